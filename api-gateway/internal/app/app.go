@@ -1,49 +1,90 @@
 package app
 
 import (
-	"log"
-	"path/filepath"
-	"runtime"
+	"context"
+	"log/slog"
 
 	"posts/internal/grpc"
 	"posts/internal/http"
 	"posts/internal/http/handlers"
 	"posts/internal/pkg/config"
+	"posts/internal/pkg/health"
 	"posts/internal/pkg/kafka"
 	"posts/internal/pkg/logger"
+	"posts/internal/pkg/tracing"
 	// "github.com/go-redis/redis"
 )
 
-var (
-	_, b, _, _ = runtime.Caller(0)
-	basepath   = filepath.Dir(b)
-)
+// serviceName identifies the gateway in shipped logs.
+const serviceName = "api-gateway"
 
 func Run(cfg config.Config) {
-	logger := logger.NewLogger(basepath, cfg.LogPath)
-	clients, err := grpc.NewClients(&cfg)
+	//connect to kafka; the logger ships records through it
+	broker := []string{cfg.KafkaUrl}
+	kafka, err := kafka.NewKafkaProducer(broker)
 	if err != nil {
-		logger.ERROR.Println("Failed to create gRPC clients", err)
-		log.Println("error")
-		return
+		logger.Fatal(slog.Default(), "failed to connect to kafka", "error", err)
 	}
+	defer kafka.Close()
 
-	//connect to kafka
-	broker := []string{cfg.KafkaUrl}
-	kafka, err := kafka.NewKafkaProducer(broker)
+	lc := logger.Config{
+		Level:      cfg.LogLevel,
+		File:       cfg.LogFile,
+		MaxSizeMB:  cfg.LogMaxSizeMB,
+		MaxBackups: cfg.LogMaxBackups,
+		MaxAgeDays: cfg.LogMaxAgeDays,
+	}
+	if cfg.LogShip {
+		sink, err := logger.NewSink(kafka, logger.SinkConfig{
+			Service:       serviceName,
+			Level:         cfg.LogShipLevel,
+			BatchSize:     cfg.LogShipBatchSize,
+			FlushInterval: cfg.LogShipFlushInterval,
+		})
+		if err != nil {
+			logger.Fatal(slog.Default(), "invalid log shipping configuration", "error", err)
+		}
+		defer sink.Close()
+		lc.Sink = sink
+	}
+
+	l, err := logger.New(lc)
+	if err != nil {
+		logger.Fatal(slog.Default(), "invalid logging configuration", "error", err)
+	}
+	// the standard log package writes through the same logger
+	slog.SetDefault(l.Logger)
+
+	shutdownTracing, err := tracing.Init(context.Background(), tracing.Config{
+		Service:     serviceName,
+		Exporter:    cfg.TracingExporter,
+		Endpoint:    cfg.TracingEndpoint,
+		SampleRatio: cfg.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.Fatal(l.Logger, "invalid tracing configuration", "error", err)
+	}
+	defer shutdownTracing(context.Background())
+
+	clients, err := grpc.NewClients(&cfg)
 	if err != nil {
-		logger.ERROR.Println("Failed to connect to Kafka", err)
-		log.Println("error")
+		l.Error("failed to create gRPC clients", "error", err)
 		return
 	}
-	defer kafka.Close()
 
 	// make handler
-	h := handlers.NewHandler(*clients, kafka, logger)
+	h := handlers.NewHandler(*clients, kafka, l, health.Checker{
+		"kafka":        health.Kafka(broker),
+		"post-service": health.GRPC(clients.Health, ""),
+	})
+	h.MaxAttachmentSize = cfg.AttachmentMaxSize
 
 	// make gin
 	router := http.NewGin(h)
 
 	// start server
-	router.Run(":8080")
+	l.Info("server started", "port", ":8080")
+	if err := router.Run(":8080"); err != nil {
+		l.Error("server stopped", "error", err)
+	}
 }
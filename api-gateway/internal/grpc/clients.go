@@ -3,19 +3,35 @@ package grpc
 import (
 	"posts/internal/pkg/config"
 	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/logger"
+	"posts/internal/pkg/metrics"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
 
 type Clients struct {
-	Post pb.PostServiceClient
-	Log  pb.LogServiceClient
-	User pb.UserServiceClient
+	Post         pb.PostServiceClient
+	Log          pb.LogServiceClient
+	User         pb.UserServiceClient
+	Comment      pb.CommentServiceClient
+	Attachment   pb.AttachmentServiceClient
+	Notification pb.NotificationServiceClient
+	Webhook      pb.WebhookServiceClient
+	Admin        pb.AdminServiceClient
+	Health       healthpb.HealthClient
 }
 
 func NewClients(cfg *config.Config) (*Clients, error) {
-	post_conn, err := grpc.NewClient(cfg.GRPCPort, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	post_conn, err := grpc.NewClient(cfg.GRPCPort,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		grpc.WithChainUnaryInterceptor(metrics.UnaryClientInterceptor(), logger.UnaryClientInterceptor()),
+		grpc.WithChainStreamInterceptor(metrics.StreamClientInterceptor(), logger.StreamClientInterceptor()),
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -23,10 +39,22 @@ func NewClients(cfg *config.Config) (*Clients, error) {
 	userClient := pb.NewUserServiceClient(post_conn)
 	logClient := pb.NewLogServiceClient(post_conn)
 	postClient := pb.NewPostServiceClient(post_conn)
+	commentClient := pb.NewCommentServiceClient(post_conn)
+	attachmentClient := pb.NewAttachmentServiceClient(post_conn)
+	notificationClient := pb.NewNotificationServiceClient(post_conn)
+	webhookClient := pb.NewWebhookServiceClient(post_conn)
+	adminClient := pb.NewAdminServiceClient(post_conn)
+	healthClient := healthpb.NewHealthClient(post_conn)
 
 	return &Clients{
-		Post: postClient,
-		Log:  logClient,
-		User: userClient,
+		Post:         postClient,
+		Log:          logClient,
+		User:         userClient,
+		Comment:      commentClient,
+		Attachment:   attachmentClient,
+		Notification: notificationClient,
+		Webhook:      webhookClient,
+		Admin:        adminClient,
+		Health:       healthClient,
 	}, nil
 }
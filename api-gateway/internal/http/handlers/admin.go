@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	pb "posts/internal/pkg/genproto"
+)
+
+// logLevelBody is the JSON accepted when changing a service's log level.
+type logLevelBody struct {
+	// trace, debug, info, warn or error
+	Level string `json:"level"`
+}
+
+// Services whose log level can be read and changed.
+const (
+	serviceGateway = "gateway"
+	servicePosts   = "posts"
+)
+
+// GetLogLevel returns a service's log level
+// @Summary Get Log Level
+// @Description The least severe level a service currently logs
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param service query string false "gateway (default) or posts"
+// @Success 200 {object} pb.LogLevel "Log level"
+// @Failure 400 {string} string "Unknown service"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Caller is not an admin"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/admin/log-level [get]
+func (h *Handler) GetLogLevel(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	switch c.DefaultQuery("service", serviceGateway) {
+	case serviceGateway:
+		c.JSON(200, &pb.LogLevel{Level: h.Logger.Level()})
+	case servicePosts:
+		res, err := h.Clients.Admin.GetLogLevel(c, &pb.LogLevelRequest{})
+		if err != nil {
+			h.log(c).Error("failed to get log level", "error", err)
+			h.replyError(c, err)
+			return
+		}
+		c.JSON(200, res)
+	default:
+		c.JSON(400, "Unknown service, expected gateway or posts")
+	}
+}
+
+// SetLogLevel changes a service's log level
+// @Summary Set Log Level
+// @Description Change the least severe level a service logs, effective immediately and until the service restarts
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param service query string false "gateway (default) or posts"
+// @Param level body handlers.logLevelBody true "Log level"
+// @Success 200 {object} pb.LogLevel "Log level"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Caller is not an admin"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/admin/log-level [put]
+func (h *Handler) SetLogLevel(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	var body logLevelBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log(c).Warn("failed to bind request", "error", err)
+		c.JSON(400, "Invalid request: "+err.Error())
+		return
+	}
+
+	switch c.DefaultQuery("service", serviceGateway) {
+	case serviceGateway:
+		if err := h.Logger.SetLevel(body.Level); err != nil {
+			c.JSON(400, err.Error())
+			return
+		}
+		h.log(c).Info("log level changed", "level", h.Logger.Level())
+		c.JSON(200, &pb.LogLevel{Level: h.Logger.Level()})
+	case servicePosts:
+		res, err := h.Clients.Admin.SetLogLevel(c, &pb.LogLevel{Level: body.Level})
+		if err != nil {
+			h.log(c).Error("failed to set log level", "error", err)
+			h.replyError(c, err)
+			return
+		}
+		c.JSON(200, res)
+	default:
+		c.JSON(400, "Unknown service, expected gateway or posts")
+	}
+}
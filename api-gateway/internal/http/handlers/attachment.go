@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	pb "posts/internal/pkg/genproto"
+)
+
+// attachmentChunkSize is the payload of a single upload chunk.
+const attachmentChunkSize = 64 << 10
+
+// allowedAttachmentTypes lists the content types, as sniffed from the
+// file itself, that may be attached to a post.
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+	"text/plain":      true,
+	"video/mp4":       true,
+	"audio/mpeg":      true,
+}
+
+// attachmentURL is a signed, expiring download link.
+type attachmentURL struct {
+	URL     string `json:"url"`
+	Expires int64  `json:"expires"`
+}
+
+// UploadAttachment attaches a file to a post
+// @Summary Upload Attachment
+// @Description Upload a file to a post as multipart form data; only the post's author may attach files
+// @Tags Attachment
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param file formData file true "File to attach"
+// @Success 201 {object} pb.Attachment "Attachment created"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not the post's author"
+// @Failure 413 {string} string "File too large"
+// @Failure 415 {string} string "Unsupported file type"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/attachments [post]
+func (h *Handler) UploadAttachment(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return
+	}
+	userID := viewerID(c)
+	if userID == 0 {
+		c.JSON(401, "Unauthorized")
+		return
+	}
+
+	// leave room for the multipart framing around the file itself
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.MaxAttachmentSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			c.JSON(413, fmt.Sprintf("File is larger than %d bytes", h.MaxAttachmentSize))
+			return
+		}
+		h.log(c).Warn("invalid upload", "error", err)
+		c.JSON(400, "Missing file")
+		return
+	}
+	if header.Size > h.MaxAttachmentSize {
+		c.JSON(413, fmt.Sprintf("File is larger than %d bytes", h.MaxAttachmentSize))
+		return
+	}
+	if header.Size == 0 {
+		c.JSON(400, "File is empty")
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		h.log(c).Error("failed to open upload", "error", err)
+		c.JSON(500, "Internal server error: "+err.Error())
+		return
+	}
+	defer file.Close()
+
+	// trust the file's content, not the client's Content-Type header
+	buf := make([]byte, attachmentChunkSize)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && err != io.ErrUnexpectedEOF {
+		h.log(c).Error("failed to read upload", "error", err)
+		c.JSON(400, "Failed to read file")
+		return
+	}
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(buf[:n]))
+	if !allowedAttachmentTypes[contentType] {
+		c.JSON(415, "Unsupported file type: "+contentType)
+		return
+	}
+
+	stream, err := h.Clients.Attachment.Upload(c)
+	if err != nil {
+		h.log(c).Error("failed to upload attachment", "error", err)
+		c.JSON(500, "Internal server error: "+err.Error())
+		return
+	}
+	chunk := &pb.AttachmentUploadChunk{
+		Meta: &pb.AttachmentMeta{
+			PostId:      postID,
+			UserId:      userID,
+			FileName:    header.Filename,
+			ContentType: contentType,
+			Size:        header.Size,
+		},
+		Data: buf[:n],
+	}
+	for {
+		if err := stream.Send(chunk); err != nil {
+			// the server gave up; CloseAndRecv reports why
+			break
+		}
+		n, err = io.ReadFull(file, buf)
+		if n == 0 {
+			break
+		}
+		chunk = &pb.AttachmentUploadChunk{Data: buf[:n]}
+		if err != nil && err != io.ErrUnexpectedEOF {
+			h.log(c).Error("failed to read upload", "error", err)
+			c.JSON(400, "Failed to read file")
+			return
+		}
+	}
+
+	res, err := stream.CloseAndRecv()
+	if err != nil {
+		h.log(c).Error("failed to upload attachment", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(201, res)
+}
+
+// GetAttachmentList lists the files attached to a post
+// @Summary Get Post Attachments
+// @Description List the files attached to a post
+// @Tags Attachment
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Success 200 {object} pb.AttachmentList "List of attachments"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Post not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/attachments [get]
+func (h *Handler) GetAttachmentList(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return
+	}
+
+	res, err := h.Clients.Attachment.GetList(c, &pb.AttachmentListRequest{PostId: postID, ViewerId: viewerID(c)})
+	if err != nil {
+		h.log(c).Error("failed to list attachments", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// DeleteAttachment removes a file from a post
+// @Summary Delete Attachment
+// @Description Delete an attachment; allowed for the uploader and the post's author
+// @Tags Attachment
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Attachment ID"
+// @Success 200 {string} string "Attachment deleted successfully"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not allowed to delete this attachment"
+// @Failure 404 {string} string "Attachment not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/attachments/{id} [delete]
+func (h *Handler) DeleteAttachment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid attachment ID", "error", err)
+		c.JSON(400, "Invalid attachment ID")
+		return
+	}
+
+	_, err = h.Clients.Attachment.Delete(c, &pb.AttachmentDeleteRequest{Id: id, UserId: viewerID(c)})
+	if err != nil {
+		h.log(c).Error("failed to delete attachment", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, "Attachment deleted successfully")
+}
+
+// GetAttachmentURL returns a signed download link for an attachment
+// @Summary Get Attachment URL
+// @Description Create a download link that works without a token until it expires
+// @Tags Attachment
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Attachment ID"
+// @Param ttl query int false "Lifetime of the link in seconds"
+// @Success 200 {object} attachmentURL "Signed download link"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Attachment not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/attachments/{id}/url [get]
+func (h *Handler) GetAttachmentURL(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid attachment ID", "error", err)
+		c.JSON(400, "Invalid attachment ID")
+		return
+	}
+
+	req := pb.AttachmentSignRequest{Id: id, ViewerId: viewerID(c)}
+	if ttl := c.Query("ttl"); ttl != "" {
+		if t, err := strconv.ParseInt(ttl, 10, 64); err == nil {
+			req.TtlSeconds = t
+		}
+	}
+
+	res, err := h.Clients.Attachment.Sign(c, &req)
+	if err != nil {
+		h.log(c).Error("failed to sign attachment URL", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, attachmentURL{
+		URL:     fmt.Sprintf("/api/v1/attachments/%d/download?expires=%d&signature=%s", res.Id, res.Expires, res.Signature),
+		Expires: res.Expires,
+	})
+}
+
+// DownloadAttachment streams an attachment's content
+// @Summary Download Attachment
+// @Description Download an attachment through a signed link obtained from the url endpoint
+// @Tags Attachment
+// @Produce octet-stream
+// @Param id path string true "Attachment ID"
+// @Param expires query int true "Expiry of the link as a Unix timestamp"
+// @Param signature query string true "Signature of the link"
+// @Success 200 {file} file "Attachment content"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 403 {string} string "Invalid or expired link"
+// @Failure 404 {string} string "Attachment not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/attachments/{id}/download [get]
+func (h *Handler) DownloadAttachment(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid attachment ID", "error", err)
+		c.JSON(400, "Invalid attachment ID")
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		c.JSON(400, "Invalid expires")
+		return
+	}
+
+	stream, err := h.Clients.Attachment.Download(c, &pb.AttachmentDownloadRequest{
+		Id:        id,
+		Expires:   expires,
+		Signature: c.Query("signature"),
+	})
+	if err != nil {
+		h.log(c).Error("failed to download attachment", "error", err)
+		c.JSON(500, "Internal server error: "+err.Error())
+		return
+	}
+
+	// errors are only reported as JSON until the first chunk is written
+	chunk, err := stream.Recv()
+	if err != nil {
+		h.log(c).Error("failed to download attachment", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", chunk.ContentType)
+	c.Header("Content-Length", strconv.FormatInt(chunk.Size, 10))
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": chunk.FileName}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Status(200)
+
+	for {
+		if _, err := c.Writer.Write(chunk.Data); err != nil {
+			h.log(c).Error("failed to write attachment", "error", err)
+			return
+		}
+		c.Writer.Flush()
+
+		chunk, err = stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			// headers are already sent, so the client sees a truncated body
+			h.log(c).Error("attachment stream interrupted", "error", err)
+			return
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/token"
+)
+
+// commentBody is the JSON accepted when creating or editing a comment.
+type commentBody struct {
+	ParentID int64  `json:"parent_id"`
+	Content  string `json:"content"`
+}
+
+// CreateComment adds a comment to a post
+// @Summary Create Comment
+// @Description Comment on a post, or reply to a comment when parent_id is set. The author is taken from the token.
+// @Tags Comment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param comment body handlers.commentBody true "Comment data"
+// @Success 200 {object} pb.CommentGet "Created comment"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Post or parent comment not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/comments [post]
+func (h *Handler) CreateComment(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return
+	}
+
+	userID, err := token.UserID(c.GetHeader("Authorization"))
+	if err != nil {
+		h.log(c).Warn("invalid token", "error", err)
+		c.JSON(401, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var body commentBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log(c).Warn("failed to bind request", "error", err)
+		c.JSON(400, "Invalid request: "+err.Error())
+		return
+	}
+
+	res, err := h.Clients.Comment.Create(c, &pb.CommentCreateRequest{
+		PostId:   postID,
+		ParentId: body.ParentID,
+		UserId:   userID,
+		Content:  body.Content,
+	})
+	if err != nil {
+		h.log(c).Error("failed to create comment", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// GetCommentList lists one level of a post's comment thread
+// @Summary Get Comments
+// @Description List top-level comments of a post, or the replies to parent_id, oldest first
+// @Tags Comment
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param parent_id query int false "Parent comment ID"
+// @Param limit query int false "Limit"
+// @Param page query int false "Page"
+// @Success 200 {object} pb.CommentGetAll "List of comments"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Post not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/comments [get]
+func (h *Handler) GetCommentList(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return
+	}
+
+	filter := pb.FilterComment{PostId: postID, ViewerId: viewerID(c)}
+
+	if parentID := c.Query("parent_id"); parentID != "" {
+		if p, err := strconv.ParseInt(parentID, 10, 64); err == nil {
+			filter.ParentId = p
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			filter.Limit = int64(l)
+		}
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filter.Page = int64(p)
+		}
+	}
+
+	res, err := h.Clients.Comment.GetList(c, &filter)
+	if err != nil {
+		h.log(c).Error("failed to list comments", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// UpdateComment edits a comment
+// @Summary Update Comment
+// @Description Edit the content of a comment; only its author may do so
+// @Tags Comment
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param comment_id path string true "Comment ID"
+// @Param comment body handlers.commentBody true "Comment data"
+// @Success 200 {string} string "Comment updated successfully"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not the author"
+// @Failure 404 {string} string "Comment not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/comments/{comment_id} [patch]
+func (h *Handler) UpdateComment(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return
+	}
+	commentID, err := strconv.ParseInt(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid comment ID", "error", err)
+		c.JSON(400, "Invalid comment ID")
+		return
+	}
+
+	userID, err := token.UserID(c.GetHeader("Authorization"))
+	if err != nil {
+		h.log(c).Warn("invalid token", "error", err)
+		c.JSON(401, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var body commentBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log(c).Warn("failed to bind request", "error", err)
+		c.JSON(400, "Invalid request: "+err.Error())
+		return
+	}
+
+	_, err = h.Clients.Comment.Update(c, &pb.CommentUpdateRequest{
+		Id:      commentID,
+		PostId:  postID,
+		UserId:  userID,
+		Content: body.Content,
+	})
+	if err != nil {
+		h.log(c).Error("failed to update comment", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, "Comment updated successfully")
+}
+
+// DeleteComment deletes a comment
+// @Summary Delete Comment
+// @Description Soft-delete a comment; its author and the post's author may do so
+// @Tags Comment
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param comment_id path string true "Comment ID"
+// @Success 200 {string} string "Comment deleted successfully"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Not allowed"
+// @Failure 404 {string} string "Comment not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/comments/{comment_id} [delete]
+func (h *Handler) DeleteComment(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return
+	}
+	commentID, err := strconv.ParseInt(c.Param("comment_id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid comment ID", "error", err)
+		c.JSON(400, "Invalid comment ID")
+		return
+	}
+
+	userID, err := token.UserID(c.GetHeader("Authorization"))
+	if err != nil {
+		h.log(c).Warn("invalid token", "error", err)
+		c.JSON(401, "Unauthorized: "+err.Error())
+		return
+	}
+
+	_, err = h.Clients.Comment.Delete(c, &pb.CommentDeleteRequest{Id: commentID, PostId: postID, UserId: userID})
+	if err != nil {
+		h.log(c).Error("failed to delete comment", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, "Comment deleted successfully")
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	pb "posts/internal/pkg/genproto"
+)
+
+// FollowUser makes the authenticated user follow another user
+// @Summary Follow User
+// @Description Follow a user so that their posts show up in your feed. Following someone twice is a no-op.
+// @Tags Follow
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {string} string "User followed successfully"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/users/{id}/follow [post]
+func (h *Handler) FollowUser(c *gin.Context) {
+	req, ok := h.followRequest(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.Clients.User.Follow(c, req); err != nil {
+		h.log(c).Error("failed to follow user", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, "User followed successfully")
+}
+
+// UnfollowUser makes the authenticated user stop following another user
+// @Summary Unfollow User
+// @Description Stop following a user
+// @Tags Follow
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {string} string "User unfollowed successfully"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/users/{id}/follow [delete]
+func (h *Handler) UnfollowUser(c *gin.Context) {
+	req, ok := h.followRequest(c)
+	if !ok {
+		return
+	}
+
+	if _, err := h.Clients.User.Unfollow(c, req); err != nil {
+		h.log(c).Error("failed to unfollow user", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, "User unfollowed successfully")
+}
+
+// GetFollowers lists the users following a user
+// @Summary Get Followers
+// @Description List the users following a user, most recent first
+// @Tags Follow
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param limit query int false "Limit"
+// @Param page query int false "Page"
+// @Success 200 {object} pb.UserGetAll "List of followers"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/users/{id}/followers [get]
+func (h *Handler) GetFollowers(c *gin.Context) {
+	req, ok := h.followListRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.Clients.User.ListFollowers(c, req)
+	if err != nil {
+		h.log(c).Error("failed to list followers", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// GetFollowing lists the users a user follows
+// @Summary Get Following
+// @Description List the users a user follows, most recent first
+// @Tags Follow
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param limit query int false "Limit"
+// @Param page query int false "Page"
+// @Success 200 {object} pb.UserGetAll "List of followed users"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/users/{id}/following [get]
+func (h *Handler) GetFollowing(c *gin.Context) {
+	req, ok := h.followListRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.Clients.User.ListFollowing(c, req)
+	if err != nil {
+		h.log(c).Error("failed to list followed users", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// GetFeed returns posts from the authors the authenticated user follows
+// @Summary Get Feed
+// @Description Newest published posts from followed authors. Pass next_cursor from a response as cursor to get the next page.
+// @Tags Follow
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Limit (default 20, at most 100)"
+// @Param cursor query string false "Cursor of the next page"
+// @Success 200 {object} pb.FeedPage "Feed page"
+// @Failure 400 {string} string "Invalid cursor"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/feed [get]
+func (h *Handler) GetFeed(c *gin.Context) {
+	userID := viewerID(c)
+	if userID == 0 {
+		c.JSON(401, "Unauthorized")
+		return
+	}
+
+	req := pb.FeedRequest{UserId: userID, Cursor: c.Query("cursor")}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			req.Limit = int64(l)
+		}
+	}
+
+	res, err := h.Clients.Post.Feed(c, &req)
+	if err != nil {
+		h.log(c).Error("failed to get feed", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+func (h *Handler) followRequest(c *gin.Context) (*pb.FollowRequest, bool) {
+	followeeID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid user ID", "error", err)
+		c.JSON(400, "Invalid user ID")
+		return nil, false
+	}
+	followerID := viewerID(c)
+	if followerID == 0 {
+		c.JSON(401, "Unauthorized")
+		return nil, false
+	}
+	return &pb.FollowRequest{FollowerId: followerID, FolloweeId: followeeID}, true
+}
+
+func (h *Handler) followListRequest(c *gin.Context) (*pb.FollowListRequest, bool) {
+	userID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid user ID", "error", err)
+		c.JSON(400, "Invalid user ID")
+		return nil, false
+	}
+
+	req := &pb.FollowListRequest{UserId: userID}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			req.Limit = int64(l)
+		}
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			req.Page = int64(p)
+		}
+	}
+	return req, true
+}
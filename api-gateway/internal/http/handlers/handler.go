@@ -1,9 +1,18 @@
 package handlers
 
 import (
+	"log/slog"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"posts/internal/grpc"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/health"
 	"posts/internal/pkg/kafka"
 	"posts/internal/pkg/logger"
+	"posts/internal/pkg/token"
 	// "github.com/go-redis/redis"
 )
 
@@ -11,8 +20,81 @@ type Handler struct {
 	Clients  grpc.Clients
 	Producer kafka.KafkaProducer
 	Logger   *logger.Logger
+	// Checks are the dependencies the gateway needs to be ready.
+	Checks health.Checker
+	// MaxAttachmentSize is the largest file accepted by UploadAttachment;
+	// the post service enforces the same ATTACHMENT_MAX_SIZE.
+	MaxAttachmentSize int64
+}
+
+func NewHandler(clients grpc.Clients, producer kafka.KafkaProducer, logger *logger.Logger, checks health.Checker) *Handler {
+	return &Handler{Clients: clients, Producer: producer, Logger: logger, Checks: checks}
+}
+
+// log returns the request's logger, which carries its request id, method,
+// path and user; see middlerware.RequestLogger.
+func (h *Handler) log(c *gin.Context) *slog.Logger {
+	if l, ok := c.Get(logger.LoggerKey); ok {
+		return l.(*slog.Logger)
+	}
+	return h.Logger.Logger
+}
+
+// replyError writes a gRPC error as JSON using the HTTP status that matches
+// its code; unexpected codes become 500.
+func (h *Handler) replyError(c *gin.Context, err error) {
+	st := status.Convert(err)
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(400, st.Message())
+	case codes.Unauthenticated:
+		c.JSON(401, st.Message())
+	case codes.PermissionDenied:
+		c.JSON(403, st.Message())
+	case codes.NotFound:
+		c.JSON(404, st.Message())
+	case codes.FailedPrecondition, codes.AlreadyExists:
+		c.JSON(409, st.Message())
+	default:
+		c.JSON(500, "Internal server error: "+err.Error())
+	}
+}
+
+// adminRole is the role of users allowed to use the admin endpoints.
+const adminRole = "ADMIN"
+
+// requireAdmin replies 401 or 403 and returns false unless the caller is
+// an admin. Tokens carry no role, so it is read from the user record;
+// deleted and erased users have no record to read, so their tokens stop
+// granting admin access with the account.
+func (h *Handler) requireAdmin(c *gin.Context) bool {
+	id, err := token.UserID(c.GetHeader("Authorization"))
+	if err != nil {
+		h.log(c).Warn("invalid token", "error", err)
+		c.JSON(401, "Unauthorized: "+err.Error())
+		return false
+	}
+
+	user, err := h.Clients.User.GetDetail(c, &pb.ById{Id: id})
+	if err != nil && status.Code(err) != codes.NotFound {
+		h.log(c).Error("failed to get caller", "error", err)
+		h.replyError(c, err)
+		return false
+	}
+	if err != nil || !strings.EqualFold(user.Role, adminRole) {
+		h.log(c).Warn("admin access denied", "user_id", id)
+		c.JSON(403, "Permission denied")
+		return false
+	}
+	return true
 }
 
-func NewHandler(clients grpc.Clients, producer kafka.KafkaProducer, logger *logger.Logger) *Handler {
-	return &Handler{Clients: clients, Producer: producer, Logger: logger}
+// viewerID returns the id of the user making the request, or 0 for
+// anonymous requests and invalid tokens.
+func viewerID(c *gin.Context) int64 {
+	id, err := token.UserID(c.GetHeader("Authorization"))
+	if err != nil {
+		return 0
+	}
+	return id
 }
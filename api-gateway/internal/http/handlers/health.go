@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"posts/internal/pkg/health"
+)
+
+// Healthz answers liveness probes
+// @Summary Liveness
+// @Description Reports that the gateway is up. It checks no dependencies.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} health.Report "Gateway is up"
+// @Router /healthz [get]
+func (h *Handler) Healthz(c *gin.Context) {
+	c.Header("Cache-Control", "no-store")
+	c.JSON(200, health.Report{Status: health.StatusOK})
+}
+
+// Readyz answers readiness probes
+// @Summary Readiness
+// @Description Checks that Kafka is reachable and that the post service reports itself serving over grpc.health.v1, with the result and latency of each check.
+// @Tags Health
+// @Produce json
+// @Success 200 {object} health.Report "Every dependency is ready"
+// @Failure 503 {object} health.Report "A dependency is not ready"
+// @Router /readyz [get]
+func (h *Handler) Readyz(c *gin.Context) {
+	report := h.Checks.Run(c)
+	c.Header("Cache-Control", "no-store")
+	if !report.OK() {
+		c.JSON(503, report)
+		return
+	}
+	c.JSON(200, report)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"google.golang.org/protobuf/encoding/protojson"
 	"strconv"
+	"strings"
 
 	pb "posts/internal/pkg/genproto"
 
@@ -25,14 +26,14 @@ import (
 func (h *Handler) CreateLog(c *gin.Context) {
 	var req pb.LogCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.Logger.ERROR.Println("Failed to bind request:", err)
+		h.log(c).Warn("failed to bind request", "error", err)
 		c.JSON(400, "Invalid request: "+err.Error())
 		return
 	}
 
 	res, err := h.Clients.Log.Create(c, &req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to create log:", err)
+		h.log(c).Error("failed to create log", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -58,7 +59,7 @@ func (h *Handler) GetLogDetail(c *gin.Context) {
 
 	id, err := strconv.ParseInt(logID, 10, 64)
 	if err != nil {
-		h.Logger.ERROR.Println("Invalid log ID:", err)
+		h.log(c).Warn("invalid log ID", "error", err)
 		c.JSON(400, "Invalid log ID")
 		return
 	}
@@ -66,7 +67,7 @@ func (h *Handler) GetLogDetail(c *gin.Context) {
 	req := &pb.GetId{Id: id}
 	res, err := h.Clients.Log.GetDetail(c, req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to get log:", err)
+		h.log(c).Error("failed to get log", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -89,7 +90,7 @@ func (h *Handler) GetLogDetail(c *gin.Context) {
 func (h *Handler) UpdateLog(c *gin.Context) {
 	var body pb.LogUpdateRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		h.Logger.ERROR.Println("Failed to bind request:", err)
+		h.log(c).Warn("failed to bind request", "error", err)
 		c.JSON(400, "Invalid request: "+err.Error())
 		return
 	}
@@ -103,19 +104,19 @@ func (h *Handler) UpdateLog(c *gin.Context) {
 
 	//_, err := h.Clients.Log.Update(c, &req)
 	//if err != nil {
-	//	h.Logger.ERROR.Println("Failed to update log:", err)
+	//	h.log(c).Error("failed to update log", "error", err)
 	//	c.JSON(500, "Internal server error: "+err.Error())
 	//	return
 	//}
 
 	input, err := protojson.Marshal(&req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to marshal req:", err)
+		h.log(c).Error("failed to marshal req", "error", err)
 		c.JSON(500, "Invalid request: "+err.Error())
 		return
 	}
-	if err := h.Producer.ProduceMessages("log-update", input); err != nil {
-		h.Logger.ERROR.Println("Failed to produce message:", err)
+	if err := h.Producer.ProduceMessages(c, "log-update", input); err != nil {
+		h.log(c).Error("failed to produce message", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -141,7 +142,7 @@ func (h *Handler) DeleteLog(c *gin.Context) {
 
 	id, err := strconv.ParseInt(logID, 10, 64)
 	if err != nil {
-		h.Logger.ERROR.Println("Invalid log ID:", err)
+		h.log(c).Warn("invalid log ID", "error", err)
 		c.JSON(400, "Invalid log ID")
 		return
 	}
@@ -150,19 +151,19 @@ func (h *Handler) DeleteLog(c *gin.Context) {
 
 	//_, err := h.Clients.Log.Delete(c, req)
 	//if err != nil {
-	//	h.Logger.ERROR.Println("Failed to delete log:", err)
+	//	h.log(c).Error("failed to delete log", "error", err)
 	//	c.JSON(500, "Internal server error: "+err.Error())
 	//	return
 	//}
 
 	input, err := protojson.Marshal(req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to marshal req:", err)
+		h.log(c).Error("failed to marshal req", "error", err)
 		c.JSON(500, "Invalid request: "+err.Error())
 		return
 	}
-	if err := h.Producer.ProduceMessages("log-delete", input); err != nil {
-		h.Logger.ERROR.Println("Failed to produce message:", err)
+	if err := h.Producer.ProduceMessages(c, "log-delete", input); err != nil {
+		h.log(c).Error("failed to produce message", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -172,16 +173,21 @@ func (h *Handler) DeleteLog(c *gin.Context) {
 
 // GetLogList retrieves a list of logs with pagination
 // @Summary Get Logs
-// @Description Retrieve a list of logs with pagination
+// @Description Retrieve logs, newest first. Pass next_cursor from a response as cursor to get the next page; page numbers still work but get slow on large tables.
 // @Tags Log
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param limit query int false "Limit"
+// @Param limit query int false "Limit (default 50, at most 500 when paging by cursor)"
+// @Param cursor query string false "Cursor of the next page"
 // @Param offset query int false "Offset"
-// @Param page query int false "Page"
-// @Param level query string false "Level"
+// @Param page query int false "Page (deprecated)"
+// @Param level query []string false "Levels, repeated or comma-separated" collectionFormat(multi)
 // @Param service_name query string false "ServiceName"
+// @Param from query string false "RFC3339 start of the time range, inclusive"
+// @Param to query string false "RFC3339 end of the time range, exclusive"
+// @Param q query string false "Full-text query over the message"
+// @Param attr query []string false "Attribute filters: key requires the attribute, key=value its value" collectionFormat(multi)
 // @Success 200 {object} pb.LogGetAll "List of logs"
 // @Failure 400 {string} string "Invalid request"
 // @Failure 500 {string} string "Internal server error"
@@ -201,20 +207,87 @@ func (h *Handler) GetLogList(c *gin.Context) {
 		}
 	}
 
-	if level := c.Query("level"); level != "" {
-		filter.Level = level
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			filter.Page = int64(p)
+		}
 	}
 
+	filter.Cursor = c.Query("cursor")
+
 	if servicename := c.Query("servicename"); servicename != "" {
 		filter.ServiceName = servicename
 	}
 
+	logFilter(c, &filter)
+
 	res, err := h.Clients.Log.GetList(context.Background(), &filter)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to list logs:", err)
-		c.JSON(500, "Internal server error: "+err.Error())
+		h.log(c).Error("failed to list logs", "error", err)
+		h.replyError(c, err)
 		return
 	}
 
 	c.JSON(200, res)
 }
+
+// GetLogStats counts logs for dashboards
+// @Summary Get Log Stats
+// @Description Count logs matching the filters by level and service and, with a bucket, by minute, hour or day. Bucketed counts default to the last hour, day or 30 days and cover at most a day, 31 days or a year.
+// @Tags Log
+// @Produce json
+// @Security BearerAuth
+// @Param bucket query string false "Time bucket: minute, hour or day"
+// @Param level query []string false "Levels, repeated or comma-separated" collectionFormat(multi)
+// @Param service_name query string false "ServiceName"
+// @Param from query string false "RFC3339 start of the time range, inclusive"
+// @Param to query string false "RFC3339 end of the time range, exclusive"
+// @Param q query string false "Full-text query over the message"
+// @Param attr query []string false "Attribute filters: key requires the attribute, key=value its value" collectionFormat(multi)
+// @Success 200 {object} pb.LogStats "Log counts"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/logs/stats [get]
+func (h *Handler) GetLogStats(c *gin.Context) {
+	req := pb.LogStatsRequest{Filter: &pb.FilterLog{}, Bucket: c.Query("bucket")}
+	logFilter(c, req.Filter)
+
+	res, err := h.Clients.Log.Stats(c, &req)
+	if err != nil {
+		h.log(c).Error("failed to get log stats", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// logFilter reads the level, service, time range, text and attribute
+// filters shared by the log list and stats.
+func logFilter(c *gin.Context, filter *pb.FilterLog) {
+	for _, level := range c.QueryArray("level") {
+		for _, l := range strings.Split(level, ",") {
+			if l = strings.TrimSpace(l); l != "" {
+				filter.Levels = append(filter.Levels, l)
+			}
+		}
+	}
+	if service := c.Query("service_name"); service != "" {
+		filter.ServiceName = service
+	}
+	filter.From = c.Query("from")
+	filter.To = c.Query("to")
+	filter.Query = c.Query("q")
+
+	for _, attr := range c.QueryArray("attr") {
+		key, value, ok := strings.Cut(attr, "=")
+		if !ok {
+			filter.AttributeKeys = append(filter.AttributeKeys, key)
+			continue
+		}
+		if filter.Attributes == nil {
+			filter.Attributes = make(map[string]string)
+		}
+		filter.Attributes[key] = value
+	}
+}
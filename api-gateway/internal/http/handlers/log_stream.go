@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	pb "posts/internal/pkg/genproto"
+)
+
+// streamHeartbeat is how often an idle log stream sends a comment, which
+// keeps proxies from closing the connection.
+const streamHeartbeat = 15 * time.Second
+
+// StreamLogs streams new logs as Server-Sent Events
+// @Summary Stream Logs
+// @Description Stream logs as they are inserted, as Server-Sent Events named "log" whose id is the log id. Reconnecting clients resume with the Last-Event-ID header or after_id. Idle streams carry a heartbeat comment every 15 seconds.
+// @Tags Log
+// @Produce text/event-stream
+// @Security BearerAuth
+// @Param level query string false "Level"
+// @Param service_name query string false "ServiceName"
+// @Param after_id query int false "Send logs with a greater id first"
+// @Param Last-Event-ID header int false "Id of the last received event; overrides after_id"
+// @Success 200 {object} pb.LogGetResponse "Stream of logs"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/logs/stream [get]
+func (h *Handler) StreamLogs(c *gin.Context) {
+	req := pb.LogTailRequest{
+		Level:       c.Query("level"),
+		ServiceName: c.Query("service_name"),
+	}
+
+	resume := c.GetHeader("Last-Event-ID")
+	if resume == "" {
+		resume = c.Query("after_id")
+	}
+	if resume != "" {
+		id, err := strconv.ParseInt(resume, 10, 64)
+		if err != nil {
+			h.log(c).Warn("invalid log ID", "error", err)
+			c.JSON(400, "Invalid log ID")
+			return
+		}
+		req.AfterId = id
+	}
+
+	// c carries the request id for the call; the request's context ends
+	// when the client goes away
+	ctx, cancel := context.WithCancel(c)
+	defer cancel()
+	defer context.AfterFunc(c.Request.Context(), cancel)()
+
+	stream, err := h.Clients.Log.Tail(ctx, &req)
+	if err == nil {
+		// errors are only reported as JSON until the tail has started,
+		// which the service signals by sending headers
+		var md metadata.MD
+		if md, err = stream.Header(); err == nil && md == nil {
+			_, err = stream.Recv()
+		}
+	}
+	if err != nil {
+		h.log(c).Error("failed to stream logs", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	logs := make(chan *pb.LogGetResponse)
+	failed := make(chan error, 1)
+	go func() {
+		for {
+			log, err := stream.Recv()
+			if err != nil {
+				failed <- err
+				return
+			}
+			select {
+			case logs <- log:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(200)
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(streamHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		var err error
+		select {
+		case <-ctx.Done():
+			return
+		case log := <-logs:
+			var data []byte
+			if data, err = json.Marshal(log); err == nil {
+				_, err = fmt.Fprintf(c.Writer, "id: %d\nevent: log\ndata: %s\n\n", log.Id, data)
+			}
+		case <-heartbeat.C:
+			_, err = io.WriteString(c.Writer, ": heartbeat\n\n")
+		case err := <-failed:
+			if errors.Is(err, io.EOF) || status.Code(err) == codes.Canceled {
+				return
+			}
+			// headers are already sent, so the error becomes an event
+			h.log(c).Error("log stream failed", "error", err)
+			data, _ := json.Marshal(status.Convert(err).Message())
+			fmt.Fprintf(c.Writer, "event: error\ndata: %s\n\n", data)
+			c.Writer.Flush()
+			return
+		}
+		if err != nil {
+			h.log(c).Warn("failed to write log stream", "error", err)
+			return
+		}
+		c.Writer.Flush()
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	pb "posts/internal/pkg/genproto"
+)
+
+// notificationPreferencesBody is the JSON accepted when changing
+// notification preferences. Only the listed preferences change.
+type notificationPreferencesBody struct {
+	Preferences []struct {
+		Kind    string `json:"kind"`
+		Channel string `json:"channel"`
+		Enabled bool   `json:"enabled"`
+	} `json:"preferences"`
+	WebhookURL      string `json:"webhook_url"`
+	ClearWebhookURL bool   `json:"clear_webhook_url"`
+}
+
+// GetNotifications lists the authenticated user's notifications
+// @Summary Get Notifications
+// @Description List your notifications, newest first, with the number of unread ones
+// @Tags Notification
+// @Produce json
+// @Security BearerAuth
+// @Param unread query bool false "Only unread notifications"
+// @Param limit query int false "Limit"
+// @Param page query int false "Page"
+// @Success 200 {object} pb.NotificationList "List of notifications"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/notifications [get]
+func (h *Handler) GetNotifications(c *gin.Context) {
+	userID := viewerID(c)
+	if userID == 0 {
+		c.JSON(401, "Unauthorized")
+		return
+	}
+
+	req := pb.NotificationListRequest{UserId: userID, UnreadOnly: c.Query("unread") == "true"}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			req.Limit = int64(l)
+		}
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			req.Page = int64(p)
+		}
+	}
+
+	res, err := h.Clients.Notification.GetList(c, &req)
+	if err != nil {
+		h.log(c).Error("failed to list notifications", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// MarkNotificationRead marks one notification as read
+// @Summary Mark Notification Read
+// @Description Mark one of your notifications as read
+// @Tags Notification
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Notification ID"
+// @Success 200 {object} pb.NotificationReadResponse "Number of notifications marked as read"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/notifications/{id}/read [post]
+func (h *Handler) MarkNotificationRead(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid notification ID", "error", err)
+		c.JSON(400, "Invalid notification ID")
+		return
+	}
+
+	h.markNotificationsRead(c, &pb.NotificationReadRequest{Ids: []int64{id}})
+}
+
+// MarkAllNotificationsRead marks every notification as read
+// @Summary Mark All Notifications Read
+// @Description Mark all of your notifications as read
+// @Tags Notification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} pb.NotificationReadResponse "Number of notifications marked as read"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/notifications/read-all [post]
+func (h *Handler) MarkAllNotificationsRead(c *gin.Context) {
+	h.markNotificationsRead(c, &pb.NotificationReadRequest{All: true})
+}
+
+func (h *Handler) markNotificationsRead(c *gin.Context, req *pb.NotificationReadRequest) {
+	req.UserId = viewerID(c)
+	if req.UserId == 0 {
+		c.JSON(401, "Unauthorized")
+		return
+	}
+
+	res, err := h.Clients.Notification.MarkRead(c, req)
+	if err != nil {
+		h.log(c).Error("failed to mark notifications as read", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// GetNotificationPreferences returns the authenticated user's notification preferences
+// @Summary Get Notification Preferences
+// @Description Which notification kinds you receive on which channel (in_app, email, webhook), and your webhook URL
+// @Tags Notification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} pb.NotificationPreferences "Notification preferences"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/notifications/preferences [get]
+func (h *Handler) GetNotificationPreferences(c *gin.Context) {
+	userID := viewerID(c)
+	if userID == 0 {
+		c.JSON(401, "Unauthorized")
+		return
+	}
+
+	res, err := h.Clients.Notification.GetPreferences(c, &pb.NotificationPreferencesRequest{UserId: userID})
+	if err != nil {
+		h.log(c).Error("failed to get notification preferences", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// UpdateNotificationPreferences changes the authenticated user's notification preferences
+// @Summary Update Notification Preferences
+// @Description Turn notification kinds on or off per channel and set or clear the webhook URL. Setting the URL returns a new webhook_secret that signs its requests like those of outbound webhooks.
+// @Tags Notification
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param preferences body handlers.notificationPreferencesBody true "Preferences to change"
+// @Success 200 {object} pb.NotificationPreferences "Notification preferences"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/notifications/preferences [put]
+func (h *Handler) UpdateNotificationPreferences(c *gin.Context) {
+	userID := viewerID(c)
+	if userID == 0 {
+		c.JSON(401, "Unauthorized")
+		return
+	}
+
+	var body notificationPreferencesBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log(c).Warn("failed to bind request", "error", err)
+		c.JSON(400, "Invalid request: "+err.Error())
+		return
+	}
+
+	req := pb.NotificationPreferences{
+		UserId:          userID,
+		WebhookUrl:      body.WebhookURL,
+		ClearWebhookUrl: body.ClearWebhookURL,
+	}
+	for _, p := range body.Preferences {
+		req.Preference = append(req.Preference, &pb.NotificationPreference{
+			Kind:    p.Kind,
+			Channel: p.Channel,
+			Enabled: p.Enabled,
+		})
+	}
+
+	res, err := h.Clients.Notification.UpdatePreferences(c, &req)
+	if err != nil {
+		h.log(c).Error("failed to update notification preferences", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
@@ -3,9 +3,11 @@ package handlers
 import (
 	"context"
 	"google.golang.org/protobuf/encoding/protojson"
+	"net/url"
 	"strconv"
 
 	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/token"
 
 	"github.com/gin-gonic/gin"
 )
@@ -25,14 +27,14 @@ import (
 func (h *Handler) Create(c *gin.Context) {
 	var req pb.PostCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.Logger.ERROR.Println("Failed to bind request:", err)
+		h.log(c).Warn("failed to bind request", "error", err)
 		c.JSON(400, "Invalid request: "+err.Error())
 		return
 	}
 
 	res, err := h.Clients.Post.Create(c, &req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to create post:", err)
+		h.log(c).Error("failed to create post", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -42,7 +44,7 @@ func (h *Handler) Create(c *gin.Context) {
 
 // GetDetail retrieves a post by ID
 // @Summary Get Post
-// @Description Retrieve a post by its ID
+// @Description Retrieve a post by its ID. Posts that are not published are only returned to their author.
 // @Tags Post
 // @Accept json
 // @Produce json
@@ -58,16 +60,47 @@ func (h *Handler) GetDetail(c *gin.Context) {
 
 	id, err := strconv.ParseInt(postID, 10, 64)
 	if err != nil {
-		h.Logger.ERROR.Println("Invalid log ID:", err)
+		h.log(c).Warn("invalid log ID", "error", err)
 		c.JSON(400, "Invalid log ID")
 		return
 	}
 
-	req := &pb.GetById{Id: id}
+	req := &pb.GetById{Id: id, ViewerId: viewerID(c)}
 	res, err := h.Clients.Post.GetDetail(c, req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to get post:", err)
-		c.JSON(500, "Internal server error: "+err.Error())
+		h.log(c).Error("failed to get post", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// GetBySlug retrieves a post by its slug
+// @Summary Get Post By Slug
+// @Description Retrieve a post by its slug. Slugs the post had before a title change, or spelled differently, redirect to the current one.
+// @Tags Post
+// @Produce json
+// @Security BearerAuth
+// @Param slug path string true "Post slug"
+// @Success 200 {object} pb.PostGetResponse "Post details"
+// @Success 301 {string} string "Moved to the post's current slug"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Post not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/by-slug/{slug} [get]
+func (h *Handler) GetBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+
+	res, err := h.Clients.Post.GetBySlug(c, &pb.PostSlugRequest{Slug: slug, ViewerId: viewerID(c)})
+	if err != nil {
+		h.log(c).Error("failed to get post by slug", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	if res.Slug != "" && res.Slug != slug {
+		c.Redirect(301, "/api/v1/posts/by-slug/"+url.PathEscape(res.Slug))
 		return
 	}
 
@@ -89,33 +122,37 @@ func (h *Handler) GetDetail(c *gin.Context) {
 func (h *Handler) UpdatePost(c *gin.Context) {
 	var body pb.PostUpdateRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		h.Logger.ERROR.Println("Failed to bind request:", err)
+		h.log(c).Warn("failed to bind request", "error", err)
 		c.JSON(400, "Invalid request: "+err.Error())
 		return
 	}
 
 	req := pb.PostUpdateRequest{
-		Id:      body.Id,
-		UserId:  body.UserId,
-		Title:   body.Title,
-		Content: body.Content,
+		Id:        body.Id,
+		UserId:    body.UserId,
+		Title:     body.Title,
+		Content:   body.Content,
+		Tags:      body.Tags,
+		ClearTags: body.ClearTags,
+		Status:    body.Status,
+		PublishAt: body.PublishAt,
 	}
 
 	//_, err := h.Clients.Post.Update(c, &req)
 	//if err != nil {
-	//	h.Logger.ERROR.Println("Failed to update post:", err)
+	//	h.log(c).Error("failed to update post", "error", err)
 	//	c.JSON(500, "Internal server error: "+err.Error())
 	//	return
 	//}
 
 	input, err := protojson.Marshal(&req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to marshal req:", err)
+		h.log(c).Error("failed to marshal req", "error", err)
 		c.JSON(500, "Invalid request: "+err.Error())
 		return
 	}
-	if err := h.Producer.ProduceMessages("post-update", input); err != nil {
-		h.Logger.ERROR.Println("Failed to produce message:", err)
+	if err := h.Producer.ProduceMessages(c, "post-update", input); err != nil {
+		h.log(c).Error("failed to produce message", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -141,7 +178,7 @@ func (h *Handler) Delete(c *gin.Context) {
 
 	id, err := strconv.ParseInt(postID, 10, 64)
 	if err != nil {
-		h.Logger.ERROR.Println("Invalid log ID:", err)
+		h.log(c).Warn("invalid log ID", "error", err)
 		c.JSON(400, "Invalid log ID")
 		return
 	}
@@ -150,19 +187,19 @@ func (h *Handler) Delete(c *gin.Context) {
 
 	//_, err := h.Clients.Post.Delete(c, req)
 	//if err != nil {
-	//	h.Logger.ERROR.Println("Failed to delete post:", err)
+	//	h.log(c).Error("failed to delete post", "error", err)
 	//	c.JSON(500, "Internal server error: "+err.Error())
 	//	return
 	//}
 
 	input, err := protojson.Marshal(req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to marshal req:", err)
+		h.log(c).Error("failed to marshal req", "error", err)
 		c.JSON(500, "Invalid request: "+err.Error())
 		return
 	}
-	if err := h.Producer.ProduceMessages("post-delete", input); err != nil {
-		h.Logger.ERROR.Println("Failed to produce message:", err)
+	if err := h.Producer.ProduceMessages(c, "post-delete", input); err != nil {
+		h.log(c).Error("failed to produce message", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -172,7 +209,7 @@ func (h *Handler) Delete(c *gin.Context) {
 
 // GetList retrieves a list of posts with pagination
 // @Summary Get Posts
-// @Description Retrieve a list of posts with pagination
+// @Description Retrieve a list of published posts with pagination; authenticated users also see their own drafts, scheduled and archived posts
 // @Tags Post
 // @Accept json
 // @Produce json
@@ -182,6 +219,10 @@ func (h *Handler) Delete(c *gin.Context) {
 // @Param page query int false "Page"
 // @Param user_id query int false "UserID"
 // @Param content query string false "Content"
+// @Param sort_by query string false "Sort order: latest (default) or popular"
+// @Param tags_any query []string false "Posts with at least one of these tags" collectionFormat(csv)
+// @Param tags_all query []string false "Posts with all of these tags" collectionFormat(csv)
+// @Param status query string false "Status: draft, scheduled, published or archived"
 // @Success 200 {object} pb.PostGetAll "List of posts"
 // @Failure 400 {string} string "Invalid request"
 // @Failure 500 {string} string "Internal server error"
@@ -208,13 +249,169 @@ func (h *Handler) GetList(c *gin.Context) {
 	if content := c.Query("content"); content != "" {
 		filter.Content = content
 	}
+	filter.ViewerId = viewerID(c)
+	filter.Status = c.Query("status")
+	filter.TagsAny = queryList(c, "tags_any")
+	filter.TagsAll = queryList(c, "tags_all")
+	switch sortBy := c.Query("sort_by"); sortBy {
+	case "", "latest", "popular":
+		filter.SortBy = sortBy
+	default:
+		c.JSON(400, "Invalid sort_by, expected latest or popular")
+		return
+	}
 
 	res, err := h.Clients.Post.GetList(context.Background(), &filter)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to list posts:", err)
+		h.log(c).Error("failed to list posts", "error", err)
+		c.JSON(500, "Internal server error: "+err.Error())
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// SearchPosts runs a full-text search over post titles and content
+// @Summary Search Posts
+// @Description Full-text search over posts, ranked by relevance with highlighted snippets
+// @Tags Post
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Search query"
+// @Param language query string false "Only posts written in this language (simple, english, russian, ...)"
+// @Param mode query string false "Query mode: websearch (default), plain, phrase or prefix"
+// @Param user_id query int false "UserID"
+// @Param limit query int false "Limit"
+// @Param page query int false "Page"
+// @Success 200 {object} pb.PostSearchResponse "Ranked search results"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/search [get]
+func (h *Handler) SearchPosts(c *gin.Context) {
+	req := pb.PostSearchRequest{
+		Query:    c.Query("q"),
+		Language: c.Query("language"),
+		Mode:     c.Query("mode"),
+	}
+	if req.Query == "" {
+		c.JSON(400, "Invalid request: q is required")
+		return
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			req.Limit = int64(l)
+		}
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			req.Page = int64(p)
+		}
+	}
+	if user_id := c.Query("user_id"); user_id != "" {
+		if o, err := strconv.Atoi(user_id); err == nil {
+			req.UserId = int64(o)
+		}
+	}
+
+	res, err := h.Clients.Post.Search(c, &req)
+	if err != nil {
+		h.log(c).Error("failed to search posts", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
 
 	c.JSON(200, res)
 }
+
+// reactionBody is the JSON accepted when reacting to a post.
+type reactionBody struct {
+	Kind string `json:"kind" example:"like"`
+}
+
+// ReactToPost adds the caller's reaction to a post
+// @Summary React to Post
+// @Description Add a reaction (like, love, laugh, wow, sad, angry) to a post. Reacting twice with the same kind has no effect.
+// @Tags Post
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param reaction body handlers.reactionBody true "Reaction"
+// @Success 200 {object} pb.PostReactions "Reaction counts of the post"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Post not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/reactions [post]
+func (h *Handler) ReactToPost(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return
+	}
+
+	userID, err := token.UserID(c.GetHeader("Authorization"))
+	if err != nil {
+		h.log(c).Warn("invalid token", "error", err)
+		c.JSON(401, "Unauthorized: "+err.Error())
+		return
+	}
+
+	var body reactionBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log(c).Warn("failed to bind request", "error", err)
+		c.JSON(400, "Invalid request: "+err.Error())
+		return
+	}
+
+	res, err := h.Clients.Post.React(c, &pb.PostReactionRequest{PostId: postID, UserId: userID, Kind: body.Kind})
+	if err != nil {
+		h.log(c).Error("failed to react to post", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// UnreactToPost removes the caller's reaction from a post
+// @Summary Remove Reaction
+// @Description Remove the caller's reaction of the given kind from a post. Removing a missing reaction has no effect.
+// @Tags Post
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param kind path string true "Reaction kind"
+// @Success 200 {object} pb.PostReactions "Reaction counts of the post"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "Post not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/reactions/{kind} [delete]
+func (h *Handler) UnreactToPost(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return
+	}
+
+	userID, err := token.UserID(c.GetHeader("Authorization"))
+	if err != nil {
+		h.log(c).Warn("invalid token", "error", err)
+		c.JSON(401, "Unauthorized: "+err.Error())
+		return
+	}
+
+	res, err := h.Clients.Post.Unreact(c, &pb.PostReactionRequest{PostId: postID, UserId: userID, Kind: c.Param("kind")})
+	if err != nil {
+		h.log(c).Error("failed to remove reaction", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
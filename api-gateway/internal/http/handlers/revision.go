@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	pb "posts/internal/pkg/genproto"
+)
+
+// GetRevisionList lists the revisions of a post
+// @Summary Get Post Revisions
+// @Description List the saved versions of a post, newest first
+// @Tags Revision
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param limit query int false "Limit"
+// @Param page query int false "Page"
+// @Success 200 {object} pb.RevisionList "List of revisions"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Post not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/revisions [get]
+func (h *Handler) GetRevisionList(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return
+	}
+
+	req := pb.RevisionListRequest{PostId: postID, ViewerId: viewerID(c)}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			req.Limit = int64(l)
+		}
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			req.Page = int64(p)
+		}
+	}
+
+	res, err := h.Clients.Post.ListRevisions(c, &req)
+	if err != nil {
+		h.log(c).Error("failed to list revisions", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// GetRevision retrieves one revision of a post
+// @Summary Get Post Revision
+// @Description Retrieve a saved version of a post
+// @Tags Revision
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param revision path string true "Revision number"
+// @Success 200 {object} pb.PostRevision "Revision"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Revision not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/revisions/{revision} [get]
+func (h *Handler) GetRevision(c *gin.Context) {
+	req, ok := h.revisionRequest(c)
+	if !ok {
+		return
+	}
+
+	res, err := h.Clients.Post.GetRevision(c, req)
+	if err != nil {
+		h.log(c).Error("failed to get revision", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// DiffRevisions compares two revisions of a post
+// @Summary Diff Post Revisions
+// @Description Line diff of title and content between two revisions; without "to" the revision is compared with the current post
+// @Tags Revision
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param from query int true "Older revision"
+// @Param to query int false "Newer revision, current post when omitted"
+// @Success 200 {object} pb.RevisionDiff "Diff"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Revision not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/revisions/diff [get]
+func (h *Handler) DiffRevisions(c *gin.Context) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return
+	}
+
+	from, err := strconv.ParseInt(c.Query("from"), 10, 64)
+	if err != nil {
+		c.JSON(400, "Invalid from revision")
+		return
+	}
+	var to int64
+	if v := c.Query("to"); v != "" {
+		if to, err = strconv.ParseInt(v, 10, 64); err != nil {
+			c.JSON(400, "Invalid to revision")
+			return
+		}
+	}
+
+	res, err := h.Clients.Post.DiffRevisions(c, &pb.RevisionDiffRequest{PostId: postID, From: from, To: to, ViewerId: viewerID(c)})
+	if err != nil {
+		h.log(c).Error("failed to diff revisions", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// RestoreRevision rolls a post back to an older revision
+// @Summary Restore Post Revision
+// @Description Restore the title and content of a revision; the replaced version is saved as a new revision. Only the author of the post can restore.
+// @Tags Revision
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Post ID"
+// @Param revision path string true "Revision number"
+// @Success 200 {string} string "Revision restored successfully"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Permission denied"
+// @Failure 404 {string} string "Revision not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/posts/{id}/revisions/{revision}/restore [post]
+func (h *Handler) RestoreRevision(c *gin.Context) {
+	req, ok := h.revisionRequest(c)
+	if !ok {
+		return
+	}
+	if req.ViewerId == 0 {
+		c.JSON(401, "Unauthorized")
+		return
+	}
+
+	if _, err := h.Clients.Post.RestoreRevision(c, req); err != nil {
+		h.log(c).Error("failed to restore revision", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, "Revision restored successfully")
+}
+
+func (h *Handler) revisionRequest(c *gin.Context) (*pb.RevisionRequest, bool) {
+	postID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid post ID", "error", err)
+		c.JSON(400, "Invalid post ID")
+		return nil, false
+	}
+	revision, err := strconv.ParseInt(c.Param("revision"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid revision", "error", err)
+		c.JSON(400, "Invalid revision")
+		return nil, false
+	}
+	return &pb.RevisionRequest{PostId: postID, Revision: revision, ViewerId: viewerID(c)}, true
+}
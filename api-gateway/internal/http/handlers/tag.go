@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	pb "posts/internal/pkg/genproto"
+)
+
+// GetTagList lists the tags used by posts
+// @Summary Get Tags
+// @Description List tags used by live posts with their usage counts, most used first
+// @Tags Tag
+// @Produce json
+// @Security BearerAuth
+// @Param prefix query string false "Only tags starting with this prefix"
+// @Param limit query int false "Limit"
+// @Param page query int false "Page"
+// @Success 200 {object} pb.TagList "List of tags"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/tags [get]
+func (h *Handler) GetTagList(c *gin.Context) {
+	req := pb.TagListRequest{Prefix: c.Query("prefix")}
+
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			req.Limit = int64(l)
+		}
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			req.Page = int64(p)
+		}
+	}
+
+	res, err := h.Clients.Post.ListTags(c, &req)
+	if err != nil {
+		h.log(c).Error("failed to list tags", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// queryList collects a query parameter given either repeatedly
+// (?tag=a&tag=b) or comma-separated (?tag=a,b).
+func queryList(c *gin.Context, key string) []string {
+	var values []string
+	for _, value := range c.QueryArray(key) {
+		for _, v := range strings.Split(value, ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+	}
+	return values
+}
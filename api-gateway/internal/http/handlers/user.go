@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
 	"posts/internal/pkg/config"
 	pb "posts/internal/pkg/genproto"
@@ -8,6 +9,8 @@ import (
 
 	"context"
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"posts/internal/pkg/token"
 )
 
@@ -58,14 +61,14 @@ func (h *Handler) Login(c *gin.Context) {
 func (h *Handler) CreateUser(c *gin.Context) {
 	var req pb.UserCreateRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		h.Logger.ERROR.Println("Failed to bind request:", err)
+		h.log(c).Warn("failed to bind request", "error", err)
 		c.JSON(400, "Invalid request: "+err.Error())
 		return
 	}
 
 	res, err := h.Clients.User.Create(c, &req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to create user:", err)
+		h.log(c).Error("failed to create user", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -91,14 +94,14 @@ func (h *Handler) GetUserDetail(c *gin.Context) {
 
 	id, err := strconv.ParseInt(userID, 10, 64)
 	if err != nil {
-		h.Logger.ERROR.Println("Invalid log ID:", err)
+		h.log(c).Warn("invalid log ID", "error", err)
 		c.JSON(400, "Invalid log ID")
 		return
 	}
 	req := &pb.ById{Id: id}
 	res, err := h.Clients.User.GetDetail(c, req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to get user:", err)
+		h.log(c).Error("failed to get user", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -121,7 +124,7 @@ func (h *Handler) GetUserDetail(c *gin.Context) {
 func (h *Handler) UpdateUser(c *gin.Context) {
 	var body pb.UserUpdateRequest
 	if err := c.ShouldBindJSON(&body); err != nil {
-		h.Logger.ERROR.Println("Failed to bind request:", err)
+		h.log(c).Warn("failed to bind request", "error", err)
 		c.JSON(400, "Invalid request: "+err.Error())
 		return
 	}
@@ -139,7 +142,7 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 
 	_, err := h.Clients.User.Update(c, &req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to update user:", err)
+		h.log(c).Error("failed to update user", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -155,9 +158,10 @@ func (h *Handler) UpdateUser(c *gin.Context) {
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "User ID"
-// @Success 200 {string} string "User deleted successfully"
+// @Success 200 {object} pb.UserDeleteResponse "Rows affected per related relation"
 // @Failure 400 {string} string "Invalid request"
 // @Failure 404 {string} string "User not found"
+// @Failure 409 {string} string "Deletion blocked by related rows"
 // @Failure 500 {string} string "Internal server error"
 // @Router /api/v1/users/{id} [delete]
 func (h *Handler) DeleteUser(c *gin.Context) {
@@ -165,20 +169,24 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 
 	id, err := strconv.ParseInt(userID, 10, 64)
 	if err != nil {
-		h.Logger.ERROR.Println("Invalid log ID:", err)
+		h.log(c).Warn("invalid log ID", "error", err)
 		c.JSON(400, "Invalid log ID")
 		return
 	}
 
 	req := &pb.ById{Id: id}
-	_, err = h.Clients.User.Delete(c, req)
+	res, err := h.Clients.User.Delete(c, req)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to delete user:", err)
+		h.log(c).Error("failed to delete user", "error", err)
+		if status.Code(err) == codes.FailedPrecondition {
+			c.JSON(409, status.Convert(err).Message())
+			return
+		}
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
 
-	c.JSON(200, "User deleted successfully")
+	c.JSON(200, res)
 }
 
 // GetUserList retrieves a list of users with pagination
@@ -229,7 +237,7 @@ func (h *Handler) GetUserList(c *gin.Context) {
 
 	res, err := h.Clients.User.GetList(context.Background(), &filter)
 	if err != nil {
-		h.Logger.ERROR.Println("Failed to list users:", err)
+		h.log(c).Error("failed to list users", "error", err)
 		c.JSON(500, "Internal server error: "+err.Error())
 		return
 	}
@@ -282,3 +290,159 @@ func (h *Handler) ChangeUserPassword(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Password successfully updated"})
 }
+
+// ExportUserData streams everything stored about a user
+// @Summary Export User Data
+// @Description Download a user's profile, posts, comments, reactions, follows, notifications and logs as JSON or as a ZIP archive; deleted users can be exported until they are erased
+// @Tags User
+// @Produce json
+// @Produce application/zip
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Param format query string false "Export format: json (default) or zip"
+// @Success 200 {file} file "Exported data"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Caller is not an admin"
+// @Failure 404 {string} string "User not found"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/users/{id}/export [get]
+func (h *Handler) ExportUserData(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid user ID", "error", err)
+		c.JSON(400, "Invalid user ID")
+		return
+	}
+
+	h.exportUserData(c, id)
+}
+
+// ExportMyData streams everything stored about the authenticated user
+// @Summary Export My Data
+// @Description Download the caller's own profile, posts, comments, reactions, follows, notifications and logs as JSON or as a ZIP archive
+// @Tags User
+// @Produce json
+// @Produce application/zip
+// @Security BearerAuth
+// @Param format query string false "Export format: json (default) or zip"
+// @Success 200 {file} file "Exported data"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/me/export [get]
+func (h *Handler) ExportMyData(c *gin.Context) {
+	id, err := token.UserID(c.GetHeader("Authorization"))
+	if err != nil {
+		h.log(c).Warn("invalid token", "error", err)
+		c.JSON(401, "Unauthorized: "+err.Error())
+		return
+	}
+
+	h.exportUserData(c, id)
+}
+
+func (h *Handler) exportUserData(c *gin.Context, id int64) {
+	stream, err := h.Clients.User.ExportData(c, &pb.UserExportRequest{Id: id, Format: c.Query("format")})
+	if err != nil {
+		h.log(c).Error("failed to export user data", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	// errors are only reported as JSON until the first chunk is written
+	chunk, err := stream.Recv()
+	if err != nil {
+		h.log(c).Error("failed to export user data", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.Header("Content-Type", chunk.ContentType)
+	c.Header("Content-Disposition", `attachment; filename="`+chunk.FileName+`"`)
+	c.Status(200)
+
+	for {
+		if _, err := c.Writer.Write(chunk.Data); err != nil {
+			h.log(c).Error("failed to write export", "error", err)
+			return
+		}
+		c.Writer.Flush()
+
+		chunk, err = stream.Recv()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			// headers are already sent, so the client sees a truncated body
+			h.log(c).Error("export stream interrupted", "error", err)
+			return
+		}
+	}
+}
+
+// EraseUser irreversibly erases a user's personal data
+// @Summary Erase User
+// @Description Replace a user's personal data with placeholders, reset their role and delete their follows, notifications and notification settings; posts, comments, reactions and logs stay, but no longer identify the user
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} pb.UserEraseResponse "User erased"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Caller is not an admin"
+// @Failure 404 {string} string "User not found or already erased"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/users/{id}/erase [post]
+func (h *Handler) EraseUser(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid user ID", "error", err)
+		c.JSON(400, "Invalid user ID")
+		return
+	}
+
+	h.eraseUser(c, id)
+}
+
+// EraseMe irreversibly erases the authenticated user's personal data
+// @Summary Erase Me
+// @Description Replace the caller's own personal data with placeholders, delete their follows, notifications and notification settings, and close the account
+// @Tags User
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} pb.UserEraseResponse "User erased"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 404 {string} string "User not found or already erased"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/me/erase [post]
+func (h *Handler) EraseMe(c *gin.Context) {
+	id, err := token.UserID(c.GetHeader("Authorization"))
+	if err != nil {
+		h.log(c).Warn("invalid token", "error", err)
+		c.JSON(401, "Unauthorized: "+err.Error())
+		return
+	}
+
+	h.eraseUser(c, id)
+}
+
+func (h *Handler) eraseUser(c *gin.Context, id int64) {
+	res, err := h.Clients.User.Erase(c, &pb.ById{Id: id})
+	if err != nil {
+		h.log(c).Error("failed to erase user", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	pb "posts/internal/pkg/genproto"
+)
+
+// webhookBody is the JSON accepted when registering a webhook.
+type webhookBody struct {
+	URL string `json:"url"`
+	// post.published, user.followed or comment.created
+	Events []string `json:"events"`
+	// generated when empty
+	Secret string `json:"secret"`
+}
+
+// webhookUpdateBody is the JSON accepted when changing a webhook; only the
+// fields that are set change.
+type webhookUpdateBody struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret"`
+	Active *bool    `json:"active"`
+}
+
+// CreateWebhook registers an outbound webhook
+// @Summary Create Webhook
+// @Description Register a URL that receives signed POST requests for the given events. The secret is only returned here; every request carries an X-Webhook-Signature header "t=<unix time>,v1=<hex HMAC-SHA256 of "<unix time>.<body>">". Admins only; the URL must resolve to public addresses.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param webhook body handlers.webhookBody true "Webhook"
+// @Success 201 {object} pb.Webhook "Created webhook with its secret"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Caller is not an admin"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/webhooks [post]
+func (h *Handler) CreateWebhook(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	var body webhookBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log(c).Warn("failed to bind request", "error", err)
+		c.JSON(400, "Invalid request: "+err.Error())
+		return
+	}
+
+	res, err := h.Clients.Webhook.Create(c, &pb.WebhookCreateRequest{
+		Url:       body.URL,
+		Events:    body.Events,
+		Secret:    body.Secret,
+		CreatedBy: viewerID(c),
+	})
+	if err != nil {
+		h.log(c).Error("failed to create webhook", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(201, res)
+}
+
+// GetWebhookList lists the registered webhooks
+// @Summary Get Webhook List
+// @Description List the registered webhooks, including ones disabled after repeated failures
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Limit"
+// @Param page query int false "Page"
+// @Success 200 {object} pb.WebhookList "List of webhooks"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Caller is not an admin"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/webhooks [get]
+func (h *Handler) GetWebhookList(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	var req pb.WebhookListRequest
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			req.Limit = int64(l)
+		}
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			req.Page = int64(p)
+		}
+	}
+
+	res, err := h.Clients.Webhook.GetList(c, &req)
+	if err != nil {
+		h.log(c).Error("failed to list webhooks", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// UpdateWebhook changes a webhook
+// @Summary Update Webhook
+// @Description Change a webhook's URL, events or secret, or enable or disable it. Enabling resets its failure count and resumes its pending deliveries.
+// @Tags Webhook
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param webhook body handlers.webhookUpdateBody true "Fields to change"
+// @Success 200 {object} pb.Webhook "Updated webhook"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Webhook not found"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Caller is not an admin"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/webhooks/{id} [patch]
+func (h *Handler) UpdateWebhook(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid webhook ID", "error", err)
+		c.JSON(400, "Invalid webhook ID")
+		return
+	}
+
+	var body webhookUpdateBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.log(c).Warn("failed to bind request", "error", err)
+		c.JSON(400, "Invalid request: "+err.Error())
+		return
+	}
+
+	res, err := h.Clients.Webhook.Update(c, &pb.WebhookUpdateRequest{
+		Id:     id,
+		Url:    body.URL,
+		Events: body.Events,
+		Secret: body.Secret,
+		Active: body.Active,
+	})
+	if err != nil {
+		h.log(c).Error("failed to update webhook", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// DeleteWebhook removes a webhook
+// @Summary Delete Webhook
+// @Description Remove a webhook together with its delivery log
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} pb.WebhookVoid "Webhook deleted"
+// @Failure 400 {string} string "Invalid webhook ID"
+// @Failure 404 {string} string "Webhook not found"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Caller is not an admin"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/webhooks/{id} [delete]
+func (h *Handler) DeleteWebhook(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid webhook ID", "error", err)
+		c.JSON(400, "Invalid webhook ID")
+		return
+	}
+
+	res, err := h.Clients.Webhook.Delete(c, &pb.WebhookRequest{Id: id})
+	if err != nil {
+		h.log(c).Error("failed to delete webhook", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// GetWebhookDeliveries lists a webhook's delivery log
+// @Summary Get Webhook Deliveries
+// @Description List a webhook's deliveries, newest first, with their attempts, last response code and error
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param status query string false "pending, delivered or failed"
+// @Param limit query int false "Limit"
+// @Param page query int false "Page"
+// @Success 200 {object} pb.WebhookDeliveryList "List of deliveries"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Webhook not found"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Caller is not an admin"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/webhooks/{id}/deliveries [get]
+func (h *Handler) GetWebhookDeliveries(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid webhook ID", "error", err)
+		c.JSON(400, "Invalid webhook ID")
+		return
+	}
+
+	req := pb.WebhookDeliveryListRequest{WebhookId: id, Status: c.Query("status")}
+	if limit := c.Query("limit"); limit != "" {
+		if l, err := strconv.Atoi(limit); err == nil {
+			req.Limit = int64(l)
+		}
+	}
+	if page := c.Query("page"); page != "" {
+		if p, err := strconv.Atoi(page); err == nil {
+			req.Page = int64(p)
+		}
+	}
+
+	res, err := h.Clients.Webhook.GetDeliveries(c, &req)
+	if err != nil {
+		h.log(c).Error("failed to list webhook deliveries", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(200, res)
+}
+
+// RedeliverWebhook sends an earlier delivery again
+// @Summary Redeliver Webhook
+// @Description Queue the payload of an earlier delivery again as a new delivery; the earlier one stays in the log
+// @Tags Webhook
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Webhook ID"
+// @Param delivery_id path string true "Delivery ID"
+// @Success 202 {object} pb.WebhookDelivery "Queued delivery"
+// @Failure 400 {string} string "Invalid request"
+// @Failure 404 {string} string "Delivery not found"
+// @Failure 401 {string} string "Unauthorized"
+// @Failure 403 {string} string "Caller is not an admin"
+// @Failure 500 {string} string "Internal server error"
+// @Router /api/v1/webhooks/{id}/deliveries/{delivery_id}/redeliver [post]
+func (h *Handler) RedeliverWebhook(c *gin.Context) {
+	if !h.requireAdmin(c) {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid webhook ID", "error", err)
+		c.JSON(400, "Invalid webhook ID")
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		h.log(c).Warn("invalid delivery ID", "error", err)
+		c.JSON(400, "Invalid delivery ID")
+		return
+	}
+
+	res, err := h.Clients.Webhook.Redeliver(c, &pb.WebhookDeliveryRequest{Id: deliveryID, WebhookId: id})
+	if err != nil {
+		h.log(c).Error("failed to redeliver webhook", "error", err)
+		h.replyError(c, err)
+		return
+	}
+
+	c.JSON(202, res)
+}
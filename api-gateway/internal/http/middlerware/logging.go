@@ -0,0 +1,56 @@
+package middlerware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"posts/internal/pkg/logger"
+	"posts/internal/pkg/token"
+)
+
+// RequestIDHeader carries the request id; one is generated when the
+// client sends none, and it is echoed in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestLogger stores a logger tagged with the request id, method, path
+// and user in the gin context, and logs every request with its status and
+// latency when it completes.
+func RequestLogger(l *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if requestID == "" {
+			requestID = logger.NewRequestID()
+		}
+		c.Header(RequestIDHeader, requestID)
+		c.Set(logger.RequestIDKey, requestID)
+
+		args := []any{"request_id", requestID, "method", c.Request.Method, "path", c.Request.URL.Path}
+		if userID, err := token.UserID(c.GetHeader("Authorization")); err == nil {
+			c.Set(logger.UserIDKey, userID)
+			args = append(args, "user_id", userID)
+		}
+		requestLogger := l.With(args...)
+		c.Set(logger.LoggerKey, requestLogger)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		requestLogger.Log(c, level, "request",
+			"route", c.FullPath(),
+			"status", status,
+			"latency_ms", float64(time.Since(start).Microseconds())/1000,
+			"size", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		)
+	}
+}
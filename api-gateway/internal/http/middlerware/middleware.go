@@ -1,8 +1,7 @@
 package middlerware
 
 import (
-	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -26,7 +25,7 @@ func NewAuth(enforce *casbin.Enforcer) gin.HandlerFunc {
 		}
 
 		allow, err := CheckPermission(ctx.FullPath(), ctx.Request, enforce)
-		fmt.Println(ctx.FullPath(), "full path ")
+		slog.Debug("checking permission", "route", ctx.FullPath())
 
 		if err != nil {
 			valid, _ := err.(jwt.ValidationError)
@@ -56,10 +55,10 @@ func GetRole(r *http.Request) (string, error) {
 		return "unauthorized", nil
 	}
 	claims, err = token.ExtractClaim(jwtToken)
-	log.Printf("Claims--------------------------------: %+v\n", claims)
+	slog.Debug("token claims", "claims", claims)
 
 	if err != nil {
-		log.Println("Error while extracting claims: ", err)
+		slog.Warn("failed to extract token claims", "error", err)
 		return "unauthorized", err
 	}
 
@@ -69,7 +68,7 @@ func GetRole(r *http.Request) (string, error) {
 func CheckPermission(path string, r *http.Request, enforcer *casbin.Enforcer) (bool, error) {
 	role, err := GetRole(r)
 	if err != nil {
-		log.Println("Error while getting role from token: ", err)
+		slog.Warn("failed to get role from token", "error", err)
 		return false, err
 	}
 	method := r.Method
@@ -77,7 +76,7 @@ func CheckPermission(path string, r *http.Request, enforcer *casbin.Enforcer) (b
 
 	allowed, err := enforcer.Enforce(role, path, method)
 	if err != nil {
-		log.Println("Error while comparing role from csv list: ", err)
+		slog.Error("failed to check role against policy", "error", err)
 		return false, err
 	}
 
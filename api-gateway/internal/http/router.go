@@ -7,6 +7,8 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 	_ "posts/docs"
 	"posts/internal/http/handlers"
+	"posts/internal/http/middlerware"
+	"posts/internal/pkg/metrics"
 )
 
 // @title NDC Post Project API Documentation
@@ -17,17 +19,29 @@ import (
 // @in header
 // @name Authorization
 func NewGin(h *handlers.Handler) *gin.Engine {
-	router := gin.Default()
+	router := gin.New()
+	// handlers pass the gin context to gRPC and Kafka calls; falling back
+	// to the request context lets the request's span reach them
+	router.ContextWithFallback = true
+	router.Use(gin.Recovery(), middlerware.Tracing("api-gateway"), middlerware.Metrics(), middlerware.RequestLogger(h.Logger))
+
+	router.GET("/metrics", gin.WrapH(metrics.Handler()))
+	router.GET("/healthz", h.Healthz)
+	router.GET("/readyz", h.Readyz)
 
 	router.GET("/api/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 	router.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // Adjust for your specific origins
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", middlerware.RequestIDHeader},
+		ExposeHeaders:    []string{"Content-Length", middlerware.RequestIDHeader},
 		AllowCredentials: true,
 	}))
 
+	// The Casbin middleware is off: tokens carry no role claim for it to
+	// check. Access is controlled by the handlers instead, with viewerID
+	// for signed-in callers and requireAdmin for admin endpoints, and by
+	// post-service's ownership and visibility checks.
 	//enforcer, err := casbin.NewEnforcer("./internal/http/casbin/model.conf", "./internal/http/casbin/policy.csv")
 	//if err != nil {
 	//	log.Println("k")
@@ -38,11 +52,34 @@ func NewGin(h *handlers.Handler) *gin.Engine {
 	{
 		posts.POST("/create", h.Create)
 		posts.GET("/:id", h.GetDetail)
+		posts.GET("/by-slug/:slug", h.GetBySlug)
 		posts.PATCH("/update", h.UpdatePost)
 		posts.DELETE("/:id", h.Delete)
 		posts.GET("/list", h.GetList)
+		posts.GET("/search", h.SearchPosts)
+		posts.GET("/:id/comments", h.GetCommentList)
+		posts.POST("/:id/comments", h.CreateComment)
+		posts.PATCH("/:id/comments/:comment_id", h.UpdateComment)
+		posts.DELETE("/:id/comments/:comment_id", h.DeleteComment)
+		posts.POST("/:id/reactions", h.ReactToPost)
+		posts.DELETE("/:id/reactions/:kind", h.UnreactToPost)
+		posts.GET("/:id/revisions", h.GetRevisionList)
+		posts.GET("/:id/revisions/diff", h.DiffRevisions)
+		posts.GET("/:id/revisions/:revision", h.GetRevision)
+		posts.POST("/:id/revisions/:revision/restore", h.RestoreRevision)
+		posts.GET("/:id/attachments", h.GetAttachmentList)
+		posts.POST("/:id/attachments", h.UploadAttachment)
 	}
 
+	attachments := router.Group("/api/v1/attachments")
+	{
+		attachments.DELETE("/:id", h.DeleteAttachment)
+		attachments.GET("/:id/url", h.GetAttachmentURL)
+		attachments.GET("/:id/download", h.DownloadAttachment)
+	}
+
+	router.GET("/api/v1/tags", h.GetTagList)
+
 	logs := router.Group("/api/v1/logs")
 	{
 		logs.POST("/create", h.CreateLog)
@@ -50,6 +87,8 @@ func NewGin(h *handlers.Handler) *gin.Engine {
 		logs.PATCH("/update", h.UpdateLog)
 		logs.DELETE("/:id", h.DeleteLog)
 		logs.GET("/list", h.GetLogList)
+		logs.GET("/stream", h.StreamLogs)
+		logs.GET("/stats", h.GetLogStats)
 	}
 
 	users := router.Group("/api/v1/users")
@@ -60,6 +99,45 @@ func NewGin(h *handlers.Handler) *gin.Engine {
 		users.DELETE("/:id", h.DeleteUser)
 		users.GET("/list", h.GetUserList)
 		users.PUT("/user-password", h.ChangeUserPassword)
+		users.GET("/:id/export", h.ExportUserData)
+		users.POST("/:id/erase", h.EraseUser)
+		users.POST("/:id/follow", h.FollowUser)
+		users.DELETE("/:id/follow", h.UnfollowUser)
+		users.GET("/:id/followers", h.GetFollowers)
+		users.GET("/:id/following", h.GetFollowing)
+	}
+
+	router.GET("/api/v1/feed", h.GetFeed)
+
+	notifications := router.Group("/api/v1/notifications")
+	{
+		notifications.GET("", h.GetNotifications)
+		notifications.POST("/:id/read", h.MarkNotificationRead)
+		notifications.POST("/read-all", h.MarkAllNotificationsRead)
+		notifications.GET("/preferences", h.GetNotificationPreferences)
+		notifications.PUT("/preferences", h.UpdateNotificationPreferences)
+	}
+
+	webhooks := router.Group("/api/v1/webhooks")
+	{
+		webhooks.POST("", h.CreateWebhook)
+		webhooks.GET("", h.GetWebhookList)
+		webhooks.PATCH("/:id", h.UpdateWebhook)
+		webhooks.DELETE("/:id", h.DeleteWebhook)
+		webhooks.GET("/:id/deliveries", h.GetWebhookDeliveries)
+		webhooks.POST("/:id/deliveries/:delivery_id/redeliver", h.RedeliverWebhook)
+	}
+
+	admin := router.Group("/api/v1/admin")
+	{
+		admin.GET("/log-level", h.GetLogLevel)
+		admin.PUT("/log-level", h.SetLogLevel)
+	}
+
+	me := router.Group("/api/v1/me")
+	{
+		me.GET("/export", h.ExportMyData)
+		me.POST("/erase", h.EraseMe)
 	}
 	router.POST("/api/v1/login", h.Login)
 
@@ -0,0 +1,116 @@
+package test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	clients "posts/internal/grpc"
+	"posts/internal/http/handlers"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/logger"
+)
+
+// fakeLogs answers Tail with a fixed list of logs followed by err.
+type fakeLogs struct {
+	pb.LogServiceClient
+	logs    []*pb.LogGetResponse
+	err     error
+	tailErr error
+	req     *pb.LogTailRequest
+}
+
+func (f *fakeLogs) Tail(ctx context.Context, in *pb.LogTailRequest, opts ...grpc.CallOption) (pb.LogService_TailClient, error) {
+	f.req = in
+	if f.tailErr != nil {
+		return nil, f.tailErr
+	}
+	return &fakeTail{logs: f.logs, err: f.err}, nil
+}
+
+type fakeTail struct {
+	grpc.ClientStream
+	logs []*pb.LogGetResponse
+	err  error
+}
+
+func (t *fakeTail) Header() (metadata.MD, error) { return metadata.MD{}, nil }
+func (t *fakeTail) Recv() (*pb.LogGetResponse, error) {
+	if len(t.logs) == 0 {
+		return nil, t.err
+	}
+	log := t.logs[0]
+	t.logs = t.logs[1:]
+	return log, nil
+}
+
+func streamLogs(t *testing.T, logs *fakeLogs, target string, header http.Header) *httptest.ResponseRecorder {
+	t.Helper()
+	log, err := logger.New(logger.Config{Level: "error"})
+	if err != nil {
+		t.Fatalf("failed to create logger: %v", err)
+	}
+	h := handlers.NewHandler(clients.Clients{Log: logs}, nil, log, nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/api/v1/logs/stream", h.StreamLogs)
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestStreamLogs(t *testing.T) {
+	logs := &fakeLogs{
+		logs: []*pb.LogGetResponse{{Id: 8, Level: "ERROR"}, {Id: 9, Level: "ERROR"}},
+		err:  io.EOF,
+	}
+	w := streamLogs(t, logs, "/api/v1/logs/stream?level=ERROR&after_id=3", http.Header{"Last-Event-Id": {"7"}})
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	assert.Equal(t, "id: 8\nevent: log\ndata: {\"id\":8,\"level\":\"ERROR\"}\n\n"+
+		"id: 9\nevent: log\ndata: {\"id\":9,\"level\":\"ERROR\"}\n\n", w.Body.String())
+	// Last-Event-ID wins over after_id
+	assert.Equal(t, "ERROR", logs.req.Level)
+	assert.Equal(t, int64(7), logs.req.AfterId)
+}
+
+func TestStreamLogsSendsErrorEvent(t *testing.T) {
+	logs := &fakeLogs{
+		logs: []*pb.LogGetResponse{{Id: 8}},
+		err:  status.Error(codes.Internal, "database is gone"),
+	}
+	w := streamLogs(t, logs, "/api/v1/logs/stream?after_id=3", nil)
+
+	assert.Equal(t, 200, w.Code)
+	assert.Equal(t, "id: 8\nevent: log\ndata: {\"id\":8}\n\n"+
+		"event: error\ndata: \"database is gone\"\n\n", w.Body.String())
+	assert.Equal(t, int64(3), logs.req.AfterId)
+}
+
+func TestStreamLogsRejectsInvalidRequests(t *testing.T) {
+	logs := &fakeLogs{}
+	w := streamLogs(t, logs, "/api/v1/logs/stream?after_id=abc", nil)
+	assert.Equal(t, 400, w.Code)
+	assert.Nil(t, logs.req)
+
+	// errors from before the tail starts are plain JSON responses
+	logs = &fakeLogs{tailErr: status.Error(codes.InvalidArgument, "invalid level")}
+	w = streamLogs(t, logs, "/api/v1/logs/stream?level=LOUD", nil)
+	assert.Equal(t, 400, w.Code)
+	assert.NotEqual(t, "text/event-stream", w.Header().Get("Content-Type"))
+}
@@ -0,0 +1,37 @@
+package test
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"posts/internal/http/middlerware"
+	"posts/internal/pkg/metrics"
+)
+
+func TestMetricsBoundsLabels(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(middlerware.Metrics())
+	r.Handle("PROPFIND", "/api/v1/posts/:id", func(c *gin.Context) { c.Status(204) })
+	r.GET("/api/v1/posts/:id", func(c *gin.Context) { c.Status(200) })
+
+	for _, req := range []struct{ method, target string }{
+		{"GET", "/api/v1/posts/1"},
+		{"GET", "/api/v1/posts/2"},
+		{"PROPFIND", "/api/v1/posts/3"},
+		{"GET", "/no/such/route"},
+	} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(req.method, req.target, nil))
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	assert.Contains(t, body, `http_requests_total{method="GET",route="/api/v1/posts/:id",status="200"} 2`)
+	assert.Contains(t, body, `http_requests_total{method="OTHER",route="/api/v1/posts/:id",status="204"} 1`)
+	assert.Contains(t, body, `http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	assert.NotContains(t, body, "PROPFIND")
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/cast"
 	"gopkg.in/yaml.v3"
@@ -16,12 +17,36 @@ type Config struct {
 	PostgresUser     string
 	PostgresPassword string
 	PostgresDatabase string
-	LogPath          string
 	KafkaUrl         string
 	HttpPort         string
 
 	DefaultOffset string
 	DefaultLimit  string
+
+	// AttachmentMaxSize is the largest attachment in bytes; it is shared
+	// with the post service through ATTACHMENT_MAX_SIZE.
+	AttachmentMaxSize int64
+
+	// Logging: the least severe level written (trace, debug, info, warn,
+	// error) and an optional file, rotated by size, that receives a copy
+	// of the stdout output.
+	LogLevel      string
+	LogFile       string
+	LogMaxSizeMB  int
+	LogMaxBackups int
+	LogMaxAgeDays int
+	// Log shipping: records at LogShipLevel and above
+	// are published to Kafka in batches and stored by the post service.
+	LogShip              bool
+	LogShipLevel         string
+	LogShipBatchSize     int
+	LogShipFlushInterval time.Duration
+
+	// Tracing: spans go to an OTLP collector at TracingEndpoint, to
+	// stdout, or nowhere (otlp, stdout or none).
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingSampleRatio float64
 }
 
 func Load() Config {
@@ -51,6 +76,23 @@ func Load() Config {
 
 	config.DefaultOffset = cast.ToString(getEnv("DEFAULT_OFFSET", "0"))
 	config.DefaultLimit = cast.ToString(getEnv("DEFAULT_LIMIT", "10"))
+	config.AttachmentMaxSize = cast.ToInt64(getEnv("ATTACHMENT_MAX_SIZE", "10485760"))
+
+	// Logging configuration
+	config.LogLevel = cast.ToString(getEnv("LOG_LEVEL", "info"))
+	config.LogFile = cast.ToString(getEnv("LOG_FILE", ""))
+	config.LogMaxSizeMB = cast.ToInt(getEnv("LOG_MAX_SIZE_MB", "100"))
+	config.LogMaxBackups = cast.ToInt(getEnv("LOG_MAX_BACKUPS", "5"))
+	config.LogMaxAgeDays = cast.ToInt(getEnv("LOG_MAX_AGE_DAYS", "30"))
+	config.LogShip = cast.ToBool(getEnv("LOG_SHIP", "true"))
+	config.LogShipLevel = cast.ToString(getEnv("LOG_SHIP_LEVEL", "warn"))
+	config.LogShipBatchSize = cast.ToInt(getEnv("LOG_SHIP_BATCH_SIZE", "100"))
+	config.LogShipFlushInterval = cast.ToDuration(getEnv("LOG_SHIP_FLUSH_INTERVAL", "2s"))
+
+	// Tracing configuration
+	config.TracingExporter = cast.ToString(getEnv("TRACING_EXPORTER", "none"))
+	config.TracingEndpoint = cast.ToString(getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"))
+	config.TracingSampleRatio = cast.ToFloat64(getEnv("TRACING_SAMPLE_RATIO", "1"))
 
 	return config
 }
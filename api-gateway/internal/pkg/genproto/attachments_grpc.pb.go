@@ -0,0 +1,324 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.4.0
+// - protoc             v3.12.4
+// source: internal/pkg/scripts/submodule/attachments.proto
+
+package genproto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.62.0 or later.
+const _ = grpc.SupportPackageIsVersion8
+
+const (
+	AttachmentService_Upload_FullMethodName   = "/protos.AttachmentService/Upload"
+	AttachmentService_GetList_FullMethodName  = "/protos.AttachmentService/GetList"
+	AttachmentService_Delete_FullMethodName   = "/protos.AttachmentService/Delete"
+	AttachmentService_Sign_FullMethodName     = "/protos.AttachmentService/Sign"
+	AttachmentService_Download_FullMethodName = "/protos.AttachmentService/Download"
+)
+
+// AttachmentServiceClient is the client API for AttachmentService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type AttachmentServiceClient interface {
+	Upload(ctx context.Context, opts ...grpc.CallOption) (AttachmentService_UploadClient, error)
+	GetList(ctx context.Context, in *AttachmentListRequest, opts ...grpc.CallOption) (*AttachmentList, error)
+	Delete(ctx context.Context, in *AttachmentDeleteRequest, opts ...grpc.CallOption) (*AttachmentVoid, error)
+	Sign(ctx context.Context, in *AttachmentSignRequest, opts ...grpc.CallOption) (*AttachmentSignature, error)
+	Download(ctx context.Context, in *AttachmentDownloadRequest, opts ...grpc.CallOption) (AttachmentService_DownloadClient, error)
+}
+
+type attachmentServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewAttachmentServiceClient(cc grpc.ClientConnInterface) AttachmentServiceClient {
+	return &attachmentServiceClient{cc}
+}
+
+func (c *attachmentServiceClient) Upload(ctx context.Context, opts ...grpc.CallOption) (AttachmentService_UploadClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AttachmentService_ServiceDesc.Streams[0], AttachmentService_Upload_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &attachmentServiceUploadClient{ClientStream: stream}
+	return x, nil
+}
+
+type AttachmentService_UploadClient interface {
+	Send(*AttachmentUploadChunk) error
+	CloseAndRecv() (*Attachment, error)
+	grpc.ClientStream
+}
+
+type attachmentServiceUploadClient struct {
+	grpc.ClientStream
+}
+
+func (x *attachmentServiceUploadClient) Send(m *AttachmentUploadChunk) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *attachmentServiceUploadClient) CloseAndRecv() (*Attachment, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(Attachment)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *attachmentServiceClient) GetList(ctx context.Context, in *AttachmentListRequest, opts ...grpc.CallOption) (*AttachmentList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttachmentList)
+	err := c.cc.Invoke(ctx, AttachmentService_GetList_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attachmentServiceClient) Delete(ctx context.Context, in *AttachmentDeleteRequest, opts ...grpc.CallOption) (*AttachmentVoid, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttachmentVoid)
+	err := c.cc.Invoke(ctx, AttachmentService_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attachmentServiceClient) Sign(ctx context.Context, in *AttachmentSignRequest, opts ...grpc.CallOption) (*AttachmentSignature, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttachmentSignature)
+	err := c.cc.Invoke(ctx, AttachmentService_Sign_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attachmentServiceClient) Download(ctx context.Context, in *AttachmentDownloadRequest, opts ...grpc.CallOption) (AttachmentService_DownloadClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &AttachmentService_ServiceDesc.Streams[1], AttachmentService_Download_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &attachmentServiceDownloadClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type AttachmentService_DownloadClient interface {
+	Recv() (*AttachmentChunk, error)
+	grpc.ClientStream
+}
+
+type attachmentServiceDownloadClient struct {
+	grpc.ClientStream
+}
+
+func (x *attachmentServiceDownloadClient) Recv() (*AttachmentChunk, error) {
+	m := new(AttachmentChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// AttachmentServiceServer is the server API for AttachmentService service.
+// All implementations must embed UnimplementedAttachmentServiceServer
+// for forward compatibility
+type AttachmentServiceServer interface {
+	Upload(AttachmentService_UploadServer) error
+	GetList(context.Context, *AttachmentListRequest) (*AttachmentList, error)
+	Delete(context.Context, *AttachmentDeleteRequest) (*AttachmentVoid, error)
+	Sign(context.Context, *AttachmentSignRequest) (*AttachmentSignature, error)
+	Download(*AttachmentDownloadRequest, AttachmentService_DownloadServer) error
+	mustEmbedUnimplementedAttachmentServiceServer()
+}
+
+// UnimplementedAttachmentServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedAttachmentServiceServer struct {
+}
+
+func (UnimplementedAttachmentServiceServer) Upload(AttachmentService_UploadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Upload not implemented")
+}
+func (UnimplementedAttachmentServiceServer) GetList(context.Context, *AttachmentListRequest) (*AttachmentList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetList not implemented")
+}
+func (UnimplementedAttachmentServiceServer) Delete(context.Context, *AttachmentDeleteRequest) (*AttachmentVoid, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedAttachmentServiceServer) Sign(context.Context, *AttachmentSignRequest) (*AttachmentSignature, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Sign not implemented")
+}
+func (UnimplementedAttachmentServiceServer) Download(*AttachmentDownloadRequest, AttachmentService_DownloadServer) error {
+	return status.Errorf(codes.Unimplemented, "method Download not implemented")
+}
+func (UnimplementedAttachmentServiceServer) mustEmbedUnimplementedAttachmentServiceServer() {}
+
+// UnsafeAttachmentServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to AttachmentServiceServer will
+// result in compilation errors.
+type UnsafeAttachmentServiceServer interface {
+	mustEmbedUnimplementedAttachmentServiceServer()
+}
+
+func RegisterAttachmentServiceServer(s grpc.ServiceRegistrar, srv AttachmentServiceServer) {
+	s.RegisterService(&AttachmentService_ServiceDesc, srv)
+}
+
+func _AttachmentService_Upload_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(AttachmentServiceServer).Upload(&attachmentServiceUploadServer{ServerStream: stream})
+}
+
+type AttachmentService_UploadServer interface {
+	SendAndClose(*Attachment) error
+	Recv() (*AttachmentUploadChunk, error)
+	grpc.ServerStream
+}
+
+type attachmentServiceUploadServer struct {
+	grpc.ServerStream
+}
+
+func (x *attachmentServiceUploadServer) SendAndClose(m *Attachment) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *attachmentServiceUploadServer) Recv() (*AttachmentUploadChunk, error) {
+	m := new(AttachmentUploadChunk)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _AttachmentService_GetList_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttachmentServiceServer).GetList(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AttachmentService_GetList_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttachmentServiceServer).GetList(ctx, req.(*AttachmentListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AttachmentService_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentDeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttachmentServiceServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AttachmentService_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttachmentServiceServer).Delete(ctx, req.(*AttachmentDeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AttachmentService_Sign_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentSignRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttachmentServiceServer).Sign(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AttachmentService_Sign_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttachmentServiceServer).Sign(ctx, req.(*AttachmentSignRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AttachmentService_Download_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(AttachmentDownloadRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(AttachmentServiceServer).Download(m, &attachmentServiceDownloadServer{ServerStream: stream})
+}
+
+type AttachmentService_DownloadServer interface {
+	Send(*AttachmentChunk) error
+	grpc.ServerStream
+}
+
+type attachmentServiceDownloadServer struct {
+	grpc.ServerStream
+}
+
+func (x *attachmentServiceDownloadServer) Send(m *AttachmentChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// AttachmentService_ServiceDesc is the grpc.ServiceDesc for AttachmentService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AttachmentService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "protos.AttachmentService",
+	HandlerType: (*AttachmentServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetList",
+			Handler:    _AttachmentService_GetList_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _AttachmentService_Delete_Handler,
+		},
+		{
+			MethodName: "Sign",
+			Handler:    _AttachmentService_Sign_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Upload",
+			Handler:       _AttachmentService_Upload_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Download",
+			Handler:       _AttachmentService_Download_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "internal/pkg/scripts/submodule/attachments.proto",
+}
@@ -19,11 +19,21 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	PostService_Create_FullMethodName    = "/protos.PostService/Create"
-	PostService_GetDetail_FullMethodName = "/protos.PostService/GetDetail"
-	PostService_Update_FullMethodName    = "/protos.PostService/Update"
-	PostService_Delete_FullMethodName    = "/protos.PostService/Delete"
-	PostService_GetList_FullMethodName   = "/protos.PostService/GetList"
+	PostService_Create_FullMethodName          = "/protos.PostService/Create"
+	PostService_GetDetail_FullMethodName       = "/protos.PostService/GetDetail"
+	PostService_GetBySlug_FullMethodName       = "/protos.PostService/GetBySlug"
+	PostService_Update_FullMethodName          = "/protos.PostService/Update"
+	PostService_Delete_FullMethodName          = "/protos.PostService/Delete"
+	PostService_GetList_FullMethodName         = "/protos.PostService/GetList"
+	PostService_Search_FullMethodName          = "/protos.PostService/Search"
+	PostService_Feed_FullMethodName            = "/protos.PostService/Feed"
+	PostService_React_FullMethodName           = "/protos.PostService/React"
+	PostService_Unreact_FullMethodName         = "/protos.PostService/Unreact"
+	PostService_ListTags_FullMethodName        = "/protos.PostService/ListTags"
+	PostService_ListRevisions_FullMethodName   = "/protos.PostService/ListRevisions"
+	PostService_GetRevision_FullMethodName     = "/protos.PostService/GetRevision"
+	PostService_DiffRevisions_FullMethodName   = "/protos.PostService/DiffRevisions"
+	PostService_RestoreRevision_FullMethodName = "/protos.PostService/RestoreRevision"
 )
 
 // PostServiceClient is the client API for PostService service.
@@ -32,9 +42,19 @@ const (
 type PostServiceClient interface {
 	Create(ctx context.Context, in *PostCreateRequest, opts ...grpc.CallOption) (*PostCreateResponse, error)
 	GetDetail(ctx context.Context, in *GetById, opts ...grpc.CallOption) (*PostGetResponse, error)
+	GetBySlug(ctx context.Context, in *PostSlugRequest, opts ...grpc.CallOption) (*PostGetResponse, error)
 	Update(ctx context.Context, in *PostUpdateRequest, opts ...grpc.CallOption) (*PostVoid, error)
 	Delete(ctx context.Context, in *GetById, opts ...grpc.CallOption) (*PostVoid, error)
 	GetList(ctx context.Context, in *FilterPost, opts ...grpc.CallOption) (*PostGetAll, error)
+	Search(ctx context.Context, in *PostSearchRequest, opts ...grpc.CallOption) (*PostSearchResponse, error)
+	Feed(ctx context.Context, in *FeedRequest, opts ...grpc.CallOption) (*FeedPage, error)
+	React(ctx context.Context, in *PostReactionRequest, opts ...grpc.CallOption) (*PostReactions, error)
+	Unreact(ctx context.Context, in *PostReactionRequest, opts ...grpc.CallOption) (*PostReactions, error)
+	ListTags(ctx context.Context, in *TagListRequest, opts ...grpc.CallOption) (*TagList, error)
+	ListRevisions(ctx context.Context, in *RevisionListRequest, opts ...grpc.CallOption) (*RevisionList, error)
+	GetRevision(ctx context.Context, in *RevisionRequest, opts ...grpc.CallOption) (*PostRevision, error)
+	DiffRevisions(ctx context.Context, in *RevisionDiffRequest, opts ...grpc.CallOption) (*RevisionDiff, error)
+	RestoreRevision(ctx context.Context, in *RevisionRequest, opts ...grpc.CallOption) (*PostVoid, error)
 }
 
 type postServiceClient struct {
@@ -65,6 +85,16 @@ func (c *postServiceClient) GetDetail(ctx context.Context, in *GetById, opts ...
 	return out, nil
 }
 
+func (c *postServiceClient) GetBySlug(ctx context.Context, in *PostSlugRequest, opts ...grpc.CallOption) (*PostGetResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PostGetResponse)
+	err := c.cc.Invoke(ctx, PostService_GetBySlug_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *postServiceClient) Update(ctx context.Context, in *PostUpdateRequest, opts ...grpc.CallOption) (*PostVoid, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(PostVoid)
@@ -95,15 +125,115 @@ func (c *postServiceClient) GetList(ctx context.Context, in *FilterPost, opts ..
 	return out, nil
 }
 
+func (c *postServiceClient) Search(ctx context.Context, in *PostSearchRequest, opts ...grpc.CallOption) (*PostSearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PostSearchResponse)
+	err := c.cc.Invoke(ctx, PostService_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) Feed(ctx context.Context, in *FeedRequest, opts ...grpc.CallOption) (*FeedPage, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FeedPage)
+	err := c.cc.Invoke(ctx, PostService_Feed_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) React(ctx context.Context, in *PostReactionRequest, opts ...grpc.CallOption) (*PostReactions, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PostReactions)
+	err := c.cc.Invoke(ctx, PostService_React_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) Unreact(ctx context.Context, in *PostReactionRequest, opts ...grpc.CallOption) (*PostReactions, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PostReactions)
+	err := c.cc.Invoke(ctx, PostService_Unreact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) ListTags(ctx context.Context, in *TagListRequest, opts ...grpc.CallOption) (*TagList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TagList)
+	err := c.cc.Invoke(ctx, PostService_ListTags_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) ListRevisions(ctx context.Context, in *RevisionListRequest, opts ...grpc.CallOption) (*RevisionList, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevisionList)
+	err := c.cc.Invoke(ctx, PostService_ListRevisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) GetRevision(ctx context.Context, in *RevisionRequest, opts ...grpc.CallOption) (*PostRevision, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PostRevision)
+	err := c.cc.Invoke(ctx, PostService_GetRevision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) DiffRevisions(ctx context.Context, in *RevisionDiffRequest, opts ...grpc.CallOption) (*RevisionDiff, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevisionDiff)
+	err := c.cc.Invoke(ctx, PostService_DiffRevisions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *postServiceClient) RestoreRevision(ctx context.Context, in *RevisionRequest, opts ...grpc.CallOption) (*PostVoid, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PostVoid)
+	err := c.cc.Invoke(ctx, PostService_RestoreRevision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // PostServiceServer is the server API for PostService service.
 // All implementations must embed UnimplementedPostServiceServer
 // for forward compatibility
 type PostServiceServer interface {
 	Create(context.Context, *PostCreateRequest) (*PostCreateResponse, error)
 	GetDetail(context.Context, *GetById) (*PostGetResponse, error)
+	GetBySlug(context.Context, *PostSlugRequest) (*PostGetResponse, error)
 	Update(context.Context, *PostUpdateRequest) (*PostVoid, error)
 	Delete(context.Context, *GetById) (*PostVoid, error)
 	GetList(context.Context, *FilterPost) (*PostGetAll, error)
+	Search(context.Context, *PostSearchRequest) (*PostSearchResponse, error)
+	Feed(context.Context, *FeedRequest) (*FeedPage, error)
+	React(context.Context, *PostReactionRequest) (*PostReactions, error)
+	Unreact(context.Context, *PostReactionRequest) (*PostReactions, error)
+	ListTags(context.Context, *TagListRequest) (*TagList, error)
+	ListRevisions(context.Context, *RevisionListRequest) (*RevisionList, error)
+	GetRevision(context.Context, *RevisionRequest) (*PostRevision, error)
+	DiffRevisions(context.Context, *RevisionDiffRequest) (*RevisionDiff, error)
+	RestoreRevision(context.Context, *RevisionRequest) (*PostVoid, error)
 	mustEmbedUnimplementedPostServiceServer()
 }
 
@@ -117,6 +247,9 @@ func (UnimplementedPostServiceServer) Create(context.Context, *PostCreateRequest
 func (UnimplementedPostServiceServer) GetDetail(context.Context, *GetById) (*PostGetResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetDetail not implemented")
 }
+func (UnimplementedPostServiceServer) GetBySlug(context.Context, *PostSlugRequest) (*PostGetResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBySlug not implemented")
+}
 func (UnimplementedPostServiceServer) Update(context.Context, *PostUpdateRequest) (*PostVoid, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Update not implemented")
 }
@@ -126,6 +259,33 @@ func (UnimplementedPostServiceServer) Delete(context.Context, *GetById) (*PostVo
 func (UnimplementedPostServiceServer) GetList(context.Context, *FilterPost) (*PostGetAll, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetList not implemented")
 }
+func (UnimplementedPostServiceServer) Search(context.Context, *PostSearchRequest) (*PostSearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedPostServiceServer) Feed(context.Context, *FeedRequest) (*FeedPage, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Feed not implemented")
+}
+func (UnimplementedPostServiceServer) React(context.Context, *PostReactionRequest) (*PostReactions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method React not implemented")
+}
+func (UnimplementedPostServiceServer) Unreact(context.Context, *PostReactionRequest) (*PostReactions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unreact not implemented")
+}
+func (UnimplementedPostServiceServer) ListTags(context.Context, *TagListRequest) (*TagList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTags not implemented")
+}
+func (UnimplementedPostServiceServer) ListRevisions(context.Context, *RevisionListRequest) (*RevisionList, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListRevisions not implemented")
+}
+func (UnimplementedPostServiceServer) GetRevision(context.Context, *RevisionRequest) (*PostRevision, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRevision not implemented")
+}
+func (UnimplementedPostServiceServer) DiffRevisions(context.Context, *RevisionDiffRequest) (*RevisionDiff, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DiffRevisions not implemented")
+}
+func (UnimplementedPostServiceServer) RestoreRevision(context.Context, *RevisionRequest) (*PostVoid, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RestoreRevision not implemented")
+}
 func (UnimplementedPostServiceServer) mustEmbedUnimplementedPostServiceServer() {}
 
 // UnsafePostServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -175,6 +335,24 @@ func _PostService_GetDetail_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PostService_GetBySlug_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostSlugRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).GetBySlug(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_GetBySlug_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).GetBySlug(ctx, req.(*PostSlugRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _PostService_Update_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(PostUpdateRequest)
 	if err := dec(in); err != nil {
@@ -229,6 +407,168 @@ func _PostService_GetList_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _PostService_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostSearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).Search(ctx, req.(*PostSearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_Feed_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FeedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).Feed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_Feed_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).Feed(ctx, req.(*FeedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_React_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).React(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_React_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).React(ctx, req.(*PostReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_Unreact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PostReactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).Unreact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_Unreact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).Unreact(ctx, req.(*PostReactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_ListTags_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).ListTags(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_ListTags_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).ListTags(ctx, req.(*TagListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_ListRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).ListRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_ListRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).ListRevisions(ctx, req.(*RevisionListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_GetRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).GetRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_GetRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).GetRevision(ctx, req.(*RevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_DiffRevisions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionDiffRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).DiffRevisions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_DiffRevisions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).DiffRevisions(ctx, req.(*RevisionDiffRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PostService_RestoreRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PostServiceServer).RestoreRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PostService_RestoreRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PostServiceServer).RestoreRevision(ctx, req.(*RevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // PostService_ServiceDesc is the grpc.ServiceDesc for PostService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -244,6 +584,10 @@ var PostService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetDetail",
 			Handler:    _PostService_GetDetail_Handler,
 		},
+		{
+			MethodName: "GetBySlug",
+			Handler:    _PostService_GetBySlug_Handler,
+		},
 		{
 			MethodName: "Update",
 			Handler:    _PostService_Update_Handler,
@@ -256,6 +600,42 @@ var PostService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetList",
 			Handler:    _PostService_GetList_Handler,
 		},
+		{
+			MethodName: "Search",
+			Handler:    _PostService_Search_Handler,
+		},
+		{
+			MethodName: "Feed",
+			Handler:    _PostService_Feed_Handler,
+		},
+		{
+			MethodName: "React",
+			Handler:    _PostService_React_Handler,
+		},
+		{
+			MethodName: "Unreact",
+			Handler:    _PostService_Unreact_Handler,
+		},
+		{
+			MethodName: "ListTags",
+			Handler:    _PostService_ListTags_Handler,
+		},
+		{
+			MethodName: "ListRevisions",
+			Handler:    _PostService_ListRevisions_Handler,
+		},
+		{
+			MethodName: "GetRevision",
+			Handler:    _PostService_GetRevision_Handler,
+		},
+		{
+			MethodName: "DiffRevisions",
+			Handler:    _PostService_DiffRevisions_Handler,
+		},
+		{
+			MethodName: "RestoreRevision",
+			Handler:    _PostService_RestoreRevision_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/pkg/scripts/submodule/posts.proto",
@@ -26,6 +26,12 @@ const (
 	UserService_Delete_FullMethodName             = "/protos.UserService/Delete"
 	UserService_GetList_FullMethodName            = "/protos.UserService/GetList"
 	UserService_Login_FullMethodName              = "/protos.UserService/Login"
+	UserService_ExportData_FullMethodName         = "/protos.UserService/ExportData"
+	UserService_Erase_FullMethodName              = "/protos.UserService/Erase"
+	UserService_Follow_FullMethodName             = "/protos.UserService/Follow"
+	UserService_Unfollow_FullMethodName           = "/protos.UserService/Unfollow"
+	UserService_ListFollowers_FullMethodName      = "/protos.UserService/ListFollowers"
+	UserService_ListFollowing_FullMethodName      = "/protos.UserService/ListFollowing"
 )
 
 // UserServiceClient is the client API for UserService service.
@@ -36,9 +42,15 @@ type UserServiceClient interface {
 	GetDetail(ctx context.Context, in *ById, opts ...grpc.CallOption) (*UserGetResponse, error)
 	Update(ctx context.Context, in *UserUpdateRequest, opts ...grpc.CallOption) (*Void, error)
 	ChangeUserPassword(ctx context.Context, in *UserRecoverPasswordRequest, opts ...grpc.CallOption) (*Void, error)
-	Delete(ctx context.Context, in *ById, opts ...grpc.CallOption) (*Void, error)
+	Delete(ctx context.Context, in *ById, opts ...grpc.CallOption) (*UserDeleteResponse, error)
 	GetList(ctx context.Context, in *FilterUser, opts ...grpc.CallOption) (*UserGetAll, error)
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*LoginResponse, error)
+	ExportData(ctx context.Context, in *UserExportRequest, opts ...grpc.CallOption) (UserService_ExportDataClient, error)
+	Erase(ctx context.Context, in *ById, opts ...grpc.CallOption) (*UserEraseResponse, error)
+	Follow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*Void, error)
+	Unfollow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*Void, error)
+	ListFollowers(ctx context.Context, in *FollowListRequest, opts ...grpc.CallOption) (*UserGetAll, error)
+	ListFollowing(ctx context.Context, in *FollowListRequest, opts ...grpc.CallOption) (*UserGetAll, error)
 }
 
 type userServiceClient struct {
@@ -89,9 +101,9 @@ func (c *userServiceClient) ChangeUserPassword(ctx context.Context, in *UserReco
 	return out, nil
 }
 
-func (c *userServiceClient) Delete(ctx context.Context, in *ById, opts ...grpc.CallOption) (*Void, error) {
+func (c *userServiceClient) Delete(ctx context.Context, in *ById, opts ...grpc.CallOption) (*UserDeleteResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(Void)
+	out := new(UserDeleteResponse)
 	err := c.cc.Invoke(ctx, UserService_Delete_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -119,6 +131,89 @@ func (c *userServiceClient) Login(ctx context.Context, in *LoginRequest, opts ..
 	return out, nil
 }
 
+func (c *userServiceClient) ExportData(ctx context.Context, in *UserExportRequest, opts ...grpc.CallOption) (UserService_ExportDataClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &UserService_ServiceDesc.Streams[0], UserService_ExportData_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &userServiceExportDataClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type UserService_ExportDataClient interface {
+	Recv() (*UserExportChunk, error)
+	grpc.ClientStream
+}
+
+type userServiceExportDataClient struct {
+	grpc.ClientStream
+}
+
+func (x *userServiceExportDataClient) Recv() (*UserExportChunk, error) {
+	m := new(UserExportChunk)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *userServiceClient) Erase(ctx context.Context, in *ById, opts ...grpc.CallOption) (*UserEraseResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserEraseResponse)
+	err := c.cc.Invoke(ctx, UserService_Erase_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) Follow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*Void, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Void)
+	err := c.cc.Invoke(ctx, UserService_Follow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) Unfollow(ctx context.Context, in *FollowRequest, opts ...grpc.CallOption) (*Void, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Void)
+	err := c.cc.Invoke(ctx, UserService_Unfollow_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ListFollowers(ctx context.Context, in *FollowListRequest, opts ...grpc.CallOption) (*UserGetAll, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserGetAll)
+	err := c.cc.Invoke(ctx, UserService_ListFollowers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *userServiceClient) ListFollowing(ctx context.Context, in *FollowListRequest, opts ...grpc.CallOption) (*UserGetAll, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserGetAll)
+	err := c.cc.Invoke(ctx, UserService_ListFollowing_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UserServiceServer is the server API for UserService service.
 // All implementations must embed UnimplementedUserServiceServer
 // for forward compatibility
@@ -127,9 +222,15 @@ type UserServiceServer interface {
 	GetDetail(context.Context, *ById) (*UserGetResponse, error)
 	Update(context.Context, *UserUpdateRequest) (*Void, error)
 	ChangeUserPassword(context.Context, *UserRecoverPasswordRequest) (*Void, error)
-	Delete(context.Context, *ById) (*Void, error)
+	Delete(context.Context, *ById) (*UserDeleteResponse, error)
 	GetList(context.Context, *FilterUser) (*UserGetAll, error)
 	Login(context.Context, *LoginRequest) (*LoginResponse, error)
+	ExportData(*UserExportRequest, UserService_ExportDataServer) error
+	Erase(context.Context, *ById) (*UserEraseResponse, error)
+	Follow(context.Context, *FollowRequest) (*Void, error)
+	Unfollow(context.Context, *FollowRequest) (*Void, error)
+	ListFollowers(context.Context, *FollowListRequest) (*UserGetAll, error)
+	ListFollowing(context.Context, *FollowListRequest) (*UserGetAll, error)
 	mustEmbedUnimplementedUserServiceServer()
 }
 
@@ -149,7 +250,7 @@ func (UnimplementedUserServiceServer) Update(context.Context, *UserUpdateRequest
 func (UnimplementedUserServiceServer) ChangeUserPassword(context.Context, *UserRecoverPasswordRequest) (*Void, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ChangeUserPassword not implemented")
 }
-func (UnimplementedUserServiceServer) Delete(context.Context, *ById) (*Void, error) {
+func (UnimplementedUserServiceServer) Delete(context.Context, *ById) (*UserDeleteResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
 }
 func (UnimplementedUserServiceServer) GetList(context.Context, *FilterUser) (*UserGetAll, error) {
@@ -158,6 +259,24 @@ func (UnimplementedUserServiceServer) GetList(context.Context, *FilterUser) (*Us
 func (UnimplementedUserServiceServer) Login(context.Context, *LoginRequest) (*LoginResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Login not implemented")
 }
+func (UnimplementedUserServiceServer) ExportData(*UserExportRequest, UserService_ExportDataServer) error {
+	return status.Errorf(codes.Unimplemented, "method ExportData not implemented")
+}
+func (UnimplementedUserServiceServer) Erase(context.Context, *ById) (*UserEraseResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Erase not implemented")
+}
+func (UnimplementedUserServiceServer) Follow(context.Context, *FollowRequest) (*Void, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Follow not implemented")
+}
+func (UnimplementedUserServiceServer) Unfollow(context.Context, *FollowRequest) (*Void, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Unfollow not implemented")
+}
+func (UnimplementedUserServiceServer) ListFollowers(context.Context, *FollowListRequest) (*UserGetAll, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFollowers not implemented")
+}
+func (UnimplementedUserServiceServer) ListFollowing(context.Context, *FollowListRequest) (*UserGetAll, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListFollowing not implemented")
+}
 func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
 
 // UnsafeUserServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -297,6 +416,117 @@ func _UserService_Login_Handler(srv interface{}, ctx context.Context, dec func(i
 	return interceptor(ctx, in, info, handler)
 }
 
+func _UserService_ExportData_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(UserExportRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(UserServiceServer).ExportData(m, &userServiceExportDataServer{ServerStream: stream})
+}
+
+type UserService_ExportDataServer interface {
+	Send(*UserExportChunk) error
+	grpc.ServerStream
+}
+
+type userServiceExportDataServer struct {
+	grpc.ServerStream
+}
+
+func (x *userServiceExportDataServer) Send(m *UserExportChunk) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _UserService_Erase_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ById)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).Erase(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_Erase_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).Erase(ctx, req.(*ById))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_Follow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).Follow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_Follow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).Follow(ctx, req.(*FollowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_Unfollow_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).Unfollow(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_Unfollow_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).Unfollow(ctx, req.(*FollowRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ListFollowers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ListFollowers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ListFollowers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ListFollowers(ctx, req.(*FollowListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _UserService_ListFollowing_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FollowListRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UserServiceServer).ListFollowing(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: UserService_ListFollowing_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UserServiceServer).ListFollowing(ctx, req.(*FollowListRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // UserService_ServiceDesc is the grpc.ServiceDesc for UserService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -332,7 +562,33 @@ var UserService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Login",
 			Handler:    _UserService_Login_Handler,
 		},
+		{
+			MethodName: "Erase",
+			Handler:    _UserService_Erase_Handler,
+		},
+		{
+			MethodName: "Follow",
+			Handler:    _UserService_Follow_Handler,
+		},
+		{
+			MethodName: "Unfollow",
+			Handler:    _UserService_Unfollow_Handler,
+		},
+		{
+			MethodName: "ListFollowers",
+			Handler:    _UserService_ListFollowers_Handler,
+		},
+		{
+			MethodName: "ListFollowing",
+			Handler:    _UserService_ListFollowing_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ExportData",
+			Handler:       _UserService_ExportData_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/pkg/scripts/submodule/users.proto",
 }
@@ -0,0 +1,77 @@
+// Package health runs the dependency checks behind the liveness and
+// readiness endpoints.
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Timeout bounds every check; a dependency slower than this is reported
+// as failing.
+const Timeout = 2 * time.Second
+
+// Check reports whether a dependency is usable.
+type Check func(ctx context.Context) error
+
+// Checker holds the checks readiness depends on, by dependency name.
+type Checker map[string]Check
+
+// Result is the outcome of one check.
+type Result struct {
+	Status    string  `json:"status"`
+	Error     string  `json:"error,omitempty"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// Report is the outcome of every check; Status is ok only when all of
+// them passed.
+type Report struct {
+	Status string            `json:"status"`
+	Checks map[string]Result `json:"checks,omitempty"`
+}
+
+// Statuses of a report and of its checks.
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// OK reports whether every check passed.
+func (r Report) OK() bool {
+	return r.Status == StatusOK
+}
+
+// Run runs the checks concurrently, each bounded by Timeout.
+func (c Checker) Run(ctx context.Context) Report {
+	report := Report{Status: StatusOK, Checks: make(map[string]Result, len(c))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for name, check := range c {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, Timeout)
+			defer cancel()
+			start := time.Now()
+			err := check(ctx)
+
+			result := Result{Status: StatusOK, LatencyMs: float64(time.Since(start).Microseconds()) / 1000}
+			if err != nil {
+				result.Status, result.Error = StatusFail, err.Error()
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			report.Checks[name] = result
+			if err != nil {
+				report.Status = StatusFail
+			}
+		}()
+	}
+	wg.Wait()
+	return report
+}
@@ -0,0 +1,59 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strconv"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// Metadata keys that pass the request id and the authenticated user along
+// with gRPC calls, so that the post service logs them too.
+const (
+	RequestIDHeader = "x-request-id"
+	UserIDHeader    = "x-user-id"
+)
+
+// Keys under which the request middleware stores the request's logger, id
+// and user in the gin context. Handlers pass the gin context to gRPC
+// calls, and gin answers Value for string keys from the same store.
+const (
+	LoggerKey    = "logger"
+	RequestIDKey = "request_id"
+	UserIDKey    = "user_id"
+)
+
+// UnaryClientInterceptor forwards the request and user ids found in the
+// call's context as metadata.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoing(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// StreamClientInterceptor is UnaryClientInterceptor for streaming calls.
+func StreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoing(ctx), desc, cc, method, opts...)
+	}
+}
+
+// NewRequestID returns a random id for requests that arrive without one.
+func NewRequestID() string {
+	var random [8]byte
+	rand.Read(random[:])
+	return hex.EncodeToString(random[:])
+}
+
+func outgoing(ctx context.Context) context.Context {
+	if id, ok := ctx.Value(RequestIDKey).(string); ok && id != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx, RequestIDHeader, id)
+	}
+	if id, ok := ctx.Value(UserIDKey).(int64); ok && id != 0 {
+		ctx = metadata.AppendToOutgoingContext(ctx, UserIDHeader, strconv.FormatInt(id, 10))
+	}
+	return ctx
+}
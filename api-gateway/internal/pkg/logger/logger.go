@@ -1,32 +1,177 @@
+// Package logger builds the service's structured JSON logger. Records go to
+// stdout and, when a file is configured, to a size-rotated file as well.
 package logger
 
 import (
-	"log"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Levels beyond the four slog defines.
+const (
+	LevelTrace = slog.Level(-8)
+	LevelFatal = slog.Level(12)
 )
 
+var levelNames = map[slog.Level]string{
+	LevelTrace:      "TRACE",
+	slog.LevelDebug: "DEBUG",
+	slog.LevelInfo:  "INFO",
+	slog.LevelWarn:  "WARN",
+	slog.LevelError: "ERROR",
+	LevelFatal:      "FATAL",
+}
+
+type Config struct {
+	// Level is the least severe level written: trace, debug, info, warn
+	// or error.
+	Level string
+	// File, when set, receives a copy of the output and is rotated once
+	// it grows past MaxSizeMB.
+	File       string
+	MaxSizeMB  int
+	MaxBackups int
+	MaxAgeDays int
+	// Sink, when set, receives the records as well, filtered by its own
+	// level.
+	Sink slog.Handler
+}
+
+// Logger is a slog.Logger whose level can be changed while it is in use.
 type Logger struct {
-	INFO  *log.Logger
-	WARN  *log.Logger
-	ERROR *log.Logger
-	DEBUG *log.Logger
-	TRACE *log.Logger
+	*slog.Logger
+	level *slog.LevelVar
+}
+
+func New(cfg Config) (*Logger, error) {
+	level := new(slog.LevelVar)
+	if cfg.Level != "" {
+		l, err := ParseLevel(cfg.Level)
+		if err != nil {
+			return nil, err
+		}
+		level.Set(l)
+	}
+
+	var out io.Writer = os.Stdout
+	if cfg.File != "" {
+		out = io.MultiWriter(os.Stdout, &lumberjack.Logger{
+			Filename:   cfg.File,
+			MaxSize:    cfg.MaxSizeMB,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+		})
+	}
+
+	var handler slog.Handler = slog.NewJSONHandler(out, &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: replaceLevel,
+	})
+	if cfg.Sink != nil {
+		handler = fanout{handler, cfg.Sink}
+	}
+	return &Logger{Logger: slog.New(handler), level: level}, nil
 }
 
-func NewLogger(basepath, path string) *Logger {
-	l := &Logger{}
+// Level returns the name of the least severe level written.
+func (l *Logger) Level() string {
+	return LevelName(l.level.Level())
+}
 
-	fullpath := basepath + "/" + path
-	var file, err = os.OpenFile(fullpath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+// SetLevel changes the least severe level written, effective immediately
+// for every logger derived from l.
+func (l *Logger) SetLevel(name string) error {
+	level, err := ParseLevel(name)
 	if err != nil {
-		log.Println("Error opening file:", err)
+		return err
+	}
+	l.level.Set(level)
+	return nil
+}
+
+// ParseLevel maps a level name, in any case, to its slog level.
+func ParseLevel(name string) (slog.Level, error) {
+	for level, n := range levelNames {
+		if strings.EqualFold(name, n) && level != LevelFatal {
+			return level, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level %q, expected trace, debug, info, warn or error", name)
+}
+
+func LevelName(level slog.Level) string {
+	if name, ok := levelNames[level]; ok {
+		return strings.ToLower(name)
+	}
+	return strings.ToLower(level.String())
+}
+
+// replaceLevel writes the custom levels by name instead of as DEBUG-4
+// and ERROR+4.
+func replaceLevel(_ []string, a slog.Attr) slog.Attr {
+	if a.Key != slog.LevelKey {
+		return a
+	}
+	if level, ok := a.Value.Any().(slog.Level); ok {
+		if name, ok := levelNames[level]; ok {
+			a.Value = slog.StringValue(name)
+		}
+	}
+	return a
+}
+
+// fanout passes records to every handler that accepts their level.
+type fanout []slog.Handler
+
+func (f fanout) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, h := range f {
+		if h.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f fanout) Handle(ctx context.Context, r slog.Record) error {
+	var errs []error
+	for _, h := range f {
+		if h.Enabled(ctx, r.Level) {
+			errs = append(errs, h.Handle(ctx, r.Clone()))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (f fanout) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := make(fanout, len(f))
+	for i, h := range f {
+		derived[i] = h.WithAttrs(attrs)
 	}
+	return derived
+}
 
-	l.INFO = log.New(file, "[INFO]  ", log.Lshortfile|log.LstdFlags)
-	l.WARN = log.New(file, "[WARN]  ", log.Lshortfile|log.LstdFlags)
-	l.ERROR = log.New(file, "[ERROR]  ", log.Lshortfile|log.LstdFlags)
-	l.DEBUG = log.New(file, "[DEBUG]  ", log.Lshortfile|log.LstdFlags)
-	l.TRACE = log.New(file, "[TRACE]  ", log.Lshortfile|log.LstdFlags)
+func (f fanout) WithGroup(name string) slog.Handler {
+	derived := make(fanout, len(f))
+	for i, h := range f {
+		derived[i] = h.WithGroup(name)
+	}
+	return derived
+}
+
+// Trace logs at LevelTrace.
+func Trace(ctx context.Context, l *slog.Logger, msg string, args ...any) {
+	l.Log(ctx, LevelTrace, msg, args...)
+}
 
-	return l
+// Fatal logs at LevelFatal and exits the process.
+func Fatal(l *slog.Logger, msg string, args ...any) {
+	l.Log(context.Background(), LevelFatal, msg, args...)
+	os.Exit(1)
 }
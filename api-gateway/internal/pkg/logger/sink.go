@@ -0,0 +1,248 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	pb "posts/internal/pkg/genproto"
+)
+
+// TopicLogIngest is the topic sinks publish log batches on.
+const TopicLogIngest = "log-ingest"
+
+// shipKey marks loggers whose records stay out of the sink; see
+// NotShipped.
+const shipKey = "ship"
+
+// Producer publishes a message on a topic; both services' Kafka producers
+// satisfy it.
+type Producer interface {
+	ProduceMessages(ctx context.Context, topic string, message []byte) error
+}
+
+type SinkConfig struct {
+	// Service is stored as the records' service name.
+	Service string
+	// Level is the least severe level shipped.
+	Level string
+	// A batch is published once it holds BatchSize records or is
+	// FlushInterval old, whichever comes first.
+	BatchSize     int
+	FlushInterval time.Duration
+}
+
+// Sink is a slog handler that ships records to the log-ingest topic in
+// batches. Publishing happens in the background; when Kafka cannot keep
+// up, records beyond ten batches are dropped and the loss is reported on
+// stderr.
+type Sink struct {
+	*sinkState
+	attrs map[string]string
+	group string
+	skip  bool
+}
+
+type sinkState struct {
+	producer Producer
+	cfg      SinkConfig
+	level    slog.Level
+
+	mu      sync.Mutex
+	batch   []*pb.LogRecord
+	dropped int
+
+	flush chan struct{}
+	done  chan struct{}
+	wg    sync.WaitGroup
+}
+
+func NewSink(producer Producer, cfg SinkConfig) (*Sink, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 2 * time.Second
+	}
+
+	state := &sinkState{
+		producer: producer,
+		cfg:      cfg,
+		level:    level,
+		flush:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
+	}
+	state.wg.Add(1)
+	go state.run()
+	return &Sink{sinkState: state}, nil
+}
+
+// NotShipped returns a logger whose records are written locally but not
+// shipped. The log-ingest consumer uses it so that its own failures do not
+// feed back into the topic.
+func NotShipped(l *slog.Logger) *slog.Logger {
+	return l.With(shipKey, false)
+}
+
+func (s *Sink) Enabled(_ context.Context, level slog.Level) bool {
+	return !s.skip && level >= s.level
+}
+
+func (s *Sink) Handle(_ context.Context, r slog.Record) error {
+	if s.skip {
+		return nil
+	}
+
+	attrs := make(map[string]string, len(s.attrs)+r.NumAttrs())
+	for k, v := range s.attrs {
+		attrs[k] = v
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(attrs, s.group, a)
+		return true
+	})
+	record := &pb.LogRecord{
+		Level:       storedLevel(r.Level),
+		Message:     r.Message,
+		ServiceName: s.cfg.Service,
+		Time:        r.Time.UTC().Format(time.RFC3339Nano),
+		Attributes:  attrs,
+	}
+
+	s.mu.Lock()
+	if len(s.batch) >= 10*s.cfg.BatchSize {
+		s.dropped++
+	} else {
+		s.batch = append(s.batch, record)
+	}
+	full := len(s.batch) >= s.cfg.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		select {
+		case s.flush <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (s *Sink) WithAttrs(attrs []slog.Attr) slog.Handler {
+	derived := *s
+	derived.attrs = make(map[string]string, len(s.attrs)+len(attrs))
+	for k, v := range s.attrs {
+		derived.attrs[k] = v
+	}
+	for _, a := range attrs {
+		if a.Key == shipKey && a.Value.Kind() == slog.KindBool && !a.Value.Bool() {
+			derived.skip = true
+			continue
+		}
+		addAttr(derived.attrs, s.group, a)
+	}
+	return &derived
+}
+
+func (s *Sink) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return s
+	}
+	derived := *s
+	derived.group = s.group + name + "."
+	return &derived
+}
+
+// Close publishes the records still waiting and stops the sink.
+func (s *Sink) Close() error {
+	close(s.done)
+	s.wg.Wait()
+	return nil
+}
+
+func (s *sinkState) run() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			s.publish()
+			return
+		case <-ticker.C:
+		case <-s.flush:
+		}
+		s.publish()
+	}
+}
+
+// publish sends the waiting records as one batch. Failures are reported
+// on stderr rather than logged, which would feed them back into the sink.
+func (s *sinkState) publish() {
+	s.mu.Lock()
+	batch, dropped := s.batch, s.dropped
+	s.batch, s.dropped = nil, 0
+	s.mu.Unlock()
+
+	if dropped > 0 {
+		fmt.Fprintf(os.Stderr, "log sink: dropped %d records\n", dropped)
+	}
+	if len(batch) == 0 {
+		return
+	}
+
+	payload, err := protojson.Marshal(&pb.LogBatch{Record: batch})
+	if err == nil {
+		err = s.producer.ProduceMessages(context.Background(), TopicLogIngest, payload)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "log sink: failed to publish %d records: %v\n", len(batch), err)
+	}
+}
+
+// storedLevel maps a slog level to the levels the logs table accepts.
+func storedLevel(level slog.Level) string {
+	switch {
+	case level >= LevelFatal:
+		return "FATAL"
+	case level >= slog.LevelError:
+		return "ERROR"
+	case level >= slog.LevelWarn:
+		return "WARNING"
+	case level >= slog.LevelInfo:
+		return "INFO"
+	case level >= slog.LevelDebug:
+		return "DEBUG"
+	default:
+		return "TRACE"
+	}
+}
+
+// addAttr stores an attribute under its key, prefixed with the names of
+// the groups it is in, separated by dots.
+func addAttr(attrs map[string]string, group string, a slog.Attr) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if a.Value.Kind() == slog.KindGroup {
+		prefix := group
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			addAttr(attrs, prefix, ga)
+		}
+		return
+	}
+	attrs[group+a.Key] = a.Value.String()
+}
@@ -3,6 +3,7 @@ package token
 import (
 	"errors"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt"
@@ -76,3 +77,18 @@ func ExtractClaim(tokenStr string) (jwt.MapClaims, error) {
 
 	return claims, nil
 }
+
+// UserID returns the "user_id" claim of an Authorization header value. The
+// "Bearer " prefix is optional.
+func UserID(authorization string) (int64, error) {
+	claims, err := ExtractClaim(strings.TrimPrefix(authorization, "Bearer "))
+	if err != nil {
+		return 0, err
+	}
+
+	id, ok := claims["user_id"].(float64)
+	if !ok {
+		return 0, errors.New("token has no user_id claim")
+	}
+	return int64(id), nil
+}
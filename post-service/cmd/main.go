@@ -1,11 +1,19 @@
 package main
 
 import (
+	"os"
+
 	"posts/internal/app"
 	"posts/internal/pkg/config"
 )
 
 func main() {
 	cfg := config.New()
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		app.Migrate(cfg, os.Args[2:])
+		return
+	}
+
 	app.Run(cfg)
 }
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"posts/internal/pkg/health"
+	"posts/internal/pkg/logger"
+	"posts/internal/pkg/metrics"
+)
+
+// runAdmin serves the operational HTTP endpoints on addr until ctx is
+// done. An empty addr disables them.
+func runAdmin(ctx context.Context, addr string, checks health.Checker) {
+	if addr == "" {
+		logger.FromContext(ctx).Info("admin server disabled")
+		return
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.Handle("GET /healthz", health.Live())
+	mux.Handle("GET /readyz", health.Ready(checks))
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	context.AfterFunc(ctx, func() { server.Close() })
+
+	logger.FromContext(ctx).Info("admin server started", "port", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.FromContext(ctx).Error("admin server stopped", "error", err)
+	}
+}
+
+// runReadiness runs checks every interval until ctx is done and reports
+// the server as serving over grpc.health.v1 only while all of them pass.
+func runReadiness(ctx context.Context, server *grpchealth.Server, checks health.Checker, interval time.Duration) {
+	if interval <= 0 {
+		logger.FromContext(ctx).Info("readiness checks disabled")
+		server.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	ready := false
+	for {
+		report := checks.Run(ctx)
+		switch {
+		case report.OK() && !ready:
+			logger.FromContext(ctx).Info("server is ready")
+			server.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+		case !report.OK() && ready:
+			logger.FromContext(ctx).Warn("server is not ready", "checks", report.Checks)
+			server.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+		ready = report.OK()
+
+		select {
+		case <-ctx.Done():
+			server.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
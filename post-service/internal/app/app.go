@@ -1,67 +1,261 @@
 package app
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"log/slog"
 	"net"
+	"net/smtp"
 
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"posts/internal/pkg/blob"
 	"posts/internal/pkg/config"
 	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/health"
+	"posts/internal/pkg/logger"
+	"posts/internal/pkg/metrics"
 	"posts/internal/pkg/postgres"
+	"posts/internal/pkg/tracing"
 	"posts/internal/repository/postgres"
 	"posts/internal/usecase/kafka"
+	"posts/internal/usecase/notify"
 	"posts/internal/usecase/service"
 )
 
+// serviceName identifies this service in shipped logs.
+const serviceName = "post-service"
+
 func Run(cf *config.Config) {
+	// connect to kafka producer; the logger ships records through it
+	kf_p, err := kafka.NewKafkaProducer([]string{cf.KafkaUrl})
+	if err != nil {
+		logger.Fatal(slog.Default(), "failed to connect to kafka", "error", err)
+	}
+
+	var sink *logger.Sink
+	if cf.LogShip {
+		sink, err = logger.NewSink(kf_p, logger.SinkConfig{
+			Service:       serviceName,
+			Level:         cf.LogShipLevel,
+			BatchSize:     cf.LogShipBatchSize,
+			FlushInterval: cf.LogShipFlushInterval,
+		})
+		if err != nil {
+			logger.Fatal(slog.Default(), "invalid log shipping configuration", "error", err)
+		}
+		defer sink.Close()
+	}
+
+	levels := newLogger(cf, sink)
+	l := levels.Logger
+	ctx := logger.WithContext(context.Background(), l)
+
+	shutdownTracing, err := tracing.Init(ctx, tracing.Config{
+		Service:     serviceName,
+		Exporter:    cf.TracingExporter,
+		Endpoint:    cf.TracingEndpoint,
+		SampleRatio: cf.TracingSampleRatio,
+	})
+	if err != nil {
+		logger.Fatal(l, "invalid tracing configuration", "error", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	// connect to postgres
+	l.Info("connecting to database", "host", cf.PostgresHost, "port", cf.PostgresPort)
 	pgm, err := postgres.NewPostgresStorage(cf)
-	cfg := config.New()
-	log.Printf("Connecting to DB: %s:%s", cfg.PostgresHost, cfg.PostgresPort)
-
-	// connect to kafka producer
-	kf_p, err := kafka.NewKafkaProducer([]string{cf.KafkaUrl})
 	if err != nil {
-		log.Fatal(err)
+		logger.Fatal(l, "failed to connect to database", "error", err)
 	}
+
+	// apply pending migrations
+	if cf.MigrateOnStart {
+		if err := migrateUp(ctx, pgm.DB); err != nil {
+			logger.Fatal(l, "failed to migrate database", "error", err)
+		}
+	}
+
 	// repo
 	if pgm == nil || pgm.DB == nil {
-		log.Fatal("Postgres connection is nil")
+		logger.Fatal(l, "postgres connection is nil")
 	}
 	db := repo.NewStorage(pgm.DB)
+	metrics.RegisterDB(pgm.DB, "posts")
 
 	//db := repo.NewStorage(pgm.DB)
-	log.Println("Connected to database")
+	l.Info("connected to database")
+
+	deletion, err := deletionPolicies(cf)
+	if err != nil {
+		logger.Fatal(l, "invalid deletion policy", "error", err)
+	}
+
+	// wake log tails when logs are inserted
+	logsInserted, err := postgres.NewListener(cf, "logs_inserted")
+	if err != nil {
+		logger.Fatal(l, "failed to listen for new logs", "error", err)
+	}
+	defer logsInserted.Close()
+
+	logService := service.NewLogService(db, kf_p, logsInserted)
+	postService := service.NewPostService(db, kf_p)
+	notificationService := service.NewNotificationService(db,
+		notify.NewInApp(db.Notification()),
+		notify.NewEmail(mailer(cf)),
+		notify.NewWebhook(),
+	)
+	webhookService := service.NewWebhookService(db, service.WebhookOptions{
+		MaxAttempts:  cf.WebhookMaxAttempts,
+		DisableAfter: cf.WebhookDisableAfter,
+	})
 
 	// register kafka handlers
 	k_handler := KafkaHandler{
-		log:  service.NewLogService(db, kf_p),
-		post: service.NewPostService(db, kf_p),
+		logger:       l,
+		log:          logService,
+		post:         postService,
+		notification: notificationService,
+		webhook:      webhookService,
+	}
+
+	if err := Registries(&k_handler, cf, kf_p); err != nil {
+		logger.Fatal(l, "failed to register kafka consumers", "error", err)
+	}
+
+	// publish scheduled posts in the background
+	go runScheduler(ctx, postService, cf.PublishInterval)
+
+	// send queued webhook deliveries in the background
+	go runWebhookDeliveries(ctx, webhookService, cf.WebhookInterval)
+
+	blobs, err := blobStore(cf)
+	if err != nil {
+		logger.Fatal(l, "failed to open blob store", "error", err)
 	}
+	attachmentService := service.NewAttachmentService(db, blobs, service.AttachmentOptions{
+		MaxSize:   cf.AttachmentMaxSize,
+		URLSecret: cf.AttachmentURLSecret,
+	})
+
+	// remove blobs of deleted attachments in the background
+	go runAttachmentGC(ctx, attachmentService, cf.AttachmentGCInterval, cf.AttachmentGCGrace)
+
+	retentionOpts := service.LogRetentionOptions{Retention: cf.LogRetention}
+	if cf.LogArchive {
+		retentionOpts.Archive = blobs
+	}
+
+	// keep log partitions ahead of time and expire old ones in the background
+	go runLogMaintenance(ctx, service.NewLogRetention(db, retentionOpts), cf.LogMaintenanceInterval)
 
-	if err := Registries(&k_handler, cf); err != nil {
-		log.Fatal(err)
+	// the server reports ready once the database and kafka are reachable
+	checks := health.Checker{
+		"postgres": health.DB(pgm.DB),
+		"kafka":    health.Kafka([]string{cf.KafkaUrl}),
 	}
+	healthServer := grpchealth.NewServer()
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	go runReadiness(ctx, healthServer, checks, cf.HealthCheckInterval)
+
+	// serve metrics and health on the admin port
+	go runAdmin(ctx, cf.AdminPort, checks)
 
 	lis, err := net.Listen("tcp", cf.GRPCPort)
 	if cf.GRPCPort == "" {
-		log.Fatal("GRPC port is not set in config")
+		logger.Fatal(l, "GRPC port is not set in config")
 	}
 
 	if err != nil {
-		log.Fatal("Failed to listen: ", err)
+		logger.Fatal(l, "failed to listen", "error", err)
 	}
 	// set grpc server
-	server := grpc.NewServer()
-	pb.RegisterUserServiceServer(server, service.NewUserService(db))
-	pb.RegisterLogServiceServer(server, service.NewLogService(db, kf_p))
-	pb.RegisterPostServiceServer(server, service.NewPostService(db, kf_p))
+	server := grpc.NewServer(
+		grpc.StatsHandler(otelgrpc.NewServerHandler(otelgrpc.WithFilter(filters.Not(filters.HealthCheck())))),
+		grpc.ChainUnaryInterceptor(metrics.UnaryServerInterceptor(), logger.UnaryServerInterceptor(l)),
+		grpc.ChainStreamInterceptor(metrics.StreamServerInterceptor(), logger.StreamServerInterceptor(l)),
+	)
+	pb.RegisterUserServiceServer(server, service.NewUserService(db, deletion, kf_p))
+	pb.RegisterLogServiceServer(server, logService)
+	pb.RegisterPostServiceServer(server, postService)
+	pb.RegisterCommentServiceServer(server, service.NewCommentService(db, kf_p))
+	pb.RegisterAttachmentServiceServer(server, attachmentService)
+	pb.RegisterNotificationServiceServer(server, notificationService)
+	pb.RegisterWebhookServiceServer(server, webhookService)
+	pb.RegisterAdminServiceServer(server, service.NewAdminService(levels))
+	healthpb.RegisterHealthServer(server, healthServer)
 
 	// start server
 
-	log.Println("Server started on", cf.GRPCPort)
+	l.Info("server started", "port", cf.GRPCPort)
 	if err = server.Serve(lis); err != nil {
-		log.Fatalf("failed to start server: %v", err)
+		logger.Fatal(l, "failed to start server", "error", err)
 	}
 	defer lis.Close()
 }
+
+// newLogger builds the logger from the configuration and makes it the
+// default, so that the standard log package writes through it as well.
+// Records also go to sink unless it is nil.
+func newLogger(cf *config.Config, sink *logger.Sink) *logger.Logger {
+	lc := logger.Config{
+		Level:      cf.LogLevel,
+		File:       cf.LogFile,
+		MaxSizeMB:  cf.LogMaxSizeMB,
+		MaxBackups: cf.LogMaxBackups,
+		MaxAgeDays: cf.LogMaxAgeDays,
+	}
+	if sink != nil {
+		lc.Sink = sink
+	}
+	l, err := logger.New(lc)
+	if err != nil {
+		logger.Fatal(slog.Default(), "invalid logging configuration", "error", err)
+	}
+	slog.SetDefault(l.Logger)
+	return l
+}
+
+func deletionPolicies(cf *config.Config) (service.DeletionPolicies, error) {
+	posts, err := service.ParseDeletionPolicy(cf.UserDeletePosts)
+	if err != nil {
+		return service.DeletionPolicies{}, fmt.Errorf("USER_DELETE_POSTS: %w", err)
+	}
+	logs, err := service.ParseDeletionPolicy(cf.UserDeleteLogs)
+	if err != nil {
+		return service.DeletionPolicies{}, fmt.Errorf("USER_DELETE_LOGS: %w", err)
+	}
+	return service.DeletionPolicies{Posts: posts, Logs: logs}, nil
+}
+
+func blobStore(cf *config.Config) (blob.Store, error) {
+	switch cf.BlobStore {
+	case "", "local":
+		return blob.NewLocal(cf.BlobDir)
+	case "s3":
+		return blob.NewS3(context.Background(), blob.S3Config{
+			Endpoint:  cf.S3Endpoint,
+			Region:    cf.S3Region,
+			Bucket:    cf.S3Bucket,
+			AccessKey: cf.S3AccessKey,
+			SecretKey: cf.S3SecretKey,
+			UseSSL:    cf.S3UseSSL,
+		})
+	default:
+		return nil, fmt.Errorf("BLOB_STORE: unknown store %q, expected local or s3", cf.BlobStore)
+	}
+}
+
+func mailer(cf *config.Config) notify.Mailer {
+	if cf.SMTPHost == "" {
+		return notify.LogMailer{}
+	}
+	m := &notify.SMTPMailer{Addr: net.JoinHostPort(cf.SMTPHost, cf.SMTPPort), From: cf.SMTPFrom}
+	if cf.SMTPUsername != "" {
+		m.Auth = smtp.PlainAuth("", cf.SMTPUsername, cf.SMTPPassword, cf.SMTPHost)
+	}
+	return m
+}
@@ -2,90 +2,197 @@ package app
 
 import (
 	"context"
-	"log"
+	"log/slog"
 
 	"google.golang.org/protobuf/encoding/protojson"
 	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/logger"
+	"posts/internal/usecase/kafka"
 	"posts/internal/usecase/service"
 )
 
 type KafkaHandler struct {
-	log  *service.LogService
-	post *service.PostService
+	logger       *slog.Logger
+	log          *service.LogService
+	post         *service.PostService
+	notification *service.NotificationService
+	webhook      *service.WebhookService
 }
 
-func (h *KafkaHandler) LogUpdate() func(message []byte) {
-	return func(message []byte) {
+// context returns the context a message is handled in; its logger tags
+// every record with the topic and an id for the message.
+func (h *KafkaHandler) context(ctx context.Context, topic string) (context.Context, *slog.Logger) {
+	l := h.logger.With("topic", topic, "request_id", logger.NewRequestID())
+	return logger.WithContext(ctx, l), l
+}
+
+func (h *KafkaHandler) LogUpdate() kafka.Handler {
+	return func(ctx context.Context, message []byte) error {
+		ctx, l := h.context(ctx, "log-update")
 
 		//unmarshal the message
 		var cer pb.LogUpdateRequest
 		if err := protojson.Unmarshal(message, &cer); err != nil {
-			log.Fatalf("Failed to unmarshal JSON to Protobuf message: %v", err)
-			return
+			l.Error("failed to unmarshal message", "error", err)
+			return err
 		}
 
-		res, err := h.log.Update(context.Background(), &cer)
-		if err != nil {
-			log.Fatal(err)
-			return
+		if _, err := h.log.Update(ctx, &cer); err != nil {
+			l.Error("failed to update log", "log_id", cer.Id, "error", err)
+			return err
 		}
-		log.Printf("Updated Log: %+v", res)
+		l.Info("updated log", "log_id", cer.Id)
+		return nil
 	}
 }
 
-func (h *KafkaHandler) LogDelete() func(message []byte) {
-	return func(message []byte) {
+func (h *KafkaHandler) LogDelete() kafka.Handler {
+	return func(ctx context.Context, message []byte) error {
+		ctx, l := h.context(ctx, "log-delete")
 
 		//unmarshal the message
 		var cer pb.GetId
 		if err := protojson.Unmarshal(message, &cer); err != nil {
-			log.Fatalf("Failed to unmarshal JSON to Protobuf message: %v", err)
-			return
+			l.Error("failed to unmarshal message", "error", err)
+			return err
+		}
+
+		if _, err := h.log.Delete(ctx, &cer); err != nil {
+			l.Error("failed to delete log", "log_id", cer.Id, "error", err)
+			return err
+		}
+		l.Info("deleted log", "log_id", cer.Id)
+		return nil
+	}
+}
+
+// LogIngest stores log batches shipped by the services' logging sinks. Its
+// own records are not shipped, so that a failing insert does not produce
+// more batches.
+func (h *KafkaHandler) LogIngest() kafka.Handler {
+	return func(ctx context.Context, message []byte) error {
+		ctx, l := h.context(ctx, logger.TopicLogIngest)
+		l = logger.NotShipped(l)
+
+		var batch pb.LogBatch
+		if err := protojson.Unmarshal(message, &batch); err != nil {
+			l.Error("failed to unmarshal message", "error", err)
+			return err
 		}
 
-		res, err := h.log.Delete(context.Background(), &cer)
+		n, err := h.log.Ingest(logger.WithContext(ctx, l), &batch)
 		if err != nil {
-			log.Fatal(err)
-			return
+			l.Error("failed to store log batch", "records", len(batch.Record), "error", err)
+			return err
 		}
-		log.Printf("Deleted Log: %+v", res)
+		logger.Trace(ctx, l, "stored log batch", "records", n)
+		return nil
 	}
 }
 
-func (h *KafkaHandler) PostUpdate() func(message []byte) {
-	return func(message []byte) {
+func (h *KafkaHandler) PostUpdate() kafka.Handler {
+	return func(ctx context.Context, message []byte) error {
+		ctx, l := h.context(ctx, "post-update")
 
 		//unmarshal the message
 		var cer pb.PostUpdateRequest
 		if err := protojson.Unmarshal(message, &cer); err != nil {
-			log.Fatalf("Failed to unmarshal JSON to Protobuf message: %v", err)
-			return
+			l.Error("failed to unmarshal message", "error", err)
+			return err
 		}
 
-		res, err := h.post.Update(context.Background(), &cer)
-		if err != nil {
-			log.Fatal(err)
-			return
+		if _, err := h.post.Update(ctx, &cer); err != nil {
+			l.Error("failed to update post", "post_id", cer.Id, "error", err)
+			return err
 		}
-		log.Printf("Updated Post: %+v", res)
+		l.Info("updated post", "post_id", cer.Id)
+		return nil
 	}
 }
 
-func (h *KafkaHandler) PostDelete() func(message []byte) {
-	return func(message []byte) {
+func (h *KafkaHandler) PostDelete() kafka.Handler {
+	return func(ctx context.Context, message []byte) error {
+		ctx, l := h.context(ctx, "post-delete")
 
 		//unmarshal the message
 		var cer pb.GetById
 		if err := protojson.Unmarshal(message, &cer); err != nil {
-			log.Fatalf("Failed to unmarshal JSON to Protobuf message: %v", err)
-			return
+			l.Error("failed to unmarshal message", "error", err)
+			return err
 		}
 
-		res, err := h.post.Delete(context.Background(), &cer)
-		if err != nil {
-			log.Fatal(err)
-			return
+		if _, err := h.post.Delete(ctx, &cer); err != nil {
+			l.Error("failed to delete post", "post_id", cer.Id, "error", err)
+			return err
+		}
+		l.Info("deleted post", "post_id", cer.Id)
+		return nil
+	}
+}
+
+func (h *KafkaHandler) UserFollowed() kafka.Handler {
+	return func(ctx context.Context, message []byte) error {
+		ctx, l := h.context(ctx, service.TopicUserFollowed)
+
+		var event pb.UserFollowed
+		if err := protojson.Unmarshal(message, &event); err != nil {
+			l.Error("failed to unmarshal message", "error", err)
+			return err
+		}
+
+		if err := h.notification.OnUserFollowed(ctx, &event); err != nil {
+			l.Error("failed to notify about a follower", "follower_id", event.FollowerId, "user_id", event.FolloweeId, "error", err)
+			return err
+		}
+		return nil
+	}
+}
+
+func (h *KafkaHandler) CommentCreated() kafka.Handler {
+	return func(ctx context.Context, message []byte) error {
+		ctx, l := h.context(ctx, service.TopicCommentCreated)
+
+		var comment pb.CommentGet
+		if err := protojson.Unmarshal(message, &comment); err != nil {
+			l.Error("failed to unmarshal message", "error", err)
+			return err
+		}
+
+		if err := h.notification.OnCommentCreated(ctx, &comment); err != nil {
+			l.Error("failed to notify about a comment", "comment_id", comment.Id, "error", err)
+			return err
+		}
+		return nil
+	}
+}
+
+func (h *KafkaHandler) PasswordChanged() kafka.Handler {
+	return func(ctx context.Context, message []byte) error {
+		ctx, l := h.context(ctx, service.TopicPasswordChanged)
+
+		var event pb.PasswordChanged
+		if err := protojson.Unmarshal(message, &event); err != nil {
+			l.Error("failed to unmarshal message", "error", err)
+			return err
+		}
+
+		if err := h.notification.OnPasswordChanged(ctx, &event); err != nil {
+			l.Error("failed to notify about a password change", "error", err)
+			return err
+		}
+		return nil
+	}
+}
+
+// Webhook queues the message for the webhooks subscribed to event.
+func (h *KafkaHandler) Webhook(event string) kafka.Handler {
+	return func(ctx context.Context, message []byte) error {
+		ctx, l := h.context(ctx, "webhooks")
+
+		if err := h.webhook.Dispatch(ctx, event, message); err != nil {
+			l.Error("failed to queue webhooks", "event", event, "error", err)
+			return err
 		}
-		log.Printf("Deleted Post: %+v", res)
+		return nil
 	}
 }
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"posts/internal/pkg/config"
+	"posts/internal/pkg/logger"
+	"posts/internal/pkg/migrate"
+	"posts/internal/pkg/postgres"
+	"posts/migrations"
+)
+
+// Migrate runs the `migrate up|down|status` subcommand.
+func Migrate(cf *config.Config, args []string) {
+	l := newLogger(cf, nil).Logger
+	if len(args) != 1 {
+		logger.Fatal(l, "usage: post-service migrate up|down|status")
+	}
+
+	pgm, err := postgres.NewPostgresStorage(cf)
+	if err != nil {
+		logger.Fatal(l, "failed to connect to database", "error", err)
+	}
+
+	// logger.Fatal exits without running deferred calls, so the
+	// connection is closed before reporting a failure
+	err = migrateCommand(context.Background(), l, pgm.DB, args[0])
+	pgm.Close()
+	if err != nil {
+		logger.Fatal(l, "migrate "+args[0]+" failed", "error", err)
+	}
+}
+
+func migrateCommand(ctx context.Context, l *slog.Logger, db *sql.DB, command string) error {
+	m, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return fmt.Errorf("loading migrations: %w", err)
+	}
+
+	switch command {
+	case "up":
+		applied, err := m.Up(ctx)
+		for _, migration := range applied {
+			l.Info("applied migration", "version", migration.Version, "name", migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			l.Info("no pending migrations")
+		}
+	case "down":
+		reverted, err := m.Down(ctx)
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			l.Info("no migrations to revert")
+			return nil
+		}
+		l.Info("reverted migration", "version", reverted.Version, "name", reverted.Name)
+	case "status":
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("version: %d (dirty: %t)\n", status.Version, status.Dirty)
+		for _, migration := range status.Applied {
+			fmt.Printf("  applied  %d_%s\n", migration.Version, migration.Name)
+		}
+		for _, migration := range status.Pending {
+			fmt.Printf("  pending  %d_%s\n", migration.Version, migration.Name)
+		}
+	default:
+		return fmt.Errorf("unknown migrate command %q, expected up, down or status", command)
+	}
+	return nil
+}
+
+func migrateUp(ctx context.Context, db *sql.DB) error {
+	m, err := migrate.New(db, migrations.FS)
+	if err != nil {
+		return err
+	}
+
+	applied, err := m.Up(ctx)
+	for _, migration := range applied {
+		logger.FromContext(ctx).Info("applied migration", "version", migration.Version, "name", migration.Name)
+	}
+	return err
+}
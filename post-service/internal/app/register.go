@@ -2,13 +2,16 @@ package app
 
 import (
 	"errors"
+	"posts/internal/entity"
 	"posts/internal/pkg/config"
+	"posts/internal/pkg/logger"
 	"posts/internal/usecase/kafka"
+	"posts/internal/usecase/service"
 )
 
-func Registries(k_handler *KafkaHandler, cfg *config.Config) error {
+func Registries(k_handler *KafkaHandler, cfg *config.Config, deadLetters kafka.KafkaProducer) error {
 	brokers := []string{cfg.KafkaUrl}
-	kcm := kafka.NewKafkaConsumerManager()
+	kcm := kafka.NewKafkaConsumerManager(deadLetters)
 
 	if err := kcm.RegisterConsumer(brokers, "log-update", "log-u", k_handler.LogUpdate()); err != nil {
 		if err == kafka.ErrConsumerAlreadyExists {
@@ -26,6 +29,14 @@ func Registries(k_handler *KafkaHandler, cfg *config.Config) error {
 		}
 	}
 
+	if err := kcm.RegisterConsumer(brokers, logger.TopicLogIngest, "log-ingest", k_handler.LogIngest()); err != nil {
+		if err == kafka.ErrConsumerAlreadyExists {
+			return errors.New("consumer for topic '" + logger.TopicLogIngest + "' already exists")
+		} else {
+			return errors.New("error registering consumer:" + err.Error())
+		}
+	}
+
 	if err := kcm.RegisterConsumer(brokers, "post-update", "post-u", k_handler.PostUpdate()); err != nil {
 		if err == kafka.ErrConsumerAlreadyExists {
 			return errors.New("consumer for topic 'post-update' already exists")
@@ -42,5 +53,36 @@ func Registries(k_handler *KafkaHandler, cfg *config.Config) error {
 		}
 	}
 
+	// domain events feed notifications and webhooks; each gets a consumer
+	// group of its own, so one failing doesn't hold up or repeat the other
+	events := []struct {
+		topic    string
+		handlers map[string]kafka.Handler
+	}{
+		{service.TopicPostPublished, map[string]kafka.Handler{
+			"webhooks": k_handler.Webhook(entity.EventPostPublished),
+		}},
+		{service.TopicUserFollowed, map[string]kafka.Handler{
+			"notifications": k_handler.UserFollowed(),
+			"webhooks":      k_handler.Webhook(entity.EventUserFollowed),
+		}},
+		{service.TopicCommentCreated, map[string]kafka.Handler{
+			"notifications": k_handler.CommentCreated(),
+			"webhooks":      k_handler.Webhook(entity.EventCommentCreated),
+		}},
+		{service.TopicPasswordChanged, map[string]kafka.Handler{
+			"notifications": k_handler.PasswordChanged(),
+		}},
+	}
+	for _, e := range events {
+		if err := kcm.RegisterGroups(brokers, e.topic, e.handlers); err != nil {
+			if err == kafka.ErrConsumerAlreadyExists {
+				return errors.New("consumer for topic '" + e.topic + "' already exists")
+			} else {
+				return errors.New("error registering consumer:" + err.Error())
+			}
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"time"
+
+	"posts/internal/pkg/logger"
+	"posts/internal/usecase/service"
+)
+
+// runScheduler publishes due scheduled posts every interval until ctx is
+// done. A full batch is followed immediately by another run.
+func runScheduler(ctx context.Context, posts *service.PostService, interval time.Duration) {
+	if interval <= 0 {
+		logger.FromContext(ctx).Info("post scheduler disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := posts.PublishDue(ctx)
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to publish scheduled posts", "error", err)
+				break
+			}
+			if n > 0 {
+				logger.FromContext(ctx).Info("published scheduled posts", "count", n)
+			}
+			if n < service.PublishBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runAttachmentGC removes blobs of deleted attachments and unreferenced
+// blobs older than grace every interval until ctx is done.
+func runAttachmentGC(ctx context.Context, attachments *service.AttachmentService, interval, grace time.Duration) {
+	if interval <= 0 {
+		logger.FromContext(ctx).Info("attachment garbage collector disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		n, err := attachments.CollectGarbage(ctx, grace)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to collect attachment garbage", "error", err)
+		}
+		if n > 0 {
+			logger.FromContext(ctx).Info("removed attachment blobs", "count", n)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runWebhookDeliveries sends due webhook deliveries every interval until
+// ctx is done. A full batch is followed immediately by another run.
+func runWebhookDeliveries(ctx context.Context, webhooks *service.WebhookService, interval time.Duration) {
+	if interval <= 0 {
+		logger.FromContext(ctx).Info("webhook delivery disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			n, err := webhooks.DeliverDue(ctx)
+			if err != nil {
+				logger.FromContext(ctx).Error("failed to deliver webhooks", "error", err)
+				break
+			}
+			if n < service.WebhookBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// runLogMaintenance creates upcoming partitions of the logs table and
+// archives and drops expired ones every interval until ctx is done.
+func runLogMaintenance(ctx context.Context, retention *service.LogRetention, interval time.Duration) {
+	if interval <= 0 {
+		logger.FromContext(ctx).Info("log maintenance disabled")
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := retention.Enforce(ctx); err != nil {
+			logger.FromContext(ctx).Error("failed to maintain log partitions", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,18 @@
+package entity
+
+import (
+	"github.com/uptrace/bun"
+)
+
+type Attachment struct {
+	bun.BaseModel `bun:"table:attachments,alias:a"`
+
+	BasicEntity
+	PostID      *int64  `json:"post_id"      bun:"post_id"`
+	UserID      *int64  `json:"user_id"      bun:"user_id"`
+	StorageKey  *string `json:"storage_key"  bun:"storage_key"`
+	FileName    *string `json:"file_name"    bun:"file_name"`
+	ContentType *string `json:"content_type" bun:"content_type"`
+	Size        *int64  `json:"size"         bun:"size"`
+	Checksum    *string `json:"checksum"     bun:"checksum"`
+}
@@ -3,8 +3,8 @@ package entity
 import "time"
 
 type BasicEntity struct {
-	ID        int64      `json:"id" bun:"id"`
-	CreatedAt *time.Time `json:"created_at"          bun:"created_at"`
+	ID        int64      `json:"id"                  bun:"id,pk,autoincrement"`
+	CreatedAt *time.Time `json:"created_at"          bun:"created_at,nullzero"`
 	CreatedBy *int64     `json:"created_by"          bun:"created_by"`
 	UpdatedAt *time.Time `json:"updated_at"          bun:"updated_at"`
 	UpdatedBy *int64     `json:"updated_by"          bun:"updated_by"`
@@ -0,0 +1,20 @@
+package entity
+
+import (
+	"github.com/uptrace/bun"
+)
+
+type Comment struct {
+	bun.BaseModel `bun:"table:comments,alias:c"`
+
+	BasicEntity
+	PostID   *int64  `json:"post_id"    bun:"post_id"`
+	ParentID *int64  `json:"parent_id"  bun:"parent_id"`
+	UserID   *int64  `json:"user_id"    bun:"user_id"`
+	Content  *string `json:"content"    bun:"content"`
+
+	// RepliesCount is computed when listing and is not a column.
+	RepliesCount int64 `json:"replies_count" bun:"replies_count,scanonly"`
+
+	User *User `json:"user" bun:"rel:belongs-to,join:user_id=id"`
+}
@@ -0,0 +1,15 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Follow struct {
+	bun.BaseModel `bun:"table:follows,alias:f"`
+
+	FollowerID int64      `json:"follower_id" bun:"follower_id,pk"`
+	FolloweeID int64      `json:"followee_id" bun:"followee_id,pk"`
+	CreatedAt  *time.Time `json:"created_at"  bun:"created_at"`
+}
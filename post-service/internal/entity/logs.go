@@ -1,14 +1,60 @@
 package entity
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/uptrace/bun"
 )
 
 type Log struct {
-	bun.BaseModel `bun:"table:logs"`
+	bun.BaseModel `bun:"table:logs,alias:l"`
 
 	BasicEntity
 	Level       *string `json:"level" bun:"level"`
 	Message     *string `json:"message" bun:"message"`
 	ServiceName *string `json:"service_name" bun:"service_name"`
+	// Attributes are structured fields such as trace_id or path.
+	Attributes map[string]string `json:"attributes,omitempty" bun:"attributes,type:jsonb,nullzero"`
+}
+
+// LogCount is a row of log statistics; Bucket is NULL when logs are not
+// grouped by time.
+type LogCount struct {
+	Level       *string    `bun:"level"`
+	ServiceName *string    `bun:"service_name"`
+	Bucket      *time.Time `bun:"bucket"`
+	Count       int64      `bun:"count"`
+}
+
+// LogPartition is the partition of the logs table holding one month,
+// [From, To).
+type LogPartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// LogPartitionFor returns the partition that holds records created at t.
+func LogPartitionFor(t time.Time) LogPartition {
+	from := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return LogPartition{
+		Name: fmt.Sprintf("logs_y%04dm%02d", from.Year(), int(from.Month())),
+		From: from,
+		To:   from.AddDate(0, 1, 0),
+	}
+}
+
+// ParseLogPartition maps a partition name back to its month; names that do
+// not follow the monthly scheme, such as logs_default, are rejected.
+func ParseLogPartition(name string) (LogPartition, bool) {
+	var year, month int
+	if _, err := fmt.Sscanf(name, "logs_y%4dm%2d", &year, &month); err != nil || month < 1 || month > 12 {
+		return LogPartition{}, false
+	}
+	p := LogPartitionFor(time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC))
+	if p.Name != name {
+		return LogPartition{}, false
+	}
+	return p, true
 }
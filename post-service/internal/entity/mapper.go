@@ -0,0 +1,429 @@
+package entity
+
+import (
+	"time"
+
+	pb "posts/internal/pkg/genproto"
+)
+
+// This file is the single place where entities are converted to and from
+// their protobuf messages. Repositories work with entities only.
+
+func UserFromCreateRequest(req *pb.UserCreateRequest) *User {
+	return &User{
+		FirstName: optional(req.FirstName),
+		LastName:  optional(req.LastName),
+		Username:  optional(req.Username),
+		Email:     optional(req.Email),
+		Phone:     optional(req.PhoneNumber),
+		Gender:    optional(req.Gender),
+		Password:  optional(req.Password),
+		Role:      optional(req.Role),
+	}
+}
+
+func (u *User) ToCreateResponse() *pb.UserCreateResponse {
+	return &pb.UserCreateResponse{
+		Id:          u.ID,
+		FirstName:   value(u.FirstName),
+		LastName:    value(u.LastName),
+		Username:    value(u.Username),
+		Email:       value(u.Email),
+		PhoneNumber: value(u.Phone),
+		Gender:      value(u.Gender),
+		Password:    value(u.Password),
+		Role:        value(u.Role),
+	}
+}
+
+func (u *User) ToGetResponse() *pb.UserGetResponse {
+	return &pb.UserGetResponse{
+		Id:          u.ID,
+		FirstName:   value(u.FirstName),
+		LastName:    value(u.LastName),
+		Username:    value(u.Username),
+		Email:       value(u.Email),
+		PhoneNumber: value(u.Phone),
+		Gender:      value(u.Gender),
+		Role:        value(u.Role),
+		CreatedAt:   timestamp(u.CreatedAt),
+		CreatedBy:   value(u.CreatedBy),
+	}
+}
+
+func (u *User) ToGetList() *pb.UserGetList {
+	return &pb.UserGetList{
+		Id:          u.ID,
+		FirstName:   value(u.FirstName),
+		LastName:    value(u.LastName),
+		Username:    value(u.Username),
+		Email:       value(u.Email),
+		PhoneNumber: value(u.Phone),
+		Gender:      value(u.Gender),
+		Role:        value(u.Role),
+		CreatedAt:   timestamp(u.CreatedAt),
+	}
+}
+
+func (u *User) ToPostUser() *pb.User {
+	if u == nil {
+		return &pb.User{}
+	}
+	return &pb.User{
+		Id:          u.ID,
+		FirstName:   value(u.FirstName),
+		LastName:    value(u.LastName),
+		Username:    value(u.Username),
+		Email:       value(u.Email),
+		PhoneNumber: value(u.Phone),
+		Gender:      value(u.Gender),
+		Role:        value(u.Role),
+	}
+}
+
+func PostFromCreateRequest(req *pb.PostCreateRequest) *Post {
+	post := &Post{
+		UserID:    optional(req.UserId),
+		Title:     &req.Title,
+		Content:   &req.Content,
+		Language:  optional(req.Language),
+		Status:    optional(req.Status),
+		PublishAt: parseTimestamp(req.PublishAt),
+	}
+	if req.Status == PostPublished {
+		now := time.Now().UTC()
+		post.PublishedAt = &now
+	}
+	return post
+}
+
+func (p *Post) ToCreateResponse() *pb.PostCreateResponse {
+	return &pb.PostCreateResponse{
+		Id:        p.ID,
+		UserId:    value(p.UserID),
+		Title:     value(p.Title),
+		Content:   value(p.Content),
+		Tags:      p.Tags,
+		Status:    value(p.Status),
+		PublishAt: timestamp(p.PublishAt),
+		Slug:      value(p.Slug),
+	}
+}
+
+func (p *Post) ToGetResponse() *pb.PostGetResponse {
+	return &pb.PostGetResponse{
+		Id:             p.ID,
+		UserId:         value(p.UserID),
+		Title:          value(p.Title),
+		Content:        value(p.Content),
+		CreatedAt:      timestamp(p.CreatedAt),
+		CreatedBy:      value(p.CreatedBy),
+		CommentsCount:  p.CommentsCount,
+		ReactionsCount: p.ReactionsCount,
+		Reactions:      p.ReactionCounts,
+		Tags:           p.Tags,
+		Status:         value(p.Status),
+		PublishAt:      timestamp(p.PublishAt),
+		PublishedAt:    timestamp(p.PublishedAt),
+		Slug:           value(p.Slug),
+	}
+}
+
+func (p *Post) ToPostGet() *pb.PostGet {
+	return &pb.PostGet{
+		Id:             p.ID,
+		UserId:         value(p.UserID),
+		Title:          value(p.Title),
+		Content:        value(p.Content),
+		User:           p.User.ToPostUser(),
+		CreatedAt:      timestamp(p.CreatedAt),
+		CreatedBy:      value(p.CreatedBy),
+		CommentsCount:  p.CommentsCount,
+		ReactionsCount: p.ReactionsCount,
+		Reactions:      p.ReactionCounts,
+		Tags:           p.Tags,
+		Status:         value(p.Status),
+		PublishAt:      timestamp(p.PublishAt),
+		PublishedAt:    timestamp(p.PublishedAt),
+		Slug:           value(p.Slug),
+	}
+}
+
+func (p *Post) ToPublished() *pb.PostPublished {
+	return &pb.PostPublished{
+		Id:          p.ID,
+		UserId:      value(p.UserID),
+		Title:       value(p.Title),
+		PublishedAt: timestamp(p.PublishedAt),
+	}
+}
+
+func (p *Post) ToReactions() *pb.PostReactions {
+	return &pb.PostReactions{
+		PostId:    p.ID,
+		Total:     p.ReactionsCount,
+		Reactions: p.ReactionCounts,
+	}
+}
+
+func (r *PostRevision) ToProto() *pb.PostRevision {
+	return &pb.PostRevision{
+		PostId:    r.PostID,
+		Revision:  r.Revision,
+		Title:     r.Title,
+		Content:   r.Content,
+		CreatedAt: timestamp(r.CreatedAt),
+	}
+}
+
+func (t *Tag) ToProto() *pb.Tag {
+	return &pb.Tag{
+		Slug:       t.Slug,
+		Name:       t.Name,
+		PostsCount: t.PostsCount,
+	}
+}
+
+func (p *PostSearchResult) ToProto() *pb.PostSearchResult {
+	return &pb.PostSearchResult{
+		Id:               p.ID,
+		UserId:           value(p.UserID),
+		Title:            value(p.Title),
+		Content:          value(p.Content),
+		TitleHighlight:   p.TitleHighlight,
+		ContentHighlight: p.ContentHighlight,
+		Rank:             p.Rank,
+		CreatedAt:        timestamp(p.CreatedAt),
+	}
+}
+
+func CommentFromCreateRequest(req *pb.CommentCreateRequest) *Comment {
+	return &Comment{
+		PostID:   &req.PostId,
+		ParentID: optional(req.ParentId),
+		UserID:   optional(req.UserId),
+		Content:  &req.Content,
+	}
+}
+
+func (c *Comment) ToCommentGet() *pb.CommentGet {
+	return &pb.CommentGet{
+		Id:           c.ID,
+		PostId:       value(c.PostID),
+		ParentId:     value(c.ParentID),
+		UserId:       value(c.UserID),
+		Content:      value(c.Content),
+		User:         c.User.ToPostUser(),
+		RepliesCount: c.RepliesCount,
+		CreatedAt:    timestamp(c.CreatedAt),
+		UpdatedAt:    timestamp(c.UpdatedAt),
+	}
+}
+
+func AttachmentFromProto(a *pb.Attachment, storageKey string) *Attachment {
+	return &Attachment{
+		PostID:      &a.PostId,
+		UserID:      optional(a.UserId),
+		StorageKey:  &storageKey,
+		FileName:    &a.FileName,
+		ContentType: &a.ContentType,
+		Size:        &a.Size,
+		Checksum:    &a.Checksum,
+	}
+}
+
+func (a *Attachment) ToProto() *pb.Attachment {
+	return &pb.Attachment{
+		Id:          a.ID,
+		PostId:      value(a.PostID),
+		UserId:      value(a.UserID),
+		FileName:    value(a.FileName),
+		ContentType: value(a.ContentType),
+		Size:        value(a.Size),
+		Checksum:    value(a.Checksum),
+		CreatedAt:   timestamp(a.CreatedAt),
+	}
+}
+
+func NotificationFromProto(n *pb.Notification) *Notification {
+	return &Notification{
+		UserID:    n.UserId,
+		Kind:      n.Kind,
+		ActorID:   optional(n.ActorId),
+		PostID:    optional(n.PostId),
+		CommentID: optional(n.CommentId),
+		Title:     n.Title,
+		Body:      n.Body,
+	}
+}
+
+func (n *Notification) ToProto() *pb.Notification {
+	return &pb.Notification{
+		Id:        n.ID,
+		UserId:    n.UserID,
+		Kind:      n.Kind,
+		ActorId:   value(n.ActorID),
+		PostId:    value(n.PostID),
+		CommentId: value(n.CommentID),
+		Title:     n.Title,
+		Body:      n.Body,
+		ReadAt:    timestamp(n.ReadAt),
+		CreatedAt: timestamp(n.CreatedAt),
+	}
+}
+
+func (p *NotificationPreference) ToProto() *pb.NotificationPreference {
+	return &pb.NotificationPreference{
+		Kind:    p.Kind,
+		Channel: p.Channel,
+		Enabled: p.Enabled,
+	}
+}
+
+func WebhookFromCreateRequest(req *pb.WebhookCreateRequest) *Webhook {
+	return &Webhook{
+		URL:       req.Url,
+		Events:    req.Events,
+		Secret:    req.Secret,
+		Active:    true,
+		CreatedBy: optional(req.CreatedBy),
+	}
+}
+
+// ToProto leaves the secret out; it is only shown when the webhook is
+// created.
+func (w *Webhook) ToProto() *pb.Webhook {
+	return &pb.Webhook{
+		Id:           w.ID,
+		Url:          w.URL,
+		Events:       w.Events,
+		Active:       w.Active,
+		FailureCount: w.FailureCount,
+		DisabledAt:   timestamp(w.DisabledAt),
+		CreatedBy:    value(w.CreatedBy),
+		CreatedAt:    timestamp(w.CreatedAt),
+	}
+}
+
+func (d *WebhookDelivery) ToProto() *pb.WebhookDelivery {
+	return &pb.WebhookDelivery{
+		Id:            d.ID,
+		WebhookId:     d.WebhookID,
+		Event:         d.Event,
+		Payload:       d.Payload,
+		Status:        d.Status,
+		Attempts:      d.Attempts,
+		NextAttemptAt: timestamp(d.NextAttemptAt),
+		ResponseCode:  value(d.ResponseCode),
+		Error:         value(d.Error),
+		CreatedAt:     timestamp(d.CreatedAt),
+		DeliveredAt:   timestamp(d.DeliveredAt),
+		RedeliveryOf:  value(d.RedeliveryOf),
+	}
+}
+
+func LogFromCreateRequest(req *pb.LogCreateRequest) *Log {
+	return &Log{
+		Level:       optional(req.Level),
+		Message:     &req.Message,
+		ServiceName: optional(req.ServiceName),
+		Attributes:  req.Attributes,
+	}
+}
+
+// Shipped records carry the sender's clock. Times further than this from
+// the insertion time are not trusted: a record dated months ahead would
+// land in logs_default and keep that month's partition from being
+// created.
+const (
+	logRecordMaxSkew = 5 * time.Minute
+	logRecordMaxAge  = 24 * time.Hour
+)
+
+// LogFromRecord maps a shipped log record; records without a valid time,
+// or with one outside [now-logRecordMaxAge, now+logRecordMaxSkew], get the
+// insertion time.
+func LogFromRecord(r *pb.LogRecord) *Log {
+	created := parseTimestamp(r.Time)
+	if created != nil {
+		now := time.Now()
+		if created.After(now.Add(logRecordMaxSkew)) || created.Before(now.Add(-logRecordMaxAge)) {
+			created = nil
+		}
+	}
+
+	return &Log{
+		BasicEntity: BasicEntity{CreatedAt: created},
+		Level:       optional(r.Level),
+		Message:     &r.Message,
+		ServiceName: optional(r.ServiceName),
+		Attributes:  r.Attributes,
+	}
+}
+
+func (l *Log) ToCreateResponse() *pb.LogCreateResponse {
+	return &pb.LogCreateResponse{
+		Id:          l.ID,
+		Level:       value(l.Level),
+		Message:     value(l.Message),
+		ServiceName: value(l.ServiceName),
+		Attributes:  l.Attributes,
+	}
+}
+
+func (l *Log) ToGetResponse() *pb.LogGetResponse {
+	return &pb.LogGetResponse{
+		Id:          l.ID,
+		Level:       value(l.Level),
+		Message:     value(l.Message),
+		ServiceName: value(l.ServiceName),
+		CreatedAt:   timestamp(l.CreatedAt),
+		CreatedBy:   value(l.CreatedBy),
+		Attributes:  l.Attributes,
+	}
+}
+
+func (c *LogCount) ToProto() *pb.LogStatsRow {
+	return &pb.LogStatsRow{
+		Level:       value(c.Level),
+		ServiceName: value(c.ServiceName),
+		Bucket:      timestamp(c.Bucket),
+		Count:       c.Count,
+	}
+}
+
+// optional maps a proto zero value to a NULL column.
+func optional[T comparable](v T) *T {
+	var zero T
+	if v == zero {
+		return nil
+	}
+	return &v
+}
+
+// value maps a NULL column to the proto zero value.
+func value[T any](v *T) T {
+	if v == nil {
+		var zero T
+		return zero
+	}
+	return *v
+}
+
+// parseTimestamp maps an RFC3339 string to a time; callers validate the
+// format beforehand, so malformed input maps to NULL.
+func parseTimestamp(s string) *time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	t = t.UTC()
+	return &t
+}
+
+func timestamp(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Notification struct {
+	bun.BaseModel `bun:"table:notifications,alias:n"`
+
+	ID        int64      `json:"id"         bun:"id,pk,autoincrement"`
+	UserID    int64      `json:"user_id"    bun:"user_id"`
+	Kind      string     `json:"kind"       bun:"kind"`
+	ActorID   *int64     `json:"actor_id"   bun:"actor_id"`
+	PostID    *int64     `json:"post_id"    bun:"post_id"`
+	CommentID *int64     `json:"comment_id" bun:"comment_id"`
+	Title     string     `json:"title"      bun:"title"`
+	Body      string     `json:"body"       bun:"body"`
+	ReadAt    *time.Time `json:"read_at"    bun:"read_at"`
+	CreatedAt *time.Time `json:"created_at" bun:"created_at,nullzero"`
+}
+
+type NotificationPreference struct {
+	bun.BaseModel `bun:"table:notification_preferences,alias:np"`
+
+	UserID  int64  `json:"user_id" bun:"user_id,pk"`
+	Kind    string `json:"kind"    bun:"kind,pk"`
+	Channel string `json:"channel" bun:"channel,pk"`
+	Enabled bool   `json:"enabled" bun:"enabled"`
+}
+
+type NotificationWebhook struct {
+	bun.BaseModel `bun:"table:notification_webhooks,alias:nw"`
+
+	UserID    int64      `json:"user_id"    bun:"user_id,pk"`
+	URL       string     `json:"url"        bun:"url"`
+	Secret    string     `json:"-"          bun:"secret"`
+	UpdatedAt *time.Time `json:"updated_at" bun:"updated_at,nullzero"`
+}
+
+// Notification kinds.
+const (
+	NotifyFollow          = "follow"
+	NotifyComment         = "comment"
+	NotifyReply           = "reply"
+	NotifyPasswordChanged = "password_changed"
+)
+
+// Notification channels; keep in sync with the CHECK constraint on
+// notification_preferences.channel.
+const (
+	ChannelInApp   = "in_app"
+	ChannelEmail   = "email"
+	ChannelWebhook = "webhook"
+)
@@ -1,14 +1,51 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/uptrace/bun"
 )
 
 type Post struct {
-	bun.BaseModel `bun:"table:posts"`
+	bun.BaseModel `bun:"table:posts,alias:p"`
 
 	BasicEntity
-	UserID  *int64  `json:"user_id"     bun:"user_id"`
-	Title   *string `json:"title"      bun:"title"`
-	Content *string `json:"content"   bun:"content"`
+	UserID   *int64  `json:"user_id"     bun:"user_id"`
+	Title    *string `json:"title"      bun:"title"`
+	Content  *string `json:"content"   bun:"content"`
+	Language *string `json:"language"  bun:"language,nullzero"`
+	Slug     *string `json:"slug"      bun:"slug,nullzero"`
+
+	Status      *string    `json:"status"       bun:"status,nullzero"`
+	PublishAt   *time.Time `json:"publish_at"   bun:"publish_at"`
+	PublishedAt *time.Time `json:"published_at" bun:"published_at"`
+
+	CommentsCount  int64            `json:"comments_count"  bun:"comments_count"`
+	ReactionsCount int64            `json:"reactions_count" bun:"reactions_count"`
+	ReactionCounts map[string]int64 `json:"reaction_counts" bun:"reaction_counts,type:jsonb"`
+
+	// Tags holds the slugs of the post's tags and is not a column.
+	Tags []string `json:"tags" bun:"tags,array,scanonly"`
+
+	User *User `json:"user" bun:"rel:belongs-to,join:user_id=id"`
+}
+
+// Post statuses. Only published posts are visible to everyone but their
+// author.
+const (
+	PostDraft     = "draft"
+	PostScheduled = "scheduled"
+	PostPublished = "published"
+	PostArchived  = "archived"
+)
+
+// PostSearchResult is a post matched by full-text search together with its
+// rank and highlighted fragments.
+type PostSearchResult struct {
+	Post `bun:",extend"`
+
+	TitleHighlight   string  `bun:"title_highlight"`
+	ContentHighlight string  `bun:"content_highlight"`
+	Rank             float32 `bun:"rank"`
+	TotalCount       int32   `bun:"total_count"`
 }
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Reaction struct {
+	bun.BaseModel `bun:"table:reactions,alias:r"`
+
+	PostID    int64      `json:"post_id"    bun:"post_id,pk"`
+	UserID    int64      `json:"user_id"    bun:"user_id,pk"`
+	Kind      string     `json:"kind"       bun:"kind,pk"`
+	CreatedAt *time.Time `json:"created_at" bun:"created_at,nullzero"`
+}
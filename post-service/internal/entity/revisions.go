@@ -0,0 +1,21 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+// PostRevision is a snapshot of a post's title and content taken before an
+// update.
+type PostRevision struct {
+	bun.BaseModel `bun:"table:post_revisions,alias:pr"`
+
+	ID        int64      `json:"id"         bun:"id,pk,autoincrement"`
+	PostID    int64      `json:"post_id"    bun:"post_id"`
+	Revision  int64      `json:"revision"   bun:"revision"`
+	Title     string     `json:"title"      bun:"title"`
+	Content   string     `json:"content"    bun:"content"`
+	CreatedAt *time.Time `json:"created_at" bun:"created_at,nullzero"`
+	CreatedBy *int64     `json:"created_by" bun:"created_by"`
+}
@@ -0,0 +1,27 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Tag struct {
+	bun.BaseModel `bun:"table:tags,alias:t"`
+
+	ID        int64      `json:"id"         bun:"id,pk,autoincrement"`
+	Slug      string     `json:"slug"       bun:"slug"`
+	Name      string     `json:"name"       bun:"name"`
+	CreatedAt *time.Time `json:"created_at" bun:"created_at,nullzero"`
+
+	// PostsCount and TotalCount are computed when listing.
+	PostsCount int64 `json:"posts_count" bun:"posts_count,scanonly"`
+	TotalCount int32 `json:"-"           bun:"total_count,scanonly"`
+}
+
+type PostTag struct {
+	bun.BaseModel `bun:"table:post_tags,alias:pt"`
+
+	PostID int64 `bun:"post_id,pk"`
+	TagID  int64 `bun:"tag_id,pk"`
+}
@@ -1,19 +1,22 @@
 package entity
 
 import (
+	"time"
+
 	"github.com/uptrace/bun"
 )
 
 type User struct {
-	bun.BaseModel `bun:"table:users"`
+	bun.BaseModel `bun:"table:users,alias:u"`
 
 	BasicEntity
-	FirstName *string `json:"first_name"     bun:"first_name"`
-	LastName  *string `json:"last_name"      bun:"last_name"`
-	Username  *string `json:"username"   bun:"username"`
-	Email     *string `json:"email"         bun:"email"`
-	Phone     *string `json:"phone"         bun:"phone"`
-	Gender    *string `json:"gender"     bun:"gender"`
-	Password  *string `json:"password"   bun:"password"`
-	Role      *string `json:"role"       bun:"role"`
+	FirstName *string    `json:"first_name"     bun:"firstname"`
+	LastName  *string    `json:"last_name"      bun:"lastname"`
+	Username  *string    `json:"username"   bun:"username"`
+	Email     *string    `json:"email"         bun:"email"`
+	Phone     *string    `json:"phone"         bun:"phone"`
+	Gender    *string    `json:"gender"     bun:"gender"`
+	Password  *string    `json:"password"   bun:"password"`
+	Role      *string    `json:"role"       bun:"role"`
+	ErasedAt  *time.Time `json:"erased_at"  bun:"erased_at"`
 }
@@ -0,0 +1,54 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/uptrace/bun"
+)
+
+type Webhook struct {
+	bun.BaseModel `bun:"table:webhooks,alias:w"`
+
+	ID           int64      `json:"id"            bun:"id,pk,autoincrement"`
+	URL          string     `json:"url"           bun:"url"`
+	Events       []string   `json:"events"        bun:"events,array"`
+	Secret       string     `json:"secret"        bun:"secret"`
+	Active       bool       `json:"active"        bun:"active"`
+	FailureCount int32      `json:"failure_count" bun:"failure_count"`
+	DisabledAt   *time.Time `json:"disabled_at"   bun:"disabled_at"`
+	CreatedBy    *int64     `json:"created_by"    bun:"created_by"`
+	CreatedAt    *time.Time `json:"created_at"    bun:"created_at,nullzero"`
+	UpdatedAt    *time.Time `json:"updated_at"    bun:"updated_at,nullzero"`
+}
+
+type WebhookDelivery struct {
+	bun.BaseModel `bun:"table:webhook_deliveries,alias:wd"`
+
+	ID            int64      `json:"id"              bun:"id,pk,autoincrement"`
+	WebhookID     int64      `json:"webhook_id"      bun:"webhook_id"`
+	Event         string     `json:"event"           bun:"event"`
+	Payload       string     `json:"payload"         bun:"payload,type:jsonb"`
+	Status        string     `json:"status"          bun:"status,nullzero"`
+	Attempts      int32      `json:"attempts"        bun:"attempts"`
+	NextAttemptAt *time.Time `json:"next_attempt_at" bun:"next_attempt_at,nullzero"`
+	ResponseCode  *int32     `json:"response_code"   bun:"response_code"`
+	Error         *string    `json:"error"           bun:"error"`
+	RedeliveryOf  *int64     `json:"redelivery_of"   bun:"redelivery_of"`
+	CreatedAt     *time.Time `json:"created_at"      bun:"created_at,nullzero"`
+	DeliveredAt   *time.Time `json:"delivered_at"    bun:"delivered_at"`
+}
+
+// Webhook event types.
+const (
+	EventPostPublished  = "post.published"
+	EventUserFollowed   = "user.followed"
+	EventCommentCreated = "comment.created"
+)
+
+// Webhook delivery statuses; keep in sync with the CHECK constraint on
+// webhook_deliveries.status.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
@@ -0,0 +1,32 @@
+// Package blob stores binary objects such as post attachments.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when a key has no blob.
+var ErrNotFound = errors.New("blob not found")
+
+// Info describes a stored blob.
+type Info struct {
+	Key      string
+	Size     int64
+	Modified time.Time
+}
+
+// Store is a flat key/value store for blobs. Keys use "/" as separator.
+type Store interface {
+	// Put stores size bytes read from r under key, replacing any blob
+	// already stored there.
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens the blob stored under key.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the blob stored under key; missing blobs are ignored.
+	Delete(ctx context.Context, key string) error
+	// List calls fn for every blob whose key starts with prefix.
+	List(ctx context.Context, prefix string, fn func(Info) error) error
+}
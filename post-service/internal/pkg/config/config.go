@@ -4,6 +4,7 @@ import (
 	"gopkg.in/yaml.v3"
 	"log"
 	"os"
+	"time"
 
 	"github.com/spf13/cast"
 )
@@ -13,6 +14,17 @@ type Config struct {
 		Timeout string
 	}
 	GRPCPort string
+	// AdminPort serves operational HTTP endpoints such as /metrics.
+	AdminPort string
+	// HealthCheckInterval is how often readiness checks run for the
+	// grpc.health.v1 service.
+	HealthCheckInterval time.Duration
+
+	// Tracing: spans go to an OTLP collector at TracingEndpoint, to
+	// stdout, or nowhere (otlp, stdout or none).
+	TracingExporter    string
+	TracingEndpoint    string
+	TracingSampleRatio float64
 
 	PostgresHost     string
 	PostgresPort     string
@@ -20,6 +32,73 @@ type Config struct {
 	PostgresPassword string
 	PostgresDatabase string
 	KafkaUrl         string
+	MigrateOnStart   bool
+
+	// Deletion policy (cascade, anonymize or block) applied to a user's
+	// posts and logs when the user is deleted.
+	UserDeletePosts string
+	UserDeleteLogs  string
+
+	// How often scheduled posts are checked for publication.
+	PublishInterval time.Duration
+
+	// Attachment storage: "local" keeps blobs under BlobDir, "s3" uses an
+	// S3-compatible bucket.
+	BlobStore   string
+	BlobDir     string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	S3UseSSL    bool
+
+	// AttachmentURLSecret signs download URLs; AttachmentMaxSize is the
+	// largest accepted upload in bytes.
+	AttachmentURLSecret string
+	AttachmentMaxSize   int64
+	// How often orphaned blobs are collected, and how old an unreferenced
+	// blob must be before it is removed.
+	AttachmentGCInterval time.Duration
+	AttachmentGCGrace    time.Duration
+
+	// SMTP server for email notifications; without a host, email is only
+	// logged.
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+
+	// How often due webhook deliveries are sent, how often a delivery is
+	// tried, and after how many failed attempts in a row a webhook is
+	// disabled.
+	WebhookInterval     time.Duration
+	WebhookMaxAttempts  int
+	WebhookDisableAfter int
+
+	// Logging: the least severe level written (trace, debug, info, warn,
+	// error) and an optional file, rotated by size, that receives a copy
+	// of the stdout output.
+	LogLevel      string
+	LogFile       string
+	LogMaxSizeMB  int
+	LogMaxBackups int
+	LogMaxAgeDays int
+	// Log shipping: records at LogShipLevel and above
+	// are published to Kafka in batches and stored in the logs table.
+	LogShip              bool
+	LogShipLevel         string
+	LogShipBatchSize     int
+	LogShipFlushInterval time.Duration
+
+	// Log retention: how often partitions of the logs table are
+	// maintained, how long logs are kept (0 keeps them forever), and
+	// whether expired partitions are archived to the blob store before
+	// they are dropped.
+	LogMaintenanceInterval time.Duration
+	LogRetention           time.Duration
+	LogArchive             bool
 }
 
 func New() *Config {
@@ -47,6 +126,59 @@ func New() *Config {
 	config.PostgresDatabase = cast.ToString(getEnv("POSTGRES_DATABASE", "posts"))
 	config.KafkaUrl = cast.ToString(getEnv("KAFKA_URL", "kafka_posts:9092"))
 	config.GRPCPort = cast.ToString(getEnv("GRPC_PORT", ":7001"))
+	config.AdminPort = cast.ToString(getEnv("ADMIN_PORT", ":7002"))
+	config.HealthCheckInterval = cast.ToDuration(getEnv("HEALTH_CHECK_INTERVAL", "10s"))
+	config.TracingExporter = cast.ToString(getEnv("TRACING_EXPORTER", "none"))
+	config.TracingEndpoint = cast.ToString(getEnv("OTEL_EXPORTER_OTLP_ENDPOINT", "localhost:4317"))
+	config.TracingSampleRatio = cast.ToFloat64(getEnv("TRACING_SAMPLE_RATIO", "1"))
+	config.MigrateOnStart = cast.ToBool(getEnv("MIGRATE_ON_START", "true"))
+	config.UserDeletePosts = cast.ToString(getEnv("USER_DELETE_POSTS", "cascade"))
+	config.UserDeleteLogs = cast.ToString(getEnv("USER_DELETE_LOGS", "anonymize"))
+	config.PublishInterval = cast.ToDuration(getEnv("PUBLISH_INTERVAL", "30s"))
+
+	// Attachment configuration
+	config.BlobStore = cast.ToString(getEnv("BLOB_STORE", "local"))
+	config.BlobDir = cast.ToString(getEnv("BLOB_DIR", "./data/blobs"))
+	config.S3Endpoint = cast.ToString(getEnv("S3_ENDPOINT", "localhost:9000"))
+	config.S3Region = cast.ToString(getEnv("S3_REGION", ""))
+	config.S3Bucket = cast.ToString(getEnv("S3_BUCKET", "attachments"))
+	config.S3AccessKey = cast.ToString(getEnv("S3_ACCESS_KEY", ""))
+	config.S3SecretKey = cast.ToString(getEnv("S3_SECRET_KEY", ""))
+	config.S3UseSSL = cast.ToBool(getEnv("S3_USE_SSL", "false"))
+	config.AttachmentURLSecret = cast.ToString(getEnv("ATTACHMENT_URL_SECRET", ""))
+	if config.AttachmentURLSecret == "" || config.AttachmentURLSecret == "change-me" {
+		// a guessable secret lets anyone sign download URLs
+		log.Fatal("ATTACHMENT_URL_SECRET must be set to a random secret")
+	}
+	config.AttachmentMaxSize = cast.ToInt64(getEnv("ATTACHMENT_MAX_SIZE", "10485760"))
+	config.AttachmentGCInterval = cast.ToDuration(getEnv("ATTACHMENT_GC_INTERVAL", "1h"))
+	config.AttachmentGCGrace = cast.ToDuration(getEnv("ATTACHMENT_GC_GRACE", "24h"))
+
+	// Notification configuration
+	config.SMTPHost = cast.ToString(getEnv("SMTP_HOST", ""))
+	config.SMTPPort = cast.ToString(getEnv("SMTP_PORT", "587"))
+	config.SMTPUsername = cast.ToString(getEnv("SMTP_USERNAME", ""))
+	config.SMTPPassword = cast.ToString(getEnv("SMTP_PASSWORD", ""))
+	config.SMTPFrom = cast.ToString(getEnv("SMTP_FROM", "no-reply@posts.local"))
+
+	// Webhook configuration
+	config.WebhookInterval = cast.ToDuration(getEnv("WEBHOOK_INTERVAL", "5s"))
+	config.WebhookMaxAttempts = cast.ToInt(getEnv("WEBHOOK_MAX_ATTEMPTS", "8"))
+	config.WebhookDisableAfter = cast.ToInt(getEnv("WEBHOOK_DISABLE_AFTER", "20"))
+
+	// Logging configuration
+	config.LogLevel = cast.ToString(getEnv("LOG_LEVEL", "info"))
+	config.LogFile = cast.ToString(getEnv("LOG_FILE", ""))
+	config.LogMaxSizeMB = cast.ToInt(getEnv("LOG_MAX_SIZE_MB", "100"))
+	config.LogMaxBackups = cast.ToInt(getEnv("LOG_MAX_BACKUPS", "5"))
+	config.LogMaxAgeDays = cast.ToInt(getEnv("LOG_MAX_AGE_DAYS", "30"))
+	config.LogShip = cast.ToBool(getEnv("LOG_SHIP", "true"))
+	config.LogShipLevel = cast.ToString(getEnv("LOG_SHIP_LEVEL", "warn"))
+	config.LogShipBatchSize = cast.ToInt(getEnv("LOG_SHIP_BATCH_SIZE", "100"))
+	config.LogShipFlushInterval = cast.ToDuration(getEnv("LOG_SHIP_FLUSH_INTERVAL", "2s"))
+	config.LogMaintenanceInterval = cast.ToDuration(getEnv("LOG_MAINTENANCE_INTERVAL", "1h"))
+	config.LogRetention = cast.ToDuration(getEnv("LOG_RETENTION", "2160h"))
+	config.LogArchive = cast.ToBool(getEnv("LOG_ARCHIVE", "true"))
 
 	return &config
 }
@@ -49,13 +49,26 @@ func IsValidUUID(id string) error {
 	return nil
 }
 
+// CheckRowsAffected returns an "<object> not found" error, which matches
+// sql.ErrNoRows, when res changed no rows.
 func CheckRowsAffected(res sql.Result, object string) error {
 	rowsAffected, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
 	if rowsAffected == 0 {
-		return errors.New(object + " not found")
+		return rowsNotFound(object)
 	}
 	return nil
 }
+
+// rowsNotFound names the object a statement found no rows of.
+type rowsNotFound string
+
+func (e rowsNotFound) Error() string {
+	return string(e) + " not found"
+}
+
+func (e rowsNotFound) Is(target error) bool {
+	return target == sql.ErrNoRows
+}
@@ -0,0 +1,43 @@
+// Package cursor encodes keyset pagination positions as opaque strings.
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalid is returned for cursors that were not produced by Encode.
+var ErrInvalid = errors.New("invalid cursor")
+
+// Position is the sort key of the last row of a page: a time and an id
+// that breaks ties between rows with the same time.
+type Position struct {
+	Time time.Time
+	ID   int64
+}
+
+// Encode returns p as a URL-safe string.
+func Encode(p Position) string {
+	raw := fmt.Sprintf("%d:%d", p.Time.UnixNano(), p.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode parses a cursor made by Encode. An empty cursor means the first
+// page and decodes to nil.
+func Decode(s string) (*Position, error) {
+	if s == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalid
+	}
+
+	var nanos, id int64
+	if _, err := fmt.Sscanf(string(raw), "%d:%d", &nanos, &id); err != nil {
+		return nil, ErrInvalid
+	}
+	return &Position{Time: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
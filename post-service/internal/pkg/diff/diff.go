@@ -0,0 +1,100 @@
+// Package diff computes line-based differences between texts.
+package diff
+
+import (
+	"errors"
+	"strings"
+)
+
+// MaxLines caps the lines either text may have once the lines both start
+// and end with are set aside. The diff table grows with the product of
+// both sides, so larger inputs are refused instead of exhausting memory.
+const MaxLines = 2000
+
+// ErrTooLarge is returned for texts that differ in more than MaxLines lines.
+var ErrTooLarge = errors.New("diff: texts differ in too many lines")
+
+// Lines returns a line diff that turns a into b. Every line of the result
+// is prefixed with "  " when it is in both texts, "- " when it is only in a
+// and "+ " when it is only in b. Equal texts give only unchanged lines.
+func Lines(a, b string) (string, error) {
+	x := split(a)
+	y := split(b)
+
+	var out strings.Builder
+
+	// common leading and trailing lines need no table
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix &&
+		x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+	tail := x[len(x)-suffix:]
+	for _, text := range x[:prefix] {
+		line(&out, "  ", text)
+	}
+	x, y = x[prefix:len(x)-suffix], y[prefix:len(y)-suffix]
+	if len(x) > MaxLines || len(y) > MaxLines {
+		return "", ErrTooLarge
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:]
+	// and y[j:]
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			line(&out, "  ", x[i])
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			line(&out, "- ", x[i])
+			i++
+		default:
+			line(&out, "+ ", y[j])
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		line(&out, "- ", x[i])
+	}
+	for ; j < len(y); j++ {
+		line(&out, "+ ", y[j])
+	}
+	for _, text := range tail {
+		line(&out, "  ", text)
+	}
+
+	return out.String(), nil
+}
+
+func split(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+func line(out *strings.Builder, prefix, text string) {
+	out.WriteString(prefix)
+	out.WriteString(text)
+	out.WriteByte('\n')
+}
@@ -24,6 +24,8 @@ const (
 	LogService_Update_FullMethodName    = "/protos.LogService/Update"
 	LogService_Delete_FullMethodName    = "/protos.LogService/Delete"
 	LogService_GetList_FullMethodName   = "/protos.LogService/GetList"
+	LogService_Tail_FullMethodName      = "/protos.LogService/Tail"
+	LogService_Stats_FullMethodName     = "/protos.LogService/Stats"
 )
 
 // LogServiceClient is the client API for LogService service.
@@ -35,6 +37,8 @@ type LogServiceClient interface {
 	Update(ctx context.Context, in *LogUpdateRequest, opts ...grpc.CallOption) (*LogVoid, error)
 	Delete(ctx context.Context, in *GetId, opts ...grpc.CallOption) (*LogVoid, error)
 	GetList(ctx context.Context, in *FilterLog, opts ...grpc.CallOption) (*LogGetAll, error)
+	Tail(ctx context.Context, in *LogTailRequest, opts ...grpc.CallOption) (LogService_TailClient, error)
+	Stats(ctx context.Context, in *LogStatsRequest, opts ...grpc.CallOption) (*LogStats, error)
 }
 
 type logServiceClient struct {
@@ -95,6 +99,49 @@ func (c *logServiceClient) GetList(ctx context.Context, in *FilterLog, opts ...g
 	return out, nil
 }
 
+func (c *logServiceClient) Tail(ctx context.Context, in *LogTailRequest, opts ...grpc.CallOption) (LogService_TailClient, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &LogService_ServiceDesc.Streams[0], LogService_Tail_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &logServiceTailClient{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type LogService_TailClient interface {
+	Recv() (*LogGetResponse, error)
+	grpc.ClientStream
+}
+
+type logServiceTailClient struct {
+	grpc.ClientStream
+}
+
+func (x *logServiceTailClient) Recv() (*LogGetResponse, error) {
+	m := new(LogGetResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *logServiceClient) Stats(ctx context.Context, in *LogStatsRequest, opts ...grpc.CallOption) (*LogStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(LogStats)
+	err := c.cc.Invoke(ctx, LogService_Stats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // LogServiceServer is the server API for LogService service.
 // All implementations must embed UnimplementedLogServiceServer
 // for forward compatibility
@@ -104,6 +151,8 @@ type LogServiceServer interface {
 	Update(context.Context, *LogUpdateRequest) (*LogVoid, error)
 	Delete(context.Context, *GetId) (*LogVoid, error)
 	GetList(context.Context, *FilterLog) (*LogGetAll, error)
+	Tail(*LogTailRequest, LogService_TailServer) error
+	Stats(context.Context, *LogStatsRequest) (*LogStats, error)
 	mustEmbedUnimplementedLogServiceServer()
 }
 
@@ -126,6 +175,12 @@ func (UnimplementedLogServiceServer) Delete(context.Context, *GetId) (*LogVoid,
 func (UnimplementedLogServiceServer) GetList(context.Context, *FilterLog) (*LogGetAll, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetList not implemented")
 }
+func (UnimplementedLogServiceServer) Tail(*LogTailRequest, LogService_TailServer) error {
+	return status.Errorf(codes.Unimplemented, "method Tail not implemented")
+}
+func (UnimplementedLogServiceServer) Stats(context.Context, *LogStatsRequest) (*LogStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
+}
 func (UnimplementedLogServiceServer) mustEmbedUnimplementedLogServiceServer() {}
 
 // UnsafeLogServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -229,6 +284,45 @@ func _LogService_GetList_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _LogService_Tail_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(LogTailRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(LogServiceServer).Tail(m, &logServiceTailServer{ServerStream: stream})
+}
+
+type LogService_TailServer interface {
+	Send(*LogGetResponse) error
+	grpc.ServerStream
+}
+
+type logServiceTailServer struct {
+	grpc.ServerStream
+}
+
+func (x *logServiceTailServer) Send(m *LogGetResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _LogService_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(LogServiceServer).Stats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: LogService_Stats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(LogServiceServer).Stats(ctx, req.(*LogStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // LogService_ServiceDesc is the grpc.ServiceDesc for LogService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -256,7 +350,17 @@ var LogService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetList",
 			Handler:    _LogService_GetList_Handler,
 		},
+		{
+			MethodName: "Stats",
+			Handler:    _LogService_Stats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Tail",
+			Handler:       _LogService_Tail_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "internal/pkg/scripts/submodule/logs.proto",
 }
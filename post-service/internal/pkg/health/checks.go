@@ -0,0 +1,46 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// DB checks that db answers a ping.
+func DB(db *sql.DB) Check {
+	return db.PingContext
+}
+
+// Kafka checks that at least one of brokers accepts a connection.
+func Kafka(brokers []string) Check {
+	return func(ctx context.Context) error {
+		var errs []error
+		for _, broker := range brokers {
+			conn, err := kafka.DialContext(ctx, "tcp", broker)
+			if err == nil {
+				return conn.Close()
+			}
+			errs = append(errs, err)
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// GRPC checks that the server behind client reports service as serving;
+// an empty service stands for the server as a whole.
+func GRPC(client healthpb.HealthClient, service string) Check {
+	return func(ctx context.Context) error {
+		resp, err := client.Check(ctx, &healthpb.HealthCheckRequest{Service: service})
+		if err != nil {
+			return err
+		}
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			return fmt.Errorf("status %s", resp.Status)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,106 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys the gateway uses to pass the request id and the
+// authenticated user along with its gRPC calls.
+const (
+	RequestIDHeader = "x-request-id"
+	UserIDHeader    = "x-user-id"
+)
+
+// UnaryServerInterceptor logs every call with its method, code and latency
+// and hands the handler a logger carrying the caller's request and user
+// ids; see FromContext.
+func UnaryServerInterceptor(l *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, callLogger := withCall(ctx, l, info.FullMethod)
+		start := time.Now()
+
+		resp, err := handler(ctx, req)
+		logCall(ctx, callLogger, start, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor is UnaryServerInterceptor for streaming calls.
+func StreamServerInterceptor(l *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, callLogger := withCall(ss.Context(), l, info.FullMethod)
+		start := time.Now()
+
+		err := handler(srv, &stream{ServerStream: ss, ctx: ctx})
+		logCall(ctx, callLogger, start, err)
+		return err
+	}
+}
+
+// NewRequestID returns a random id for requests that arrive without one.
+func NewRequestID() string {
+	var random [8]byte
+	rand.Read(random[:])
+	return hex.EncodeToString(random[:])
+}
+
+func withCall(ctx context.Context, l *slog.Logger, method string) (context.Context, *slog.Logger) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := firstValue(md, RequestIDHeader)
+	if requestID == "" {
+		requestID = NewRequestID()
+	}
+	args := []any{"request_id", requestID, "method", method}
+	if userID := firstValue(md, UserIDHeader); userID != "" {
+		args = append(args, "user_id", userID)
+	}
+
+	callLogger := l.With(args...)
+	return WithContext(ctx, callLogger), callLogger
+}
+
+func logCall(ctx context.Context, l *slog.Logger, start time.Time, err error) {
+	code := status.Code(err)
+
+	level := slog.LevelInfo
+	switch code {
+	case codes.OK:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		level = slog.LevelError
+	default:
+		level = slog.LevelWarn
+	}
+
+	args := []any{"code", code.String(), "latency_ms", float64(time.Since(start).Microseconds()) / 1000}
+	if err != nil {
+		args = append(args, "error", err)
+	}
+	l.Log(ctx, level, "grpc call", args...)
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// stream swaps in the context that carries the call's logger.
+type stream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *stream) Context() context.Context {
+	return s.ctx
+}
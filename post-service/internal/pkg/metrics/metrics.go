@@ -0,0 +1,87 @@
+// Package metrics defines the service's Prometheus metrics. Labels only
+// take bounded values: gRPC method names, status codes, topic names and
+// notification channels.
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Registry holds every metric of the service.
+var Registry = prometheus.NewRegistry()
+
+var (
+	GRPCHandled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "grpc_server_handled_total",
+		Help: "gRPC calls completed by the server, by method and status code.",
+	}, []string{"method", "code"})
+
+	GRPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "grpc_server_handling_seconds",
+		Help:    "Time the server took to complete gRPC calls, by method.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method"})
+
+	KafkaProduced = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_messages_produced_total",
+		Help: "Messages published to Kafka, by topic and result (ok or error).",
+	}, []string{"topic", "result"})
+
+	KafkaConsumed = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_messages_consumed_total",
+		Help: "Messages read from Kafka, by topic, consumer group and result (ok or error).",
+	}, []string{"topic", "group", "result"})
+
+	KafkaLag = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_lag",
+		Help: "Messages a consumer group is behind on a topic.",
+	}, []string{"topic", "group"})
+
+	KafkaDeadLettered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "kafka_messages_dead_lettered_total",
+		Help: "Messages that failed handling and were moved to a dead-letter topic, by topic and consumer group.",
+	}, []string{"topic", "group"})
+
+	NotificationsDelivered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "notifications_delivered_total",
+		Help: "Notification deliveries, by channel and result (ok or error).",
+	}, []string{"channel", "result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		GRPCHandled,
+		GRPCDuration,
+		KafkaProduced,
+		KafkaConsumed,
+		KafkaLag,
+		KafkaDeadLettered,
+		NotificationsDelivered,
+	)
+}
+
+// RegisterDB adds the connection pool statistics of db, labelled with
+// name.
+func RegisterDB(db *sql.DB, name string) {
+	Registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// Result labels the outcome of an operation.
+func Result(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+	"posts/internal/pkg/config"
+)
+
+const (
+	listenMinReconnect = 10 * time.Second
+	listenMaxReconnect = time.Minute
+	// listenPing checks an idle connection, which would otherwise drop
+	// unnoticed.
+	listenPing = 90 * time.Second
+)
+
+// NotificationSource delivers the notifications a Listener passes on;
+// *pq.Listener is one. A nil notification reports a reconnect.
+type NotificationSource interface {
+	NotificationChannel() <-chan *pq.Notification
+	Ping() error
+	Close() error
+}
+
+// Listener wakes its subscribers whenever a notification arrives on a
+// channel. Payloads are not passed on: subscribers are expected to read
+// what changed themselves, so a wake-up may stand for several
+// notifications. Subscribers are woken after a reconnect as well, since
+// notifications sent meanwhile are lost.
+type Listener struct {
+	source NotificationSource
+
+	mu          sync.Mutex
+	subscribers map[chan struct{}]struct{}
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewListener(cfg *config.Config, channel string) (*Listener, error) {
+	pl := pq.NewListener(dsn(cfg), listenMinReconnect, listenMaxReconnect, nil)
+	if err := pl.Listen(channel); err != nil {
+		pl.Close()
+		return nil, fmt.Errorf("listening on %s: %w", channel, err)
+	}
+	return NewListenerFrom(pl), nil
+}
+
+// NewListenerFrom passes on the notifications of source, which it closes
+// on Close.
+func NewListenerFrom(source NotificationSource) *Listener {
+	l := &Listener{
+		source:      source,
+		subscribers: make(map[chan struct{}]struct{}),
+		done:        make(chan struct{}),
+	}
+	l.wg.Add(1)
+	go l.run()
+	return l
+}
+
+// Subscribe returns a channel that receives a value after notifications
+// arrive, and a function that ends the subscription.
+func (l *Listener) Subscribe() (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	l.mu.Lock()
+	l.subscribers[ch] = struct{}{}
+	l.mu.Unlock()
+
+	return ch, func() {
+		l.mu.Lock()
+		delete(l.subscribers, ch)
+		l.mu.Unlock()
+	}
+}
+
+func (l *Listener) Close() error {
+	close(l.done)
+	l.wg.Wait()
+	return l.source.Close()
+}
+
+func (l *Listener) run() {
+	defer l.wg.Done()
+
+	ticker := time.NewTicker(listenPing)
+	defer ticker.Stop()
+	notifications := l.source.NotificationChannel()
+
+	for {
+		select {
+		case <-l.done:
+			return
+		case <-notifications:
+			// a nil notification reports a reconnect
+			l.wake()
+		case <-ticker.C:
+			l.source.Ping()
+		}
+	}
+}
+
+func (l *Listener) wake() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for ch := range l.subscribers {
+		select {
+		case ch <- struct{}{}:
+		default:
+		}
+	}
+}
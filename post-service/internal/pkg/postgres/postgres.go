@@ -3,7 +3,6 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
-	"log"
 
 	_ "github.com/lib/pq"
 	"posts/internal/pkg/config"
@@ -13,22 +12,22 @@ type PostgresStorage struct {
 	DB *sql.DB
 }
 
-func NewPostgresStorage(cfg *config.Config) (*PostgresStorage, error) {
-	// PostgreSQL DSN
-	dsn := fmt.Sprintf(
+// dsn returns the PostgreSQL connection string of the configuration.
+func dsn(cfg *config.Config) string {
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		cfg.PostgresHost, cfg.PostgresPort, cfg.PostgresUser, cfg.PostgresPassword, cfg.PostgresDatabase,
 	)
+}
 
-	db, err := sql.Open("postgres", dsn)
+func NewPostgresStorage(cfg *config.Config) (*PostgresStorage, error) {
+	db, err := sql.Open("postgres", dsn(cfg))
 	if err != nil {
-		log.Printf("Failed to open DB: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("opening database: %w", err)
 	}
 
 	if err := db.Ping(); err != nil {
-		log.Printf("Failed to ping DB: %v", err)
-		return nil, err
+		return nil, fmt.Errorf("pinging database: %w", err)
 	}
 
 	return &PostgresStorage{DB: db}, nil
@@ -0,0 +1,57 @@
+// Package slug turns free-form text into URL-safe identifiers.
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MaxLength bounds the length of a slug in runes.
+const MaxLength = 64
+
+// Make lowercases s and joins its runs of letters and digits with single
+// hyphens, so "  Go & Kafka " and "go-kafka" give the same slug.
+func Make(s string) string {
+	var b strings.Builder
+	n := 0
+	pendingHyphen := false
+
+	for _, r := range strings.ToLower(s) {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			pendingHyphen = n > 0
+			continue
+		}
+		if n >= MaxLength {
+			break
+		}
+		if pendingHyphen {
+			if n+1 >= MaxLength {
+				break
+			}
+			b.WriteByte('-')
+			n++
+			pendingHyphen = false
+		}
+		b.WriteRune(r)
+		n++
+	}
+
+	return b.String()
+}
+
+// Unique returns the distinct non-empty slugs of names in their original
+// order.
+func Unique(names []string) []string {
+	slugs := make([]string, 0, len(names))
+	seen := make(map[string]bool, len(names))
+
+	for _, name := range names {
+		s := Make(name)
+		if s == "" || seen[s] {
+			continue
+		}
+		seen[s] = true
+		slugs = append(slugs, s)
+	}
+	return slugs
+}
@@ -0,0 +1,79 @@
+package slug
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// latin spells out letters that do not decompose into a Latin base letter
+// and combining marks.
+var latin = map[rune]string{
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'д': "d", 'е': "e", 'ё': "yo",
+	'ж': "zh", 'з': "z", 'и': "i", 'й': "y", 'к': "k", 'л': "l", 'м': "m",
+	'н': "n", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'у': "u",
+	'ф': "f", 'х': "kh", 'ц': "ts", 'ч': "ch", 'ш': "sh", 'щ': "shch",
+	'ъ': "", 'ы': "y", 'ь': "", 'э': "e", 'ю': "yu", 'я': "ya",
+	'ў': "o", 'қ': "q", 'ғ': "g", 'ҳ': "h", 'і': "i", 'ї': "yi", 'є': "ye",
+	'ґ': "g",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i",
+	'θ': "th", 'ι': "i", 'κ': "k", 'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x",
+	'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t", 'υ': "y",
+	'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+	// Latin letters without a decomposition
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ł': "l", 'þ': "th",
+	'ð': "d", 'ı': "i",
+}
+
+// Transliterate rewrites s in Latin letters: accents are dropped and
+// Cyrillic and Greek letters are spelled out. Letters it cannot spell are
+// kept as they are.
+func Transliterate(s string) string {
+	var b strings.Builder
+
+	for _, r := range norm.NFD.String(strings.ToLower(s)) {
+		if unicode.Is(unicode.Mn, r) {
+			continue
+		}
+		if l, ok := latin[r]; ok {
+			b.WriteString(l)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return norm.NFC.String(b.String())
+}
+
+// ASCII is Make for URLs: the result only holds a-z, 0-9 and hyphens.
+// Apostrophes are dropped, so "don't" gives "dont"; letters that cannot be
+// transliterated are treated as separators.
+func ASCII(s string) string {
+	ascii := strings.Map(func(r rune) rune {
+		switch {
+		case r == '\'' || r == '‘' || r == '’' || r == 'ʻ' || r == 'ʼ':
+			return -1
+		case r > unicode.MaxASCII:
+			return ' '
+		}
+		return r
+	}, Transliterate(s))
+	return Make(ascii)
+}
+
+// WithSuffix returns the first of base, base-2, base-3, ... that is not in
+// taken.
+func WithSuffix(base string, taken map[string]bool) string {
+	if !taken[base] {
+		return base
+	}
+	for n := 2; ; n++ {
+		candidate := base + "-" + strconv.Itoa(n)
+		if !taken[candidate] {
+			return candidate
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// headers adapts Kafka message headers to propagation.TextMapCarrier.
+type headers struct {
+	msg *kafka.Message
+}
+
+func (h headers) Get(key string) string {
+	for _, header := range h.msg.Headers {
+		if header.Key == key {
+			return string(header.Value)
+		}
+	}
+	return ""
+}
+
+func (h headers) Set(key, value string) {
+	for i, header := range h.msg.Headers {
+		if header.Key == key {
+			h.msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	h.msg.Headers = append(h.msg.Headers, kafka.Header{Key: key, Value: []byte(value)})
+}
+
+func (h headers) Keys() []string {
+	keys := make([]string, len(h.msg.Headers))
+	for i, header := range h.msg.Headers {
+		keys[i] = header.Key
+	}
+	return keys
+}
+
+// InjectKafka writes the trace context of ctx into the headers of msg.
+func InjectKafka(ctx context.Context, msg *kafka.Message) {
+	otel.GetTextMapPropagator().Inject(ctx, headers{msg: msg})
+}
+
+// ExtractKafka returns ctx carrying the trace context found in the headers
+// of msg.
+func ExtractKafka(ctx context.Context, msg *kafka.Message) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, headers{msg: msg})
+}
+
+// KafkaAttributes describes a message on topic.
+func KafkaAttributes(topic string) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		semconv.MessagingSystemKafka,
+		semconv.MessagingDestinationName(topic),
+	}
+}
@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+var (
+	// ErrInvalidURL is returned for URLs that are not absolute http or
+	// https URLs.
+	ErrInvalidURL = errors.New("webhook URL must be an absolute http or https URL")
+	// ErrForbiddenAddress is returned for URLs that point to loopback,
+	// private, link-local or other non-public addresses.
+	ErrForbiddenAddress = errors.New("webhook URL must not point to an internal address")
+)
+
+// reserved are non-public IPv4 ranges netip has no predicate for.
+var reserved = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// CheckURL makes sure raw is an absolute http or https URL whose host
+// only resolves to public addresses. DNS answers can change after the
+// check, so requests must also be sent with a client from NewClient.
+func CheckURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidURL
+	}
+
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", u.Hostname())
+	if err != nil {
+		return fmt.Errorf("resolving %s: %w", u.Hostname(), err)
+	}
+	for _, addr := range addrs {
+		if !Public(addr) {
+			return ErrForbiddenAddress
+		}
+	}
+	return nil
+}
+
+// Public reports whether addr may be the target of a webhook.
+func Public(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsValid() || addr.IsUnspecified() || addr.IsLoopback() || addr.IsPrivate() ||
+		addr.IsLinkLocalUnicast() || addr.IsLinkLocalMulticast() ||
+		addr.IsInterfaceLocalMulticast() || addr.IsMulticast() {
+		return false
+	}
+	for _, prefix := range reserved {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// NewClient returns a client for webhook requests. It refuses to connect
+// to non-public addresses, whatever a host resolves to at that moment,
+// and does not follow redirects: a redirect counts as a failed delivery.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialControl}
+	return &http.Client{
+		Timeout: timeout,
+		// no proxy: it would connect on our behalf, past the check
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dialControl runs after a host is resolved and before the connection is
+// made, so it sees the address actually dialed.
+func dialControl(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !Public(addrPort.Addr()) {
+		return fmt.Errorf("dialing %s: %w", address, ErrForbiddenAddress)
+	}
+	return nil
+}
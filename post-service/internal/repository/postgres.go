@@ -2,6 +2,10 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"posts/internal/entity"
+	"posts/internal/pkg/cursor"
 	pb "posts/internal/pkg/genproto"
 )
 
@@ -9,20 +13,56 @@ type StorageI interface {
 	User() UserI
 	Log() LogI
 	Post() PostI
+	Comment() CommentI
+	Tag() TagI
+	Revision() RevisionI
+	Attachment() AttachmentI
+	Follow() FollowI
+	Notification() NotificationI
+	Webhook() WebhookI
+	WithTx(ctx context.Context, fn func(StorageI) error) error
 }
 type LogI interface {
 	Create(ctx context.Context, request *pb.LogCreateRequest) (*pb.LogCreateResponse, error)
+	CreateBatch(ctx context.Context, records []*pb.LogRecord) (int64, error)
 	GetDetail(ctx context.Context, id *pb.GetId) (*pb.LogGetResponse, error)
-	GetList(ctx context.Context, req *pb.FilterLog) (*pb.LogGetAll, error)
+	GetList(ctx context.Context, req *pb.FilterLog, after *cursor.Position) (*pb.LogGetAll, error)
 	Update(ctx context.Context, req *pb.LogUpdateRequest) (*pb.LogVoid, error)
 	Delete(ctx context.Context, Id *pb.GetId) (*pb.LogVoid, error)
+	CountByUser(ctx context.Context, userID int64) (int64, error)
+	DeleteByUser(ctx context.Context, userID int64) (int64, error)
+	AnonymizeByUser(ctx context.Context, userID int64) (int64, error)
+	ListByUser(ctx context.Context, userID int64) ([]*pb.LogGetResponse, error)
+	Stats(ctx context.Context, filter *pb.FilterLog, bucket string) ([]entity.LogCount, error)
+	LastID(ctx context.Context) (int64, error)
+	Since(ctx context.Context, filter *pb.LogTailRequest, afterID int64, limit int) ([]*pb.LogGetResponse, error)
+	Partitions(ctx context.Context) ([]entity.LogPartition, error)
+	CreatePartition(ctx context.Context, p entity.LogPartition) error
+	ExportPartition(ctx context.Context, p entity.LogPartition, fn func(*entity.Log) error) error
+	DropPartition(ctx context.Context, p entity.LogPartition) error
 }
 type PostI interface {
 	Create(ctx context.Context, request *pb.PostCreateRequest) (*pb.PostCreateResponse, error)
 	GetDetail(ctx context.Context, id *pb.GetById) (*pb.PostGetResponse, error)
+	GetBySlug(ctx context.Context, slug string) (*pb.PostGetResponse, error)
+	TakenSlugs(ctx context.Context, base string, postID int64) (map[string]bool, error)
+	SetSlug(ctx context.Context, postID int64, slug string) error
 	GetList(ctx context.Context, req *pb.FilterPost) (*pb.PostGetAll, error)
 	Update(ctx context.Context, req *pb.PostUpdateRequest) (*pb.PostVoid, error)
 	Delete(ctx context.Context, id *pb.GetById) (*pb.PostVoid, error)
+	CountByUser(ctx context.Context, userID int64) (int64, error)
+	DeleteByUser(ctx context.Context, userID int64) (int64, error)
+	AnonymizeByUser(ctx context.Context, userID int64) (int64, error)
+	ListByUser(ctx context.Context, userID int64) ([]*pb.PostGetResponse, error)
+	Search(ctx context.Context, req *pb.PostSearchRequest) (*pb.PostSearchResponse, error)
+	Feed(ctx context.Context, followerID int64, limit int, after *cursor.Position) (*pb.FeedPage, error)
+	AddComments(ctx context.Context, postID int64, delta int64) error
+	PublishDue(ctx context.Context, limit int) ([]*pb.PostPublished, error)
+	AddReaction(ctx context.Context, req *pb.PostReactionRequest) (bool, error)
+	RemoveReaction(ctx context.Context, req *pb.PostReactionRequest) (bool, error)
+	Reactions(ctx context.Context, postID int64) (*pb.PostReactions, error)
+	RefreshReactions(ctx context.Context, postID int64) (*pb.PostReactions, error)
+	ReactionsByUser(ctx context.Context, userID int64) ([]entity.Reaction, error)
 }
 type UserI interface {
 	Create(ctx context.Context, request *pb.UserCreateRequest) (*pb.UserCreateResponse, error)
@@ -32,4 +72,69 @@ type UserI interface {
 	Delete(ctx context.Context, id *pb.ById) (*pb.Void, error)
 	Login(ctx context.Context, request *pb.LoginRequest) (*pb.LoginResponse, error)
 	ChangeUserPassword(ctx context.Context, request *pb.UserRecoverPasswordRequest) (*pb.Void, error)
+	Erase(ctx context.Context, id *pb.ById) (*pb.UserEraseResponse, error)
+	IDByEmail(ctx context.Context, email string) (int64, error)
+	Profile(ctx context.Context, userID int64) (*pb.UserGetResponse, error)
+}
+type CommentI interface {
+	Create(ctx context.Context, request *pb.CommentCreateRequest) (*pb.CommentGet, error)
+	GetDetail(ctx context.Context, id int64) (*pb.CommentGet, error)
+	GetList(ctx context.Context, req *pb.FilterComment) (*pb.CommentGetAll, error)
+	Update(ctx context.Context, req *pb.CommentUpdateRequest) (*pb.CommentVoid, error)
+	Delete(ctx context.Context, req *pb.CommentDeleteRequest) (*pb.CommentVoid, error)
+	ListByUser(ctx context.Context, userID int64) ([]*pb.CommentGet, error)
+}
+type TagI interface {
+	SetForPost(ctx context.Context, postID int64, names []string) ([]string, error)
+	List(ctx context.Context, req *pb.TagListRequest) (*pb.TagList, error)
+	IDsForPost(ctx context.Context, postID int64) ([]int64, error)
+	IDsForUser(ctx context.Context, userID int64) ([]int64, error)
+	DeleteUnused(ctx context.Context, tagIDs []int64) (int64, error)
+}
+type RevisionI interface {
+	Snapshot(ctx context.Context, postID int64) (int64, error)
+	Get(ctx context.Context, req *pb.RevisionRequest) (*pb.PostRevision, error)
+	List(ctx context.Context, req *pb.RevisionListRequest) (*pb.RevisionList, error)
+}
+type AttachmentI interface {
+	Create(ctx context.Context, req *pb.Attachment, storageKey string) (*pb.Attachment, error)
+	GetDetail(ctx context.Context, id int64) (*entity.Attachment, error)
+	GetList(ctx context.Context, req *pb.AttachmentListRequest) (*pb.AttachmentList, error)
+	Delete(ctx context.Context, req *pb.AttachmentDeleteRequest) (*pb.AttachmentVoid, error)
+	Garbage(ctx context.Context, limit int) ([]entity.Attachment, error)
+	Purge(ctx context.Context, ids []int64) error
+	KnownKeys(ctx context.Context, keys []string) (map[string]bool, error)
+}
+type FollowI interface {
+	Follow(ctx context.Context, req *pb.FollowRequest) (bool, error)
+	Unfollow(ctx context.Context, req *pb.FollowRequest) (*pb.Void, error)
+	Followers(ctx context.Context, req *pb.FollowListRequest) (*pb.UserGetAll, error)
+	Following(ctx context.Context, req *pb.FollowListRequest) (*pb.UserGetAll, error)
+	ListByUser(ctx context.Context, userID int64) ([]entity.Follow, error)
+	DeleteByUser(ctx context.Context, userID int64) (int64, error)
+}
+type NotificationI interface {
+	Create(ctx context.Context, req *pb.Notification) (*pb.Notification, error)
+	GetList(ctx context.Context, req *pb.NotificationListRequest) (*pb.NotificationList, error)
+	MarkRead(ctx context.Context, req *pb.NotificationReadRequest) (int64, error)
+	Preferences(ctx context.Context, userID int64) ([]*pb.NotificationPreference, error)
+	SetPreferences(ctx context.Context, userID int64, preferences []*pb.NotificationPreference) error
+	Webhook(ctx context.Context, userID int64) (*entity.NotificationWebhook, error)
+	SetWebhook(ctx context.Context, userID int64, url, secret string) error
+	ListByUser(ctx context.Context, userID int64) ([]*pb.Notification, error)
+	DeleteByUser(ctx context.Context, userID int64) (int64, error)
+}
+type WebhookI interface {
+	Create(ctx context.Context, req *pb.WebhookCreateRequest) (*pb.Webhook, error)
+	GetDetail(ctx context.Context, id int64) (*entity.Webhook, error)
+	GetList(ctx context.Context, req *pb.WebhookListRequest) (*pb.WebhookList, error)
+	Update(ctx context.Context, req *pb.WebhookUpdateRequest) (*pb.Webhook, error)
+	Delete(ctx context.Context, id int64) error
+	Enqueue(ctx context.Context, event, payload string) (int64, error)
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error)
+	SaveAttempt(ctx context.Context, delivery *entity.WebhookDelivery) error
+	Succeeded(ctx context.Context, webhookID int64) error
+	Failed(ctx context.Context, webhookID int64, disableAfter int) (bool, error)
+	Deliveries(ctx context.Context, req *pb.WebhookDeliveryListRequest) (*pb.WebhookDeliveryList, error)
+	Redeliver(ctx context.Context, req *pb.WebhookDeliveryRequest) (*pb.WebhookDelivery, error)
 }
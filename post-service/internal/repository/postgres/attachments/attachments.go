@@ -0,0 +1,154 @@
+package attachments
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+	"posts/internal/entity"
+	"posts/internal/pkg/config"
+	pb "posts/internal/pkg/genproto"
+)
+
+var attachmentColumns = []string{"id", "post_id", "user_id", "storage_key", "file_name", "content_type", "size", "checksum", "created_at"}
+
+type Repository struct {
+	db bun.IDB
+}
+
+func NewRepository(database bun.IDB) *Repository {
+	return &Repository{db: database}
+}
+
+func (r *Repository) Create(ctx context.Context, request *pb.Attachment, storageKey string) (*pb.Attachment, error) {
+	attachment := entity.AttachmentFromProto(request, storageKey)
+	attachment.CreatedBy = attachment.UserID
+
+	_, err := r.db.NewInsert().
+		Model(attachment).
+		Column("post_id", "user_id", "storage_key", "file_name", "content_type", "size", "checksum", "created_by").
+		Returning("id, created_at").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert attachment: %w", err)
+	}
+
+	return attachment.ToProto(), nil
+}
+
+// GetDetail returns a live attachment together with its storage key.
+func (r *Repository) GetDetail(ctx context.Context, id int64) (*entity.Attachment, error) {
+	var attachment entity.Attachment
+
+	err := r.db.NewSelect().
+		Model(&attachment).
+		Column(attachmentColumns...).
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *Repository) GetList(ctx context.Context, request *pb.AttachmentListRequest) (*pb.AttachmentList, error) {
+	var attachments []entity.Attachment
+
+	err := r.db.NewSelect().
+		Model(&attachments).
+		Column(attachmentColumns...).
+		Where("post_id = ?", request.PostId).
+		Where("deleted_at IS NULL").
+		Order("id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying attachments: %w", err)
+	}
+
+	response := make([]*pb.Attachment, 0, len(attachments))
+	for i := range attachments {
+		response = append(response, attachments[i].ToProto())
+	}
+
+	return &pb.AttachmentList{
+		Attachment: response,
+		Count:      int32(len(response)),
+	}, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, request *pb.AttachmentDeleteRequest) (*pb.AttachmentVoid, error) {
+	res, err := r.db.NewUpdate().
+		Table("attachments").
+		Set("deleted_at = NOW()").
+		Set("deleted_by = ?", request.UserId).
+		Where("id = ?", request.Id).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("deleting attachment: %w", err)
+	}
+	if err := config.CheckRowsAffected(res, "attachment"); err != nil {
+		return nil, err
+	}
+	return &pb.AttachmentVoid{}, nil
+}
+
+// Garbage returns up to limit attachments that were deleted or whose post
+// was deleted, so that their blobs can be removed.
+func (r *Repository) Garbage(ctx context.Context, limit int) ([]entity.Attachment, error) {
+	var attachments []entity.Attachment
+
+	err := r.db.NewSelect().
+		Model(&attachments).
+		Column("a.id", "a.storage_key").
+		Join("JOIN posts AS p ON p.id = a.post_id").
+		WhereOr("a.deleted_at IS NOT NULL").
+		WhereOr("p.deleted_at IS NOT NULL").
+		Order("a.id").
+		Limit(limit).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying deleted attachments: %w", err)
+	}
+	return attachments, nil
+}
+
+// Purge removes attachment rows for good, once their blobs are gone.
+func (r *Repository) Purge(ctx context.Context, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	_, err := r.db.NewDelete().
+		Model((*entity.Attachment)(nil)).
+		Where("id IN (?)", bun.In(ids)).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("purging attachments: %w", err)
+	}
+	return nil
+}
+
+// KnownKeys reports which of the given storage keys belong to an
+// attachment row, deleted or not.
+func (r *Repository) KnownKeys(ctx context.Context, keys []string) (map[string]bool, error) {
+	known := make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return known, nil
+	}
+
+	var found []string
+	err := r.db.NewSelect().
+		Model((*entity.Attachment)(nil)).
+		Column("storage_key").
+		Where("storage_key IN (?)", bun.In(keys)).
+		Scan(ctx, &found)
+	if err != nil {
+		return nil, fmt.Errorf("looking up storage keys: %w", err)
+	}
+
+	for _, key := range found {
+		known[key] = true
+	}
+	return known, nil
+}
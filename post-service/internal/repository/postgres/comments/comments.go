@@ -0,0 +1,160 @@
+package comments
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+	"posts/internal/entity"
+	"posts/internal/pkg/config"
+	pb "posts/internal/pkg/genproto"
+)
+
+type Repository struct {
+	db bun.IDB
+}
+
+func NewRepository(database bun.IDB) *Repository {
+	return &Repository{db: database}
+}
+
+func (r *Repository) Create(ctx context.Context, request *pb.CommentCreateRequest) (*pb.CommentGet, error) {
+	comment := entity.CommentFromCreateRequest(request)
+	comment.CreatedBy = comment.UserID
+
+	_, err := r.db.NewInsert().
+		Model(comment).
+		Column("post_id", "parent_id", "user_id", "content", "created_by").
+		Returning("id, created_at").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert comment: %w", err)
+	}
+
+	return comment.ToCommentGet(), nil
+}
+
+// GetDetail returns a live comment without its author.
+func (r *Repository) GetDetail(ctx context.Context, id int64) (*pb.CommentGet, error) {
+	var comment entity.Comment
+
+	err := r.db.NewSelect().
+		Model(&comment).
+		Column("id", "post_id", "parent_id", "user_id", "content", "created_at", "updated_at").
+		Where("id = ?", id).
+		Where("deleted_at IS NULL").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return comment.ToCommentGet(), nil
+}
+
+func (r *Repository) Update(ctx context.Context, request *pb.CommentUpdateRequest) (*pb.CommentVoid, error) {
+	res, err := r.db.NewUpdate().
+		Table("comments").
+		Set("content = ?", request.Content).
+		Set("updated_at = NOW()").
+		Set("updated_by = ?", request.UserId).
+		Where("id = ?", request.Id).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("updating comment: %w", err)
+	}
+	if err := config.CheckRowsAffected(res, "comment"); err != nil {
+		return nil, err
+	}
+	return &pb.CommentVoid{}, nil
+}
+
+func (r *Repository) Delete(ctx context.Context, request *pb.CommentDeleteRequest) (*pb.CommentVoid, error) {
+	res, err := r.db.NewUpdate().
+		Table("comments").
+		Set("deleted_at = NOW()").
+		Set("deleted_by = ?", request.UserId).
+		Where("id = ?", request.Id).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("deleting comment: %w", err)
+	}
+	if err := config.CheckRowsAffected(res, "comment"); err != nil {
+		return nil, err
+	}
+	return &pb.CommentVoid{}, nil
+}
+
+// GetList returns one level of a post's comment thread: the top-level
+// comments when filter.ParentId is zero, the direct replies otherwise. Each
+// comment carries the number of its live replies so clients can expand it.
+func (r *Repository) GetList(ctx context.Context, filter *pb.FilterComment) (*pb.CommentGetAll, error) {
+	var comments []entity.Comment
+
+	query := r.db.NewSelect().
+		Model(&comments).
+		Column("c.id", "c.post_id", "c.parent_id", "c.user_id", "c.content", "c.created_at", "c.updated_at").
+		ColumnExpr(`(SELECT COUNT(*) FROM comments AS r WHERE r.parent_id = c.id AND r.deleted_at IS NULL) AS replies_count`).
+		RelationWithOpts("User", bun.RelationOpts{
+			Apply: func(q *bun.SelectQuery) *bun.SelectQuery {
+				return q.Column("id", "firstname", "lastname", "username")
+			},
+			AdditionalJoinOnConditions: []schema.QueryWithArgs{
+				schema.SafeQuery(`"user"."deleted_at" IS NULL`, nil),
+			},
+		}).
+		Where("c.post_id = ?", filter.PostId).
+		Where("c.deleted_at IS NULL")
+
+	if filter.ParentId != 0 {
+		query.Where("c.parent_id = ?", filter.ParentId)
+	} else {
+		query.Where("c.parent_id IS NULL")
+	}
+
+	if filter.Limit > 0 {
+		query.Limit(int(filter.Limit))
+	}
+	if filter.Page > 0 && filter.Limit > 0 {
+		query.Offset(int((filter.Page - 1) * filter.Limit))
+	}
+
+	count, err := query.Order("c.created_at ASC", "c.id ASC").ScanAndCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying comments: %w", err)
+	}
+
+	response := make([]*pb.CommentGet, 0, len(comments))
+	for i := range comments {
+		response = append(response, comments[i].ToCommentGet())
+	}
+
+	return &pb.CommentGetAll{
+		Comment: response,
+		Count:   int32(count),
+	}, nil
+}
+
+// ListByUser returns every comment the user wrote, deleted ones included,
+// oldest first.
+func (r *Repository) ListByUser(ctx context.Context, userID int64) ([]*pb.CommentGet, error) {
+	var comments []entity.Comment
+
+	err := r.db.NewSelect().
+		Model(&comments).
+		Column("id", "post_id", "parent_id", "user_id", "content", "created_at", "updated_at").
+		Where("user_id = ?", userID).
+		Order("id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing comments of user: %w", err)
+	}
+
+	response := make([]*pb.CommentGet, 0, len(comments))
+	for i := range comments {
+		response = append(response, comments[i].ToCommentGet())
+	}
+	return response, nil
+}
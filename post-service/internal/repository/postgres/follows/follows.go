@@ -0,0 +1,126 @@
+package follows
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+)
+
+// userColumns are the follower and followee columns returned to callers.
+var userColumns = []string{"u.id", "u.firstname", "u.lastname", "u.username", "u.email", "u.phone", "u.gender", "u.role", "u.created_at"}
+
+type Repository struct {
+	db bun.IDB
+}
+
+func NewRepository(database bun.IDB) *Repository {
+	return &Repository{db: database}
+}
+
+// Follow makes the follower follow the followee and reports whether the
+// follow is new. Following someone twice is a no-op.
+func (r *Repository) Follow(ctx context.Context, request *pb.FollowRequest) (bool, error) {
+	follow := &entity.Follow{FollowerID: request.FollowerId, FolloweeID: request.FolloweeId}
+
+	res, err := r.db.NewInsert().
+		Model(follow).
+		Column("follower_id", "followee_id").
+		On("CONFLICT DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("following user: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Unfollow removes a follow; removing one that does not exist is a no-op.
+func (r *Repository) Unfollow(ctx context.Context, request *pb.FollowRequest) (*pb.Void, error) {
+	_, err := r.db.NewDelete().
+		Model((*entity.Follow)(nil)).
+		Where("follower_id = ?", request.FollowerId).
+		Where("followee_id = ?", request.FolloweeId).
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unfollowing user: %w", err)
+	}
+	return &pb.Void{}, nil
+}
+
+// Followers lists the live users following request.UserId, most recent
+// first.
+func (r *Repository) Followers(ctx context.Context, request *pb.FollowListRequest) (*pb.UserGetAll, error) {
+	return r.list(ctx, request, "f.follower_id = u.id", "f.followee_id = ?")
+}
+
+// Following lists the live users request.UserId follows, most recent
+// first.
+func (r *Repository) Following(ctx context.Context, request *pb.FollowListRequest) (*pb.UserGetAll, error) {
+	return r.list(ctx, request, "f.followee_id = u.id", "f.follower_id = ?")
+}
+
+func (r *Repository) list(ctx context.Context, request *pb.FollowListRequest, join, where string) (*pb.UserGetAll, error) {
+	var users []entity.User
+
+	query := r.db.NewSelect().
+		Model(&users).
+		Column(userColumns...).
+		Join("JOIN follows AS f ON "+join).
+		Where(where, request.UserId).
+		Where("u.deleted_at IS NULL").
+		Order("f.created_at DESC", "u.id")
+
+	if request.Limit > 0 {
+		query.Limit(int(request.Limit))
+	}
+	if request.Page > 0 && request.Limit > 0 {
+		query.Offset(int((request.Page - 1) * request.Limit))
+	}
+
+	count, err := query.ScanAndCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying follows: %w", err)
+	}
+
+	response := make([]*pb.UserGetList, 0, len(users))
+	for i := range users {
+		response = append(response, users[i].ToGetList())
+	}
+
+	return &pb.UserGetAll{
+		User:  response,
+		Count: int32(count),
+	}, nil
+}
+
+// ListByUser returns the follows the user takes part in, either as
+// follower or as followee.
+func (r *Repository) ListByUser(ctx context.Context, userID int64) ([]entity.Follow, error) {
+	var follows []entity.Follow
+
+	err := r.db.NewSelect().
+		Model(&follows).
+		Where("follower_id = ? OR followee_id = ?", userID, userID).
+		Order("created_at", "follower_id", "followee_id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing follows of user: %w", err)
+	}
+	return follows, nil
+}
+
+// DeleteByUser removes the follows the user takes part in and returns how
+// many there were.
+func (r *Repository) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	res, err := r.db.NewDelete().
+		Model((*entity.Follow)(nil)).
+		Where("follower_id = ? OR followee_id = ?", userID, userID).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("deleting follows of user: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -2,105 +2,104 @@ package logs
 
 import (
 	"context"
-	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"strings"
+	"time"
 
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"posts/internal/entity"
+	"posts/internal/pkg/cursor"
 	pb "posts/internal/pkg/genproto"
 )
 
 type Repository struct {
-	*sql.DB
+	db bun.IDB
 }
 
-func NewRepository(database *sql.DB) *Repository {
-	return &Repository{DB: database}
+func NewRepository(database bun.IDB) *Repository {
+	return &Repository{db: database}
 }
 
 func (r *Repository) Create(ctx context.Context, request *pb.LogCreateRequest) (*pb.LogCreateResponse, error) {
-	query := `INSERT INTO logs (
-								level, 
-								message, 
-								service_name) 
-	          VALUES ($1, $2, $3)`
-
-	_, err := r.DB.ExecContext(
-		ctx,
-		query,
-		request.Level,
-		request.Message,
-		request.ServiceName,
-	)
+	log := entity.LogFromCreateRequest(request)
+
+	_, err := r.db.NewInsert().
+		Model(log).
+		Column("level", "message", "service_name", "attributes").
+		Returning("id").
+		Exec(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert logs: %w", err)
 	}
 
-	response := pb.LogCreateResponse{
-		Level:       request.Level,
-		Message:     request.Message,
-		ServiceName: request.ServiceName,
+	return log.ToCreateResponse(), nil
+}
+
+// CreateBatch inserts shipped log records with a single statement.
+func (r *Repository) CreateBatch(ctx context.Context, records []*pb.LogRecord) (int64, error) {
+	if len(records) == 0 {
+		return 0, nil
 	}
-	return &response, nil
+
+	logs := make([]*entity.Log, 0, len(records))
+	for _, record := range records {
+		logs = append(logs, entity.LogFromRecord(record))
+	}
+
+	res, err := r.db.NewInsert().
+		Model(&logs).
+		Column("level", "message", "service_name", "attributes", "created_at").
+		Returning("NULL").
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert log batch: %w", err)
+	}
+	return res.RowsAffected()
 }
 
 func (r *Repository) GetDetail(ctx context.Context, request *pb.GetId) (*pb.LogGetResponse, error) {
-	query := `SELECT 
-					id, 
-					level, 
-					message, 
-					service_name, 
-					created_at
-	          FROM logs 
-			  WHERE id = $1`
-	row := r.DB.QueryRowContext(ctx, query, request.Id)
-
-	var log pb.LogGetResponse
-	err := row.Scan(
-		&log.Id,
-		&log.Level,
-		&log.Message,
-		&log.ServiceName,
-		&log.CreatedAt,
-	)
+	var log entity.Log
+
+	err := r.db.NewSelect().
+		Model(&log).
+		Column("id", "level", "message", "service_name", "created_at", "created_by", "attributes").
+		Where("id = ?", request.Id).
+		Scan(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &log, nil
+	return log.ToGetResponse(), nil
 }
 
 func (r *Repository) Update(ctx context.Context, request *pb.LogUpdateRequest) (*pb.LogVoid, error) {
-	query := `UPDATE logs SET updated_at = NOW()`
-
-	var arg []interface{}
-	var conditions []string
+	query := r.db.NewUpdate().
+		Table("logs").
+		Set("updated_at = NOW()")
+	updates := 0
 
 	if request.Level != "" && request.Level != "string" {
-		arg = append(arg, request.Level)
-		conditions = append(conditions, fmt.Sprintf("level = $%d", len(arg)))
+		query.Set("level = ?", request.Level)
+		updates++
 	}
 
 	if request.Message != "" && request.Message != "string" {
-		arg = append(arg, request.Message)
-		conditions = append(conditions, fmt.Sprintf("message = $%d", len(arg)))
+		query.Set("message = ?", request.Message)
+		updates++
 	}
 
 	if request.ServiceName != "" && request.ServiceName != "string" {
-		arg = append(arg, request.ServiceName)
-		conditions = append(conditions, fmt.Sprintf("service_name = $%d", len(arg)))
+		query.Set("service_name = ?", request.ServiceName)
+		updates++
 	}
 
-	if len(conditions) == 0 {
+	if updates == 0 {
 		return nil, fmt.Errorf("no fields to update")
 	}
 
-	query += ", " + strings.Join(conditions, ", ")
-
-	arg = append(arg, request.Id)
-	query += fmt.Sprintf(" WHERE id = $%d", len(arg))
-
-	_, err := r.DB.ExecContext(ctx, query, arg...)
+	_, err := query.Where("id = ?", request.Id).Exec(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -108,73 +107,315 @@ func (r *Repository) Update(ctx context.Context, request *pb.LogUpdateRequest) (
 }
 
 func (r *Repository) Delete(ctx context.Context, request *pb.GetId) (*pb.LogVoid, error) {
-	query := `update logs set deleted_at = NOW() WHERE id = $1`
-	_, err := r.DB.ExecContext(ctx, query, request.Id)
+	_, err := r.db.NewUpdate().
+		Table("logs").
+		Set("deleted_at = NOW()").
+		Where("id = ?", request.Id).
+		Exec(ctx)
 	return &pb.LogVoid{}, err
 }
 
-func (r *Repository) GetList(ctx context.Context, filter *pb.FilterLog) (*pb.LogGetAll, error) {
-	where := `WHERE deleted_at IS NULL`
-	var args []interface{}
+// CountByUser returns how many live log entries were created by a user.
+func (r *Repository) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	count, err := r.db.NewSelect().
+		Table("logs").
+		Where("created_by = ?", userID).
+		Where("deleted_at IS NULL").
+		Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("counting logs of user: %w", err)
+	}
+	return int64(count), nil
+}
+
+// DeleteByUser soft-deletes every live log entry created by a user.
+func (r *Repository) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	res, err := r.db.NewUpdate().
+		Table("logs").
+		Set("deleted_at = NOW()").
+		Where("created_by = ?", userID).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("deleting logs of user: %w", err)
+	}
+	return res.RowsAffected()
+}
 
+// AnonymizeByUser clears the author of every log entry created by a user.
+func (r *Repository) AnonymizeByUser(ctx context.Context, userID int64) (int64, error) {
+	res, err := r.db.NewUpdate().
+		Table("logs").
+		Set("created_by = NULL").
+		Where("created_by = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("anonymizing logs of user: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ListByUser returns every log entry created by a user, including deleted ones.
+func (r *Repository) ListByUser(ctx context.Context, userID int64) ([]*pb.LogGetResponse, error) {
+	var logs []entity.Log
+
+	err := r.db.NewSelect().
+		Model(&logs).
+		Column("id", "level", "message", "service_name", "created_at", "created_by", "attributes").
+		Where("created_by = ?", userID).
+		Order("id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing logs of user: %w", err)
+	}
+
+	response := make([]*pb.LogGetResponse, 0, len(logs))
+	for i := range logs {
+		response = append(response, logs[i].ToGetResponse())
+	}
+	return response, nil
+}
+
+// GetList returns live logs, newest first. A page number selects the page
+// by OFFSET and counts all matching logs; otherwise the page starts after
+// the given position and is chained with NextCursor, which stays cheap on
+// large tables.
+func (r *Repository) GetList(ctx context.Context, filter *pb.FilterLog, after *cursor.Position) (*pb.LogGetAll, error) {
+	var logs []entity.Log
+
+	query := r.db.NewSelect().
+		Model(&logs).
+		Column("id", "level", "message", "service_name", "created_at", "created_by", "attributes")
+	filterLogs(query, filter)
+
+	if filter.Page > 0 {
+		if filter.Limit > 0 {
+			query.Limit(int(filter.Limit)).Offset(int((filter.Page - 1) * filter.Limit))
+		}
+
+		count, err := query.Order("created_at DESC", "id DESC").ScanAndCount(ctx)
+		if err != nil {
+			return nil, errors.New("querying logs")
+		}
+		return &pb.LogGetAll{Log: logResponses(logs), Count: int32(count)}, nil
+	}
+
+	if after != nil {
+		query.Where("(created_at, id) < (?, ?)", after.Time, after.ID)
+	}
+
+	// one extra row tells whether another page follows
+	limit := int(filter.Limit)
+	err := query.
+		Order("created_at DESC", "id DESC").
+		Limit(limit + 1).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying logs: %w", err)
+	}
+
+	page := &pb.LogGetAll{}
+	if len(logs) > limit {
+		logs = logs[:limit]
+		last := logs[limit-1]
+		position := cursor.Position{ID: last.ID}
+		if last.CreatedAt != nil {
+			position.Time = *last.CreatedAt
+		}
+		page.NextCursor = cursor.Encode(position)
+	}
+	page.Log = logResponses(logs)
+	page.Count = int32(len(page.Log))
+	return page, nil
+}
+
+// Stats counts live logs matching the filter by level and service name,
+// and by the start of each bucket ("minute", "hour" or "day") unless
+// bucket is empty. Rows are ordered by bucket.
+func (r *Repository) Stats(ctx context.Context, filter *pb.FilterLog, bucket string) ([]entity.LogCount, error) {
+	var counts []entity.LogCount
+
+	query := r.db.NewSelect().
+		TableExpr("logs AS l").
+		Column("level", "service_name").
+		ColumnExpr("count(*) AS count").
+		Group("level", "service_name")
+	if bucket != "" {
+		query.ColumnExpr("date_trunc(?, created_at) AS bucket", bucket).
+			GroupExpr("bucket").
+			OrderExpr("bucket")
+	}
+	filterLogs(query, filter)
+
+	err := query.Order("level", "service_name").Scan(ctx, &counts)
+	if err != nil {
+		return nil, fmt.Errorf("counting logs: %w", err)
+	}
+	return counts, nil
+}
+
+// filterLogs narrows query to live logs matching the filter. The service
+// validates levels and times beforehand.
+func filterLogs(query *bun.SelectQuery, filter *pb.FilterLog) {
+	query.Where("deleted_at IS NULL")
+
+	levels := filter.Levels
 	if filter.Level != "" {
-		args = append(args, filter.Level)
-		where += fmt.Sprintf(" AND level = $%d", len(args))
+		levels = append([]string{filter.Level}, levels...)
+	}
+	if len(levels) == 1 {
+		query.Where("level = ?", levels[0])
+	} else if len(levels) > 1 {
+		query.Where("level IN (?)", bun.In(levels))
 	}
 
 	if filter.ServiceName != "" {
-		args = append(args, filter.ServiceName)
-		where += fmt.Sprintf(" AND service_name = $%d", len(args))
+		query.Where("service_name = ?", filter.ServiceName)
+	}
+
+	// created_at has no time zone and holds UTC
+	if from, err := time.Parse(time.RFC3339, filter.From); err == nil {
+		query.Where("created_at >= ?", from.UTC())
+	}
+	if to, err := time.Parse(time.RFC3339, filter.To); err == nil {
+		query.Where("created_at < ?", to.UTC())
+	}
+
+	if filter.Query != "" {
+		query.Where("search_vector @@ websearch_to_tsquery('simple', ?)", filter.Query)
 	}
 
-	var limit, offset int64
+	// \\? keeps bun from taking the operator for a placeholder
+	if len(filter.AttributeKeys) > 0 {
+		query.Where("attributes \\?& ?", pgdialect.Array(filter.AttributeKeys))
+	}
+	if len(filter.Attributes) > 0 {
+		attributes, _ := json.Marshal(filter.Attributes)
+		query.Where("attributes @> ?::jsonb", string(attributes))
+	}
+}
+
+func logResponses(logs []entity.Log) []*pb.LogGetResponse {
+	response := make([]*pb.LogGetResponse, 0, len(logs))
+	for i := range logs {
+		response = append(response, logs[i].ToGetResponse())
+	}
+	return response
+}
+
+// LastID returns the id of the newest log, or 0 when there is none.
+func (r *Repository) LastID(ctx context.Context) (int64, error) {
+	var id int64
+	err := r.db.NewSelect().
+		Table("logs").
+		ColumnExpr("COALESCE(max(id), 0)").
+		Scan(ctx, &id)
+	if err != nil {
+		return 0, fmt.Errorf("reading last log id: %w", err)
+	}
+	return id, nil
+}
+
+// Since returns up to limit live logs with an id greater than afterID that
+// match the filter's level and service name, in id order.
+func (r *Repository) Since(ctx context.Context, filter *pb.LogTailRequest, afterID int64, limit int) ([]*pb.LogGetResponse, error) {
+	var logs []entity.Log
+
+	query := r.db.NewSelect().
+		Model(&logs).
+		Column("id", "level", "message", "service_name", "created_at", "created_by", "attributes").
+		Where("id > ?", afterID).
+		Where("deleted_at IS NULL")
 
-	if filter.Limit > 0 {
-		limit = filter.Limit
+	if filter.Level != "" {
+		query.Where("level = ?", filter.Level)
+	}
+
+	if filter.ServiceName != "" {
+		query.Where("service_name = ?", filter.ServiceName)
 	}
-	if filter.Page > 0 && filter.Limit > 0 {
-		offset = (filter.Page - 1) * filter.Limit
+
+	err := query.Order("id").Limit(limit).Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying new logs: %w", err)
 	}
+	return logResponses(logs), nil
+}
 
-	args = append(args, limit, offset)
+// Partitions returns the monthly partitions of the logs table, oldest
+// first. The default partition is left out.
+func (r *Repository) Partitions(ctx context.Context) ([]entity.LogPartition, error) {
+	var names []string
+	err := r.db.NewRaw(`SELECT c.relname FROM pg_inherits AS i JOIN pg_class AS c ON c.oid = i.inhrelid WHERE i.inhparent = 'logs'::regclass ORDER BY c.relname`).
+		Scan(ctx, &names)
+	if err != nil {
+		return nil, fmt.Errorf("listing log partitions: %w", err)
+	}
 
-	query := fmt.Sprintf(`
-		SELECT 
-			COUNT(id) OVER () AS total_count,
-			id, 
-			level, 
-			message, 
-			service_name, 
-			created_at 
-		FROM logs 
-		%s ORDER BY created_at DESC LIMIT $%d OFFSET $%d`, where, len(args)-1, len(args))
+	partitions := make([]entity.LogPartition, 0, len(names))
+	for _, name := range names {
+		if p, ok := entity.ParseLogPartition(name); ok {
+			partitions = append(partitions, p)
+		}
+	}
+	return partitions, nil
+}
 
-	rows, err := r.DB.QueryContext(ctx, query, args...)
+// CreatePartition creates the partition unless it exists.
+func (r *Repository) CreatePartition(ctx context.Context, p entity.LogPartition) error {
+	_, err := r.db.NewRaw(`CREATE TABLE IF NOT EXISTS ? PARTITION OF logs FOR VALUES FROM (?) TO (?)`,
+		bun.Ident(p.Name), p.From.Format(partitionBound), p.To.Format(partitionBound)).
+		Exec(ctx)
 	if err != nil {
-		return nil, errors.New("querying logs")
-	}
-	defer rows.Close()
-
-	var response []*pb.LogGetResponse
-	var count int32
-	for rows.Next() {
-		var log pb.LogGetResponse
-		err := rows.Scan(
-			&count,
-			&log.Id,
-			&log.Level,
-			&log.Message,
-			&log.ServiceName,
-			&log.CreatedAt,
-		)
+		return fmt.Errorf("creating log partition %s: %w", p.Name, err)
+	}
+	return nil
+}
+
+// partitionBound formats partition bounds; created_at has no time zone.
+const partitionBound = "2006-01-02 15:04:05"
+
+// exportBatchSize is how many logs ExportPartition reads per query.
+const exportBatchSize = 1000
+
+// ExportPartition calls fn for every log in the partition, deleted ones
+// included, in id order.
+func (r *Repository) ExportPartition(ctx context.Context, p entity.LogPartition, fn func(*entity.Log) error) error {
+	var lastID int64
+	for {
+		var logs []entity.Log
+		err := r.db.NewSelect().
+			Model(&logs).
+			ModelTableExpr("? AS l", bun.Ident(p.Name)).
+			Column("id", "level", "message", "service_name", "attributes", "created_at", "created_by", "updated_at", "updated_by", "deleted_at", "deleted_by").
+			Where("id > ?", lastID).
+			Order("id").
+			Limit(exportBatchSize).
+			Scan(ctx)
 		if err != nil {
-			return nil, err
+			return fmt.Errorf("reading log partition %s: %w", p.Name, err)
+		}
+
+		for i := range logs {
+			if err := fn(&logs[i]); err != nil {
+				return err
+			}
+		}
+		if len(logs) < exportBatchSize {
+			return nil
 		}
-		response = append(response, &log)
+		lastID = logs[len(logs)-1].ID
 	}
+}
 
-	return &pb.LogGetAll{
-		Log:   response,
-		Count: count,
-	}, nil
+// DropPartition drops the partition together with its logs.
+func (r *Repository) DropPartition(ctx context.Context, p entity.LogPartition) error {
+	if _, ok := entity.ParseLogPartition(p.Name); !ok {
+		return fmt.Errorf("%s is not a monthly log partition", p.Name)
+	}
+	_, err := r.db.NewRaw(`DROP TABLE IF EXISTS ?`, bun.Ident(p.Name)).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("dropping log partition %s: %w", p.Name, err)
+	}
+	return nil
 }
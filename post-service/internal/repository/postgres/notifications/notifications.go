@@ -0,0 +1,237 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/uptrace/bun"
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+)
+
+type Repository struct {
+	db bun.IDB
+}
+
+func NewRepository(database bun.IDB) *Repository {
+	return &Repository{db: database}
+}
+
+func (r *Repository) Create(ctx context.Context, request *pb.Notification) (*pb.Notification, error) {
+	notification := entity.NotificationFromProto(request)
+
+	_, err := r.db.NewInsert().
+		Model(notification).
+		Column("user_id", "kind", "actor_id", "post_id", "comment_id", "title", "body").
+		Returning("id, created_at").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert notification: %w", err)
+	}
+	return notification.ToProto(), nil
+}
+
+// GetList returns a user's notifications, newest first, together with the
+// number of unread ones.
+func (r *Repository) GetList(ctx context.Context, request *pb.NotificationListRequest) (*pb.NotificationList, error) {
+	var notifications []entity.Notification
+
+	query := r.db.NewSelect().
+		Model(&notifications).
+		Where("user_id = ?", request.UserId)
+	if request.UnreadOnly {
+		query.Where("read_at IS NULL")
+	}
+	if request.Limit > 0 {
+		query.Limit(int(request.Limit))
+	}
+	if request.Page > 0 && request.Limit > 0 {
+		query.Offset(int((request.Page - 1) * request.Limit))
+	}
+
+	count, err := query.Order("created_at DESC", "id DESC").ScanAndCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying notifications: %w", err)
+	}
+
+	unread, err := r.db.NewSelect().
+		Model((*entity.Notification)(nil)).
+		Where("user_id = ?", request.UserId).
+		Where("read_at IS NULL").
+		Count(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("counting unread notifications: %w", err)
+	}
+
+	response := make([]*pb.Notification, 0, len(notifications))
+	for i := range notifications {
+		response = append(response, notifications[i].ToProto())
+	}
+
+	return &pb.NotificationList{
+		Notification: response,
+		Count:        int32(count),
+		UnreadCount:  int64(unread),
+	}, nil
+}
+
+// MarkRead marks the given notifications of a user, or all of them, as
+// read and returns how many were unread before.
+func (r *Repository) MarkRead(ctx context.Context, request *pb.NotificationReadRequest) (int64, error) {
+	query := r.db.NewUpdate().
+		Model((*entity.Notification)(nil)).
+		Set("read_at = NOW()").
+		Where("user_id = ?", request.UserId).
+		Where("read_at IS NULL")
+	if !request.All {
+		query.Where("id IN (?)", bun.In(request.Ids))
+	}
+
+	res, err := query.Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("marking notifications as read: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Preferences returns the preferences a user has set; kinds and channels
+// without a row use the defaults.
+func (r *Repository) Preferences(ctx context.Context, userID int64) ([]*pb.NotificationPreference, error) {
+	var preferences []entity.NotificationPreference
+
+	err := r.db.NewSelect().
+		Model(&preferences).
+		Where("user_id = ?", userID).
+		Order("kind", "channel").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying notification preferences: %w", err)
+	}
+
+	response := make([]*pb.NotificationPreference, 0, len(preferences))
+	for i := range preferences {
+		response = append(response, preferences[i].ToProto())
+	}
+	return response, nil
+}
+
+func (r *Repository) SetPreferences(ctx context.Context, userID int64, preferences []*pb.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+
+	rows := make([]entity.NotificationPreference, 0, len(preferences))
+	for _, p := range preferences {
+		rows = append(rows, entity.NotificationPreference{
+			UserID:  userID,
+			Kind:    p.Kind,
+			Channel: p.Channel,
+			Enabled: p.Enabled,
+		})
+	}
+
+	_, err := r.db.NewInsert().
+		Model(&rows).
+		On("CONFLICT (user_id, kind, channel) DO UPDATE").
+		Set("enabled = EXCLUDED.enabled").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("saving notification preferences: %w", err)
+	}
+	return nil
+}
+
+// Webhook returns where the user's webhook notifications go and the
+// secret that signs them. Users without a webhook get an empty URL.
+func (r *Repository) Webhook(ctx context.Context, userID int64) (*entity.NotificationWebhook, error) {
+	webhook := entity.NotificationWebhook{UserID: userID}
+
+	err := r.db.NewSelect().
+		Model(&webhook).
+		Column("url", "secret").
+		Where("user_id = ?", userID).
+		Scan(ctx)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &webhook, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying notification webhook: %w", err)
+	}
+	return &webhook, nil
+}
+
+// SetWebhook stores the user's webhook URL and signing secret; an empty
+// URL removes the webhook.
+func (r *Repository) SetWebhook(ctx context.Context, userID int64, url, secret string) error {
+	var err error
+	if url == "" {
+		_, err = r.db.NewDelete().
+			Model((*entity.NotificationWebhook)(nil)).
+			Where("user_id = ?", userID).
+			Exec(ctx)
+	} else {
+		_, err = r.db.NewInsert().
+			Model(&entity.NotificationWebhook{UserID: userID, URL: url, Secret: secret}).
+			Column("user_id", "url", "secret").
+			On("CONFLICT (user_id) DO UPDATE").
+			Set("url = EXCLUDED.url").
+			Set("secret = EXCLUDED.secret").
+			Set("updated_at = NOW()").
+			Exec(ctx)
+	}
+	if err != nil {
+		return fmt.Errorf("saving notification webhook: %w", err)
+	}
+	return nil
+}
+
+// ListByUser returns every notification the user received, oldest first.
+func (r *Repository) ListByUser(ctx context.Context, userID int64) ([]*pb.Notification, error) {
+	var notifications []entity.Notification
+
+	err := r.db.NewSelect().
+		Model(&notifications).
+		Where("user_id = ?", userID).
+		Order("id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing notifications of user: %w", err)
+	}
+
+	response := make([]*pb.Notification, 0, len(notifications))
+	for i := range notifications {
+		response = append(response, notifications[i].ToProto())
+	}
+	return response, nil
+}
+
+// DeleteByUser removes the user's notifications, preferences and webhook,
+// together with other users' notifications about the user's actions,
+// whose titles name the user. It returns how many notifications went.
+func (r *Repository) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	res, err := r.db.NewDelete().
+		Model((*entity.Notification)(nil)).
+		Where("user_id = ? OR actor_id = ?", userID, userID).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("deleting notifications of user: %w", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = r.db.NewDelete().
+		Model((*entity.NotificationPreference)(nil)).
+		Where("user_id = ?", userID).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("deleting notification preferences of user: %w", err)
+	}
+	if err := r.SetWebhook(ctx, userID, "", ""); err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
@@ -1,24 +1,67 @@
 package repo
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"github.com/uptrace/bun/extra/bunotel"
 	"posts/internal/repository"
+	attachment "posts/internal/repository/postgres/attachments"
+	comment "posts/internal/repository/postgres/comments"
+	follow "posts/internal/repository/postgres/follows"
 	log "posts/internal/repository/postgres/logs"
+	notification "posts/internal/repository/postgres/notifications"
 	post "posts/internal/repository/postgres/posts"
+	revision "posts/internal/repository/postgres/revisions"
+	tag "posts/internal/repository/postgres/tags"
 	user "posts/internal/repository/postgres/users"
+	webhook "posts/internal/repository/postgres/webhooks"
 )
 
+// maxTxAttempts bounds how many times WithTx re-runs a transaction that
+// failed with a serialization failure or deadlock.
+const maxTxAttempts = 3
+
 type Storage struct {
-	UserS repository.UserI
-	LogS  repository.LogI
-	PostS repository.PostI
+	db            *bun.DB
+	UserS         repository.UserI
+	LogS          repository.LogI
+	PostS         repository.PostI
+	CommentS      repository.CommentI
+	TagS          repository.TagI
+	RevisionS     repository.RevisionI
+	AttachmentS   repository.AttachmentI
+	FollowS       repository.FollowI
+	NotificationS repository.NotificationI
+	WebhookS      repository.WebhookI
 }
 
 func NewStorage(db *sql.DB) *Storage {
+	bdb := bun.NewDB(db, pgdialect.New())
+	bdb.AddQueryHook(bunotel.NewQueryHook(bunotel.WithDBName("posts")))
+
+	storage := newStorage(bdb)
+	storage.db = bdb
+	return storage
+}
+
+func newStorage(db bun.IDB) *Storage {
 	return &Storage{
-		UserS: user.NewRepository(db),
-		LogS:  log.NewRepository(db),
-		PostS: post.NewRepository(db),
+		UserS:         user.NewRepository(db),
+		LogS:          log.NewRepository(db),
+		PostS:         post.NewRepository(db),
+		CommentS:      comment.NewRepository(db),
+		TagS:          tag.NewRepository(db),
+		RevisionS:     revision.NewRepository(db),
+		AttachmentS:   attachment.NewRepository(db),
+		FollowS:       follow.NewRepository(db),
+		NotificationS: notification.NewRepository(db),
+		WebhookS:      webhook.NewRepository(db),
 	}
 }
 
@@ -33,3 +76,67 @@ func (s *Storage) Log() repository.LogI {
 func (s *Storage) Post() repository.PostI {
 	return s.PostS
 }
+
+func (s *Storage) Comment() repository.CommentI {
+	return s.CommentS
+}
+
+func (s *Storage) Tag() repository.TagI {
+	return s.TagS
+}
+
+func (s *Storage) Revision() repository.RevisionI {
+	return s.RevisionS
+}
+
+func (s *Storage) Attachment() repository.AttachmentI {
+	return s.AttachmentS
+}
+
+func (s *Storage) Follow() repository.FollowI {
+	return s.FollowS
+}
+
+func (s *Storage) Notification() repository.NotificationI {
+	return s.NotificationS
+}
+
+func (s *Storage) Webhook() repository.WebhookI {
+	return s.WebhookS
+}
+
+// WithTx runs fn against repositories bound to a single serializable
+// transaction. The transaction is committed when fn returns nil and rolled
+// back otherwise; serialization failures and deadlocks are retried. Calling
+// WithTx on a transaction-scoped storage reuses the running transaction.
+func (s *Storage) WithTx(ctx context.Context, fn func(repository.StorageI) error) error {
+	if s.db == nil {
+		return fn(s)
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = s.db.RunInTx(ctx, &sql.TxOptions{Isolation: sql.LevelSerializable}, func(ctx context.Context, tx bun.Tx) error {
+			return fn(newStorage(tx))
+		})
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt*attempt) * 10 * time.Millisecond):
+		}
+	}
+	return err
+}
+
+func isRetryable(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+	// serialization_failure, deadlock_detected
+	return pqErr.Code == "40001" || pqErr.Code == "40P01"
+}
@@ -2,103 +2,189 @@ package posts
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
+	"unicode"
 
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/schema"
+	"posts/internal/entity"
+	"posts/internal/pkg/config"
+	"posts/internal/pkg/cursor"
 	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/slug"
 )
 
+// tagsColumn selects the sorted slugs of a post's tags into Post.Tags.
+const tagsColumn = "ARRAY(SELECT t.slug FROM post_tags AS pt JOIN tags AS t ON t.id = pt.tag_id " +
+	"WHERE pt.post_id = p.id ORDER BY t.slug) AS tags"
+
+// authorRelation joins the author of a listed post. Deleted authors are
+// not joined, so their stale data never shows.
+var authorRelation = bun.RelationOpts{
+	Apply: func(q *bun.SelectQuery) *bun.SelectQuery {
+		return q.Column("id", "firstname", "lastname", "username", "email", "phone", "gender", "role")
+	},
+	AdditionalJoinOnConditions: []schema.QueryWithArgs{
+		schema.SafeQuery(`"user"."deleted_at" IS NULL`, nil),
+	},
+}
+
 type Repository struct {
-	*sql.DB
+	db bun.IDB
 }
 
-func NewRepository(database *sql.DB) *Repository {
-	return &Repository{DB: database}
+func NewRepository(database bun.IDB) *Repository {
+	return &Repository{db: database}
 }
 
 func (r *Repository) Create(ctx context.Context, request *pb.PostCreateRequest) (*pb.PostCreateResponse, error) {
-	query := `INSERT INTO posts (
-								user_id, 
-								title, 
-								content) 
-	          VALUES ($1, $2, $3)`
-
-	_, err := r.DB.ExecContext(
-		ctx,
-		query,
-		request.UserId,
-		request.Title,
-		request.Content,
-	)
+	post := entity.PostFromCreateRequest(request)
+
+	_, err := r.db.NewInsert().
+		Model(post).
+		Column("user_id", "title", "content", "language", "status", "publish_at", "published_at").
+		Returning("id").
+		Exec(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to insert posts: %w", err)
 	}
 
-	response := pb.PostCreateResponse{
-		UserId:  request.UserId,
-		Title:   request.Title,
-		Content: request.Content,
-	}
-	return &response, nil
+	return post.ToCreateResponse(), nil
 }
 
+// GetDetail returns a live post; deleted posts are not found.
 func (r *Repository) GetDetail(ctx context.Context, request *pb.GetById) (*pb.PostGetResponse, error) {
-	query := `SELECT 
-					id, 
-					user_id, 
-					title, 
-					content, 
-					created_at
-	          FROM posts 
-			  WHERE id = $1`
-	row := r.DB.QueryRowContext(ctx, query, request.Id)
-
-	var post pb.PostGetResponse
-	err := row.Scan(
-		&post.Id,
-		&post.UserId,
-		&post.Title,
-		&post.Content,
-		&post.CreatedAt,
-	)
+	var post entity.Post
+
+	err := r.db.NewSelect().
+		Model(&post).
+		Column("id", "user_id", "title", "content", "created_at", "created_by", "comments_count", "reactions_count", "reaction_counts", "status", "publish_at", "published_at", "slug").
+		ColumnExpr(tagsColumn).
+		Where("id = ?", request.Id).
+		Where("deleted_at IS NULL").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return post.ToGetResponse(), nil
+}
+
+// GetBySlug returns the live post that has or had the given slug.
+func (r *Repository) GetBySlug(ctx context.Context, postSlug string) (*pb.PostGetResponse, error) {
+	var post entity.Post
+
+	err := r.db.NewSelect().
+		Model(&post).
+		Column("id", "user_id", "title", "content", "created_at", "created_by", "comments_count", "reactions_count", "reaction_counts", "status", "publish_at", "published_at", "slug").
+		ColumnExpr(tagsColumn).
+		Where("id = (SELECT ps.post_id FROM post_slugs AS ps WHERE ps.slug = ?)", postSlug).
+		Where("deleted_at IS NULL").
+		Scan(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	return &post, nil
+	return post.ToGetResponse(), nil
+}
+
+// TakenSlugs returns the slugs other posts have or had among base and its
+// numbered variants base-2, base-3, ...
+func (r *Repository) TakenSlugs(ctx context.Context, base string, postID int64) (map[string]bool, error) {
+	var slugs []string
+
+	err := r.db.NewSelect().
+		Table("post_slugs").
+		Column("slug").
+		WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("slug = ?", base).
+				WhereOr("slug LIKE ?", base+"-%")
+		}).
+		Where("post_id <> ?", postID).
+		Scan(ctx, &slugs)
+	if err != nil {
+		return nil, fmt.Errorf("querying slugs: %w", err)
+	}
+
+	taken := make(map[string]bool, len(slugs))
+	for _, s := range slugs {
+		taken[s] = true
+	}
+	return taken, nil
+}
+
+// SetSlug makes postSlug the post's current slug. Earlier slugs stay in
+// post_slugs so that old links keep resolving.
+func (r *Repository) SetSlug(ctx context.Context, postID int64, postSlug string) error {
+	_, err := r.db.NewRaw(
+		"INSERT INTO post_slugs (slug, post_id) VALUES (?, ?) ON CONFLICT (slug) DO NOTHING",
+		postSlug, postID,
+	).Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("saving slug: %w", err)
+	}
+
+	_, err = r.db.NewUpdate().
+		Table("posts").
+		Set("slug = ?", postSlug).
+		Where("id = ?", postID).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("updating post slug: %w", err)
+	}
+	return nil
 }
 
 func (r *Repository) Update(ctx context.Context, request *pb.PostUpdateRequest) (*pb.PostVoid, error) {
-	query := `UPDATE posts SET updated_at = NOW()`
-	var args []interface{}
-	var updates []string
+	query := r.db.NewUpdate().
+		Table("posts").
+		Set("updated_at = NOW()")
+	updates := 0
 
 	if request.UserId != 0 {
-		args = append(args, request.UserId)
-		updates = append(updates, fmt.Sprintf("user_id = $%d", len(args)))
+		query.Set("user_id = ?", request.UserId)
+		updates++
 	}
 
 	if request.Title != "" && request.Title != "string" {
-		args = append(args, request.Title)
-		updates = append(updates, fmt.Sprintf("title = $%d", len(args)))
+		query.Set("title = ?", request.Title)
+		updates++
 	}
 
 	if request.Content != "" && request.Content != "string" {
-		args = append(args, request.Content)
-		updates = append(updates, fmt.Sprintf("content = $%d", len(args)))
+		query.Set("content = ?", request.Content)
+		updates++
 	}
 
-	if len(updates) == 0 {
-		return nil, fmt.Errorf("no fields to update")
+	if request.Status != "" {
+		query.Set("status = ?", request.Status)
+		// keep the first publication time when a post is re-published
+		query.Set("published_at = CASE WHEN ? = 'published' THEN COALESCE(published_at, ?) ELSE published_at END",
+			request.Status, time.Now().UTC())
+		if request.Status != entity.PostScheduled {
+			query.Set("publish_at = NULL")
+		}
+		updates++
+	}
+
+	if request.PublishAt != "" {
+		query.Set("publish_at = ?", request.PublishAt)
+		updates++
 	}
 
-	query += ", " + strings.Join(updates, ", ")
+	// tags are saved by the tag repository, but still touch updated_at
+	if len(request.Tags) > 0 || request.ClearTags {
+		updates++
+	}
 
-	args = append(args, request.Id)
-	query += fmt.Sprintf(" WHERE id = $%d", len(args))
+	if updates == 0 {
+		return nil, fmt.Errorf("no fields to update")
+	}
 
-	_, err := r.DB.ExecContext(ctx, query, args...)
+	_, err := query.Where("id = ?", request.Id).Exec(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("updating post: %w", err)
 	}
@@ -106,109 +192,409 @@ func (r *Repository) Update(ctx context.Context, request *pb.PostUpdateRequest)
 }
 
 func (r *Repository) Delete(ctx context.Context, request *pb.GetById) (*pb.PostVoid, error) {
-	query := `update posts set deleted_at =Now() WHERE id = $1 `
-	_, err := r.DB.ExecContext(ctx, query, request.Id)
+	_, err := r.db.NewUpdate().
+		Table("posts").
+		Set("deleted_at = NOW()").
+		Where("id = ?", request.Id).
+		Exec(ctx)
 	return &pb.PostVoid{}, err
 }
 
+// PublishDue publishes up to limit scheduled posts whose publish time has
+// passed and returns them. Rows locked by a concurrent run are skipped, so
+// several replicas may run the scheduler at once.
+func (r *Repository) PublishDue(ctx context.Context, limit int) ([]*pb.PostPublished, error) {
+	due := r.db.NewSelect().
+		Table("posts").
+		Column("id").
+		Where("status = ?", entity.PostScheduled).
+		Where("publish_at <= NOW() AT TIME ZONE 'UTC'").
+		Where("deleted_at IS NULL").
+		OrderExpr("publish_at").
+		Limit(limit).
+		For("UPDATE SKIP LOCKED")
+
+	var posts []entity.Post
+	_, err := r.db.NewUpdate().
+		Table("posts").
+		Set("status = ?", entity.PostPublished).
+		Set("published_at = publish_at").
+		Set("updated_at = NOW()").
+		Where("id IN (?)", due).
+		Returning("id, user_id, title, published_at").
+		Exec(ctx, &posts)
+	if err != nil {
+		return nil, fmt.Errorf("publishing due posts: %w", err)
+	}
+
+	published := make([]*pb.PostPublished, 0, len(posts))
+	for i := range posts {
+		published = append(published, posts[i].ToPublished())
+	}
+	return published, nil
+}
+
+// AddComments adjusts the denormalized comment counter of a live post.
+func (r *Repository) AddComments(ctx context.Context, postID int64, delta int64) error {
+	res, err := r.db.NewUpdate().
+		Table("posts").
+		Set("comments_count = comments_count + ?", delta).
+		Where("id = ?", postID).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("updating comments count: %w", err)
+	}
+	return config.CheckRowsAffected(res, "post")
+}
+
+// AddReaction records a reaction and reports whether it was new.
+func (r *Repository) AddReaction(ctx context.Context, request *pb.PostReactionRequest) (bool, error) {
+	reaction := entity.Reaction{PostID: request.PostId, UserID: request.UserId, Kind: request.Kind}
+
+	res, err := r.db.NewInsert().
+		Model(&reaction).
+		Column("post_id", "user_id", "kind").
+		On("CONFLICT DO NOTHING").
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("adding reaction: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ReactionsByUser returns every reaction the user left, oldest first.
+func (r *Repository) ReactionsByUser(ctx context.Context, userID int64) ([]entity.Reaction, error) {
+	var reactions []entity.Reaction
+
+	err := r.db.NewSelect().
+		Model(&reactions).
+		Where("user_id = ?", userID).
+		Order("created_at", "post_id", "kind").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing reactions of user: %w", err)
+	}
+	return reactions, nil
+}
+
+// RemoveReaction deletes a reaction and reports whether it existed.
+func (r *Repository) RemoveReaction(ctx context.Context, request *pb.PostReactionRequest) (bool, error) {
+	res, err := r.db.NewDelete().
+		Model((*entity.Reaction)(nil)).
+		Where("post_id = ?", request.PostId).
+		Where("user_id = ?", request.UserId).
+		Where("kind = ?", request.Kind).
+		Exec(ctx)
+	if err != nil {
+		return false, fmt.Errorf("removing reaction: %w", err)
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// Reactions returns the aggregated reaction counts of a live post.
+func (r *Repository) Reactions(ctx context.Context, postID int64) (*pb.PostReactions, error) {
+	var post entity.Post
+
+	err := r.db.NewSelect().
+		Model(&post).
+		Column("id", "reactions_count", "reaction_counts").
+		Where("id = ?", postID).
+		Where("deleted_at IS NULL").
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return post.ToReactions(), nil
+}
+
+// RefreshReactions recomputes the denormalized reaction counts of a post from
+// the reactions table and returns them.
+func (r *Repository) RefreshReactions(ctx context.Context, postID int64) (*pb.PostReactions, error) {
+	post := entity.Post{BasicEntity: entity.BasicEntity{ID: postID}}
+
+	_, err := r.db.NewUpdate().
+		Model(&post).
+		Set("reactions_count = (SELECT COUNT(*) FROM reactions WHERE post_id = ?)", postID).
+		Set(`reaction_counts = COALESCE((SELECT jsonb_object_agg(k.kind, k.n)
+			FROM (SELECT kind, COUNT(*) AS n FROM reactions WHERE post_id = ? GROUP BY kind) k), '{}')`, postID).
+		Where("id = ?", postID).
+		Returning("reactions_count, reaction_counts").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("refreshing reaction counts: %w", err)
+	}
+	return post.ToReactions(), nil
+}
+
+// CountByUser returns how many live posts a user has authored.
+func (r *Repository) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	count, err := r.db.NewSelect().
+		Table("posts").
+		Where("user_id = ?", userID).
+		Where("deleted_at IS NULL").
+		Count(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("counting posts of user: %w", err)
+	}
+	return int64(count), nil
+}
+
+// DeleteByUser soft-deletes every live post of a user and returns how many
+// posts were affected.
+func (r *Repository) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	res, err := r.db.NewUpdate().
+		Table("posts").
+		Set("deleted_at = NOW()").
+		Where("user_id = ?", userID).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("deleting posts of user: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// AnonymizeByUser detaches a user's live posts from their author and returns
+// how many posts were affected.
+func (r *Repository) AnonymizeByUser(ctx context.Context, userID int64) (int64, error) {
+	res, err := r.db.NewUpdate().
+		Table("posts").
+		Set("user_id = NULL").
+		Set("updated_at = NOW()").
+		Where("user_id = ?", userID).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("anonymizing posts of user: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ListByUser returns every post a user has authored, including deleted ones.
+func (r *Repository) ListByUser(ctx context.Context, userID int64) ([]*pb.PostGetResponse, error) {
+	var posts []entity.Post
+
+	err := r.db.NewSelect().
+		Model(&posts).
+		Column("id", "user_id", "title", "content", "created_at", "created_by", "comments_count", "reactions_count", "reaction_counts", "status", "publish_at", "published_at", "slug").
+		ColumnExpr(tagsColumn).
+		Where("user_id = ?", userID).
+		Order("id").
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing posts of user: %w", err)
+	}
+
+	response := make([]*pb.PostGetResponse, 0, len(posts))
+	for i := range posts {
+		response = append(response, posts[i].ToGetResponse())
+	}
+	return response, nil
+}
+
 func (r *Repository) GetList(ctx context.Context, filter *pb.FilterPost) (*pb.PostGetAll, error) {
-	var args []interface{}
-	var conditions []string
-	argID := 1
+	var posts []entity.Post
+
+	query := r.db.NewSelect().
+		Model(&posts).
+		Column("p.id", "p.user_id", "p.title", "p.content", "p.created_at", "p.created_by", "p.comments_count", "p.reactions_count", "p.reaction_counts", "p.status", "p.publish_at", "p.published_at", "p.slug").
+		ColumnExpr(tagsColumn).
+		RelationWithOpts("User", authorRelation).
+		Where("p.deleted_at IS NULL")
 
-	conditions = append(conditions, "p.deleted_at IS NULL")
+	// drafts, scheduled and archived posts are only listed for their author
+	if filter.ViewerId != 0 {
+		query.WhereGroup(" AND ", func(q *bun.SelectQuery) *bun.SelectQuery {
+			return q.Where("p.status = ?", entity.PostPublished).
+				WhereOr("p.user_id = ?", filter.ViewerId)
+		})
+	} else {
+		query.Where("p.status = ?", entity.PostPublished)
+	}
+	if filter.Status != "" {
+		query.Where("p.status = ?", filter.Status)
+	}
 
 	if filter.UserId != 0 {
-		conditions = append(conditions, fmt.Sprintf("p.user_id = $%d", argID))
-		args = append(args, filter.UserId)
-		argID++
+		query.Where("p.user_id = ?", filter.UserId)
 	}
 	if filter.Content != "" {
-		conditions = append(conditions, fmt.Sprintf("p.content ILIKE $%d", argID))
-		args = append(args, "%"+filter.Content+"%")
-		argID++
+		query.Where("p.content ILIKE ?", "%"+filter.Content+"%")
 	}
-
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	if tags := slug.Unique(filter.TagsAny); len(tags) > 0 {
+		query.Where(`EXISTS (SELECT 1 FROM post_tags AS pt JOIN tags AS t ON t.id = pt.tag_id
+			WHERE pt.post_id = p.id AND t.slug IN (?))`, bun.In(tags))
+	}
+	if tags := slug.Unique(filter.TagsAll); len(tags) > 0 {
+		query.Where(`(SELECT COUNT(*) FROM post_tags AS pt JOIN tags AS t ON t.id = pt.tag_id
+			WHERE pt.post_id = p.id AND t.slug IN (?)) = ?`, bun.In(tags), len(tags))
 	}
 
-	var limit, offset int64
 	if filter.Limit > 0 {
-		limit = filter.Limit
+		query.Limit(int(filter.Limit))
 	}
 	if filter.Page > 0 && filter.Limit > 0 {
-		offset = (filter.Page - 1) * filter.Limit
-	}
-
-	query := fmt.Sprintf(`
-		SELECT 
-			p.id, 
-			p.user_id, 
-			p.title, 
-			p.content, 
-			u.id as user_id,
-			u.firstname as firstname,
-			u.lastname as lastname,
-			u.username as username,
-			u.email as email,
-			u.phone as phone,
-			u.gender,
-			u.role,
-			p.created_at 
-		FROM posts p 
-		LEFT JOIN users u ON u.id = p.user_id
-		%s 
-		ORDER BY p.created_at DESC 
-		LIMIT $%d OFFSET $%d`, whereClause, argID, argID+1)
-
-	args = append(args, limit, offset)
-
-	rows, err := r.DB.QueryContext(ctx, query, args...)
+		query.Offset(int((filter.Page - 1) * filter.Limit))
+	}
+
+	switch filter.SortBy {
+	case "", "latest":
+		query.Order("p.created_at DESC")
+	case "popular":
+		query.Order("p.reactions_count DESC", "p.created_at DESC")
+	default:
+		return nil, fmt.Errorf("unknown sort %q, expected latest or popular", filter.SortBy)
+	}
+
+	count, err := query.ScanAndCount(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("querying posts: %w", err)
 	}
-	defer rows.Close()
-
-	var response []*pb.PostGet
-	for rows.Next() {
-		var post pb.PostGet
-		post.User = &pb.User{}
-		err := rows.Scan(
-			&post.Id,
-			&post.UserId,
-			&post.Title,
-			&post.Content,
-			&post.User.Id,
-			&post.User.FirstName,
-			&post.User.LastName,
-			&post.User.Username,
-			&post.User.Email,
-			&post.User.PhoneNumber,
-			&post.User.Gender,
-			&post.User.Role,
-			&post.CreatedAt,
-		)
-		if err != nil {
-			return nil, err
-		}
-		response = append(response, &post)
+
+	response := make([]*pb.PostGet, 0, len(posts))
+	for i := range posts {
+		response = append(response, posts[i].ToPostGet())
 	}
 
-	countQuery := `SELECT COUNT(p.id) FROM posts p`
-	if whereClause != "" {
-		countQuery += " " + whereClause
+	return &pb.PostGetAll{
+		Post:  response,
+		Count: int32(count),
+	}, nil
+}
+
+// Search matches published posts against the query. Every post is searched
+// with its own language, the configuration its search_vector was built
+// with; a requested language only keeps posts written in it.
+func (r *Repository) Search(ctx context.Context, request *pb.PostSearchRequest) (*pb.PostSearchResponse, error) {
+	tsQuery, queryText, err := searchQuery(request.Mode, request.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []entity.PostSearchResult
+
+	query := r.db.NewSelect().
+		Model(&results).
+		TableExpr("LATERAL (SELECT ? AS query) AS q", bun.SafeQuery(tsQuery+"(p.language, ?)", queryText)).
+		ColumnExpr("COUNT(p.id) OVER () AS total_count").
+		Column("p.id", "p.user_id", "p.title", "p.content", "p.created_at").
+		ColumnExpr("ts_headline(p.language, p.title, q.query, 'HighlightAll=true, StartSel=<mark>, StopSel=</mark>') AS title_highlight").
+		ColumnExpr("ts_headline(p.language, p.content, q.query, 'MaxFragments=2, MaxWords=30, MinWords=10, StartSel=<mark>, StopSel=</mark>') AS content_highlight").
+		ColumnExpr("ts_rank_cd(p.search_vector, q.query) AS rank").
+		Where("p.deleted_at IS NULL").
+		Where("p.status = ?", entity.PostPublished).
+		Where("p.search_vector @@ q.query")
+
+	if request.Language != "" {
+		query.Where("p.language = ?::regconfig", request.Language)
+	}
+	if request.UserId != 0 {
+		query.Where("p.user_id = ?", request.UserId)
 	}
 
-	var totalCount int32
-	err = r.DB.QueryRow(countQuery, args[:len(args)-2]...).Scan(&totalCount)
+	limit := 10
+	if request.Limit > 0 {
+		limit = int(request.Limit)
+	}
+	query.Limit(limit)
+	if request.Page > 0 {
+		query.Offset(int(request.Page-1) * limit)
+	}
+
+	err = query.OrderExpr("rank DESC, p.created_at DESC").Scan(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("counting posts: %w", err)
+		return nil, fmt.Errorf("searching posts: %w", err)
 	}
 
-	return &pb.PostGetAll{
+	response := make([]*pb.PostSearchResult, 0, len(results))
+	var count int32
+	for i := range results {
+		count = results[i].TotalCount
+		response = append(response, results[i].ToProto())
+	}
+
+	return &pb.PostSearchResponse{
 		Post:  response,
-		Count: totalCount,
+		Count: count,
 	}, nil
 }
+
+// searchQuery picks the tsquery constructor for the requested mode and
+// prepares the user input for it. Prefix mode builds the tsquery text itself
+// so that every term matches as a prefix ("go rout" -> "go:* & rout:*").
+func searchQuery(mode, text string) (string, string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", "", errors.New("search query is empty")
+	}
+
+	switch strings.ToLower(mode) {
+	case "", "websearch":
+		return "websearch_to_tsquery", text, nil
+	case "plain":
+		return "plainto_tsquery", text, nil
+	case "phrase":
+		return "phraseto_tsquery", text, nil
+	case "prefix":
+		terms := strings.FieldsFunc(text, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(terms) == 0 {
+			return "", "", errors.New("search query has no searchable terms")
+		}
+		for i, term := range terms {
+			terms[i] = term + ":*"
+		}
+		return "to_tsquery", strings.Join(terms, " & "), nil
+	default:
+		return "", "", fmt.Errorf("unknown search mode %q", mode)
+	}
+}
+
+// Feed returns up to limit published posts by the authors followerID
+// follows, newest first, starting after the given position. Posts are read
+// per author through posts_feed_idx rather than from a stored timeline.
+func (r *Repository) Feed(ctx context.Context, followerID int64, limit int, after *cursor.Position) (*pb.FeedPage, error) {
+	var posts []entity.Post
+
+	query := r.db.NewSelect().
+		Model(&posts).
+		Column("p.id", "p.user_id", "p.title", "p.content", "p.created_at", "p.created_by", "p.comments_count", "p.reactions_count", "p.reaction_counts", "p.status", "p.publish_at", "p.published_at", "p.slug").
+		ColumnExpr(tagsColumn).
+		RelationWithOpts("User", authorRelation).
+		Where("p.user_id IN (SELECT f.followee_id FROM follows AS f WHERE f.follower_id = ?)", followerID).
+		Where("p.status = ?", entity.PostPublished).
+		Where("p.deleted_at IS NULL")
+	if after != nil {
+		query.Where("(p.published_at, p.id) < (?, ?)", after.Time, after.ID)
+	}
+
+	// one extra row tells whether another page follows
+	err := query.
+		Order("p.published_at DESC", "p.id DESC").
+		Limit(limit + 1).
+		Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying feed: %w", err)
+	}
+
+	page := &pb.FeedPage{}
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[limit-1]
+		position := cursor.Position{ID: last.ID}
+		if last.PublishedAt != nil {
+			position.Time = *last.PublishedAt
+		}
+		page.NextCursor = cursor.Encode(position)
+	}
+
+	page.Post = make([]*pb.PostGet, 0, len(posts))
+	for i := range posts {
+		page.Post = append(page.Post, posts[i].ToPostGet())
+	}
+	return page, nil
+}
@@ -0,0 +1,83 @@
+package revisions
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/uptrace/bun"
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+)
+
+type Repository struct {
+	db bun.IDB
+}
+
+func NewRepository(database bun.IDB) *Repository {
+	return &Repository{db: database}
+}
+
+// Snapshot stores the current title and content of a live post as its next
+// revision and returns the revision number. It returns sql.ErrNoRows when
+// the post does not exist.
+func (r *Repository) Snapshot(ctx context.Context, postID int64) (int64, error) {
+	var revision int64
+
+	err := r.db.NewRaw(`INSERT INTO post_revisions (post_id, revision, title, content)
+		SELECT p.id, COALESCE((SELECT MAX(pr.revision) FROM post_revisions AS pr WHERE pr.post_id = p.id), 0) + 1, p.title, p.content
+		FROM posts AS p
+		WHERE p.id = ? AND p.deleted_at IS NULL
+		RETURNING revision`, postID).
+		Scan(ctx, &revision)
+	if err != nil {
+		return 0, fmt.Errorf("snapshotting post: %w", err)
+	}
+	return revision, nil
+}
+
+func (r *Repository) Get(ctx context.Context, request *pb.RevisionRequest) (*pb.PostRevision, error) {
+	var revision entity.PostRevision
+
+	err := r.db.NewSelect().
+		Model(&revision).
+		Column("post_id", "revision", "title", "content", "created_at").
+		Where("post_id = ?", request.PostId).
+		Where("revision = ?", request.Revision).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return revision.ToProto(), nil
+}
+
+// List returns a post's revisions, newest first.
+func (r *Repository) List(ctx context.Context, request *pb.RevisionListRequest) (*pb.RevisionList, error) {
+	var revisions []entity.PostRevision
+
+	query := r.db.NewSelect().
+		Model(&revisions).
+		Column("post_id", "revision", "title", "content", "created_at").
+		Where("post_id = ?", request.PostId)
+
+	if request.Limit > 0 {
+		query.Limit(int(request.Limit))
+	}
+	if request.Page > 0 && request.Limit > 0 {
+		query.Offset(int((request.Page - 1) * request.Limit))
+	}
+
+	count, err := query.Order("revision DESC").ScanAndCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying revisions: %w", err)
+	}
+
+	response := make([]*pb.PostRevision, 0, len(revisions))
+	for i := range revisions {
+		response = append(response, revisions[i].ToProto())
+	}
+
+	return &pb.RevisionList{
+		Revision: response,
+		Count:    int32(count),
+	}, nil
+}
@@ -0,0 +1,170 @@
+package tags
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/uptrace/bun"
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/slug"
+)
+
+type Repository struct {
+	db bun.IDB
+}
+
+func NewRepository(database bun.IDB) *Repository {
+	return &Repository{db: database}
+}
+
+// SetForPost replaces the tags of a post, creating missing tags on the way,
+// and returns the slugs the post ends up with. Names that normalize to the
+// same slug are merged; the first spelling becomes the tag name.
+func (r *Repository) SetForPost(ctx context.Context, postID int64, names []string) ([]string, error) {
+	_, err := r.db.NewDelete().
+		Model((*entity.PostTag)(nil)).
+		Where("post_id = ?", postID).
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("clearing post tags: %w", err)
+	}
+
+	slugs := slug.Unique(names)
+	if len(slugs) == 0 {
+		return []string{}, nil
+	}
+
+	spelling := make(map[string]string, len(slugs))
+	for _, name := range names {
+		if s := slug.Make(name); spelling[s] == "" {
+			spelling[s] = strings.TrimSpace(name)
+		}
+	}
+	tags := make([]entity.Tag, len(slugs))
+	for i, s := range slugs {
+		tags[i] = entity.Tag{Slug: s, Name: spelling[s]}
+	}
+
+	// DO UPDATE instead of DO NOTHING so existing tags return their id too
+	_, err = r.db.NewInsert().
+		Model(&tags).
+		Column("slug", "name").
+		On("CONFLICT (slug) DO UPDATE").
+		Set("slug = EXCLUDED.slug").
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("saving tags: %w", err)
+	}
+
+	links := make([]entity.PostTag, 0, len(tags))
+	for _, tag := range tags {
+		links = append(links, entity.PostTag{PostID: postID, TagID: tag.ID})
+	}
+
+	_, err = r.db.NewInsert().
+		Model(&links).
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("linking tags to post: %w", err)
+	}
+
+	return slugs, nil
+}
+
+// List returns tags used by live posts, most used first.
+func (r *Repository) List(ctx context.Context, request *pb.TagListRequest) (*pb.TagList, error) {
+	var tags []entity.Tag
+
+	query := r.db.NewSelect().
+		Model(&tags).
+		Column("t.slug", "t.name").
+		ColumnExpr("COUNT(*) AS posts_count").
+		ColumnExpr("COUNT(*) OVER () AS total_count").
+		Join("JOIN post_tags AS pt ON pt.tag_id = t.id").
+		Join("JOIN posts AS p ON p.id = pt.post_id AND p.deleted_at IS NULL").
+		Group("t.id")
+
+	if prefix := slug.Make(request.Prefix); prefix != "" {
+		query.Where("t.slug LIKE ?", prefix+"%")
+	}
+
+	limit := 50
+	if request.Limit > 0 {
+		limit = int(request.Limit)
+	}
+	query.Limit(limit)
+	if request.Page > 0 {
+		query.Offset(int(request.Page-1) * limit)
+	}
+
+	err := query.OrderExpr("posts_count DESC, t.slug ASC").Scan(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing tags: %w", err)
+	}
+
+	response := make([]*pb.Tag, 0, len(tags))
+	var count int32
+	for i := range tags {
+		count = tags[i].TotalCount
+		response = append(response, tags[i].ToProto())
+	}
+
+	return &pb.TagList{
+		Tag:   response,
+		Count: count,
+	}, nil
+}
+
+// IDsForPost returns the ids of the tags of a post.
+func (r *Repository) IDsForPost(ctx context.Context, postID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.NewSelect().
+		Model((*entity.PostTag)(nil)).
+		Column("tag_id").
+		Where("post_id = ?", postID).
+		Scan(ctx, &ids)
+	if err != nil {
+		return nil, fmt.Errorf("reading post tags: %w", err)
+	}
+	return ids, nil
+}
+
+// IDsForUser returns the ids of the tags of a user's posts.
+func (r *Repository) IDsForUser(ctx context.Context, userID int64) ([]int64, error) {
+	var ids []int64
+	err := r.db.NewSelect().
+		Model((*entity.PostTag)(nil)).
+		Distinct().
+		Column("pt.tag_id").
+		Join("JOIN posts AS p ON p.id = pt.post_id").
+		Where("p.user_id = ?", userID).
+		Scan(ctx, &ids)
+	if err != nil {
+		return nil, fmt.Errorf("reading user's post tags: %w", err)
+	}
+	return ids, nil
+}
+
+// DeleteUnused removes those of the given tags that no live post uses any
+// more. Callers pass the tags a post just dropped, so that other tags are
+// not scanned.
+func (r *Repository) DeleteUnused(ctx context.Context, tagIDs []int64) (int64, error) {
+	if len(tagIDs) == 0 {
+		return 0, nil
+	}
+
+	res, err := r.db.NewDelete().
+		Model((*entity.Tag)(nil)).
+		Where("t.id IN (?)", bun.In(tagIDs)).
+		Where(`NOT EXISTS (SELECT 1 FROM post_tags AS pt
+			JOIN posts AS p ON p.id = pt.post_id AND p.deleted_at IS NULL
+			WHERE pt.tag_id = t.id)`).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("deleting unused tags: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -5,18 +5,29 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
+
+	"github.com/uptrace/bun"
 	"golang.org/x/crypto/bcrypt"
+	"posts/internal/entity"
 	"posts/internal/pkg/config"
 	pb "posts/internal/pkg/genproto"
-	"strings"
 )
 
+// userColumns are the columns returned to callers; password is never selected
+// outside of Login.
+var userColumns = []string{"id", "firstname", "lastname", "username", "email", "phone", "gender", "role", "created_at", "created_by"}
+
+// defaultRole is the role of users without extra permissions.
+const defaultRole = "VIEWER"
+
 type Repository struct {
-	*sql.DB
+	db bun.IDB
 }
 
-func NewRepository(database *sql.DB) *Repository {
-	return &Repository{DB: database}
+func NewRepository(database bun.IDB) *Repository {
+	return &Repository{db: database}
 }
 
 func (u *Repository) Create(ctx context.Context, request *pb.UserCreateRequest) (*pb.UserCreateResponse, error) {
@@ -40,148 +51,122 @@ func (u *Repository) Create(ctx context.Context, request *pb.UserCreateRequest)
 		gen = strings.ToUpper(request.Gender)
 	}
 
-	query := `
-			INSERT INTO users (
-				firstname, 
-				lastname, 
-				username, 
-				email, 
-				phone, 
-				gender, 
-				password, 
-				role
-			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		`
-
-	_, err = u.DB.ExecContext(
-		ctx,
-		query,
-		request.FirstName,
-		request.LastName,
-		request.Username,
-		request.Email,
-		request.PhoneNumber,
-		gen,
-		hashedPassword,
-		request.Role,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to insert user: %w", err)
+	user := entity.UserFromCreateRequest(request)
+	user.Password = &hashedPassword
+	user.Role = &role
+	if gen != "" {
+		user.Gender = &gen
 	}
 
-	response := pb.UserCreateResponse{
-		FirstName:   request.FirstName,
-		LastName:    request.LastName,
-		Username:    request.Username,
-		Gender:      request.Gender,
-		PhoneNumber: request.PhoneNumber,
-		Email:       request.Email,
-		Password:    hashedPassword,
-		Role:        request.Role,
+	_, err = u.db.NewInsert().
+		Model(user).
+		Column("firstname", "lastname", "username", "email", "phone", "gender", "password", "role").
+		Returning("id").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert user: %w", err)
 	}
 
-	return &response, nil
+	return user.ToCreateResponse(), nil
 }
 
+// GetDetail returns a live user; deleted and erased users are not found.
 func (u *Repository) GetDetail(ctx context.Context, request *pb.ById) (*pb.UserGetResponse, error) {
-	query := `
-		SELECT 
-		     id,
-		     firstname, 
-		     lastname, 
-		     username, 
-		     email, 
-		     phone, 
-		     gender, 
-		     role,
-		     created_at
-		FROM users 
-			WHERE id = $1
-	`
-	var user pb.UserGetResponse
-
-	err := u.DB.QueryRowContext(ctx, query, request.Id).Scan(
-		&user.Id,
-		&user.FirstName,
-		&user.LastName,
-		&user.Username,
-		&user.Email,
-		&user.PhoneNumber,
-		&user.Gender,
-		&user.Role,
-		&user.CreatedAt,
-	)
+	var user entity.User
+
+	err := u.db.NewSelect().
+		Model(&user).
+		Column(userColumns...).
+		Where("id = ?", request.Id).
+		Where("deleted_at IS NULL").
+		Scan(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &user, nil
+	return user.ToGetResponse(), nil
 
 }
 
-func (u *Repository) Update(ctx context.Context, request *pb.UserUpdateRequest) (*pb.Void, error) {
+// Profile returns a user whether or not they are deleted, for exports of
+// the data still held about deleted users.
+func (u *Repository) Profile(ctx context.Context, userID int64) (*pb.UserGetResponse, error) {
+	var user entity.User
 
-	query := `UPDATE users SET updated_at = NOW()`
+	err := u.db.NewSelect().
+		Model(&user).
+		Column(userColumns...).
+		Where("id = ?", userID).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return user.ToGetResponse(), nil
+}
+
+// IDByEmail returns the id of the live user with the given email.
+func (u *Repository) IDByEmail(ctx context.Context, email string) (int64, error) {
+	var id int64
+
+	err := u.db.NewSelect().
+		Table("users").
+		Column("id").
+		Where("email = ?", email).
+		Where("deleted_at IS NULL").
+		Scan(ctx, &id)
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
 
-	var arg []interface{}
-	var conditions []string
+func (u *Repository) Update(ctx context.Context, request *pb.UserUpdateRequest) (*pb.Void, error) {
+	query := u.db.NewUpdate().
+		Table("users").
+		Set("updated_at = NOW()")
 
 	if request.FirstName != "" && request.FirstName != "string" {
-		arg = append(arg, request.FirstName)
-		conditions = append(conditions, fmt.Sprintf("firstname = $%d", len(arg)))
+		query.Set("firstname = ?", request.FirstName)
 	}
 
 	if request.LastName != "" && request.LastName != "string" {
-		arg = append(arg, request.LastName)
-		conditions = append(conditions, fmt.Sprintf("lastname = $%d", len(arg)))
+		query.Set("lastname = ?", request.LastName)
 	}
 
 	if request.Username != "" && request.Username != "string" {
-		arg = append(arg, request.Username)
-		conditions = append(conditions, fmt.Sprintf("username = $%d", len(arg)))
+		query.Set("username = ?", request.Username)
 	}
 
 	if request.PhoneNumber != "" && request.PhoneNumber != "string" {
-		arg = append(arg, request.PhoneNumber)
-		conditions = append(conditions, fmt.Sprintf("phone = $%d", len(arg)))
+		query.Set("phone = ?", request.PhoneNumber)
 	}
 
 	if request.Email != "" && request.Email != "string" {
-		arg = append(arg, request.Email)
-		conditions = append(conditions, fmt.Sprintf("email = $%d", len(arg)))
+		query.Set("email = ?", request.Email)
 	}
 
 	if request.Gender != "" && request.Gender != "string" {
-		arg = append(arg, request.Gender)
-		conditions = append(conditions, fmt.Sprintf("gender = $%d", len(arg)))
+		query.Set("gender = ?", request.Gender)
 	}
 
 	if request.Role != "" && request.Role != "string" {
-		arg = append(arg, request.Role)
-		conditions = append(conditions, fmt.Sprintf("role = $%d", len(arg)))
-	}
-
-	if len(conditions) > 0 {
-		query += ", " + strings.Join(conditions, ", ")
+		query.Set("role = ?", request.Role)
 	}
 
-	query += fmt.Sprintf(" WHERE id = $%d", len(arg)+1)
-	arg = append(arg, request.Id)
-
-	_, err := u.DB.ExecContext(ctx, query, arg...)
+	_, err := query.Where("id = ?", request.Id).Exec(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return nil, nil
+	return &pb.Void{}, nil
 }
 
 func (u *Repository) ChangeUserPassword(ctx context.Context, request *pb.UserRecoverPasswordRequest) (*pb.Void, error) {
-	query := `
-		UPDATE users 
-		SET password = $1, updated_at = NOW()
-		WHERE email = $2 AND deleted_at IS NULL
-	`
-
-	_, err := u.DB.ExecContext(ctx, query, request.NewPassword, request.Email)
+	_, err := u.db.NewUpdate().
+		Table("users").
+		Set("password = ?", request.NewPassword).
+		Set("updated_at = NOW()").
+		Where("email = ?", request.Email).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("error updating password: %w", err)
 	}
@@ -190,150 +175,125 @@ func (u *Repository) ChangeUserPassword(ctx context.Context, request *pb.UserRec
 }
 
 func (u *Repository) Delete(ctx context.Context, request *pb.ById) (*pb.Void, error) {
-	query := `
-		UPDATE users 
-		SET deleted_at = NOW() 
-		WHERE id = $1
-	`
-	_, err := u.DB.ExecContext(ctx, query, request.Id)
+	res, err := u.db.NewUpdate().
+		Table("users").
+		Set("deleted_at = NOW()").
+		Where("id = ?", request.Id).
+		Where("deleted_at IS NULL").
+		Exec(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return nil, nil
+	if err := config.CheckRowsAffected(res, "user"); err != nil {
+		return nil, err
+	}
+	return &pb.Void{}, nil
 }
 
 func (u *Repository) GetList(ctx context.Context, filter *pb.FilterUser) (*pb.UserGetAll, error) {
-	var args []interface{}
-	var conditions []string
-	argID := 1
+	var users []entity.User
 
-	conditions = append(conditions, "deleted_at IS NULL")
+	query := u.db.NewSelect().
+		Model(&users).
+		Column(userColumns...).
+		Where("deleted_at IS NULL")
 
 	if filter.Role != "" {
-		conditions = append(conditions, fmt.Sprintf("role = $%d", argID))
-		args = append(args, filter.Role)
-		argID++
+		query.Where("role = ?", filter.Role)
 	}
 	if filter.Username != "" {
-		conditions = append(conditions, fmt.Sprintf("username = $%d", argID))
-		args = append(args, filter.Username)
-		argID++
+		query.Where("username = ?", filter.Username)
 	}
 	if filter.Firstname != "" {
-		conditions = append(conditions, fmt.Sprintf("firstname = $%d", argID))
-		args = append(args, filter.Firstname)
-		argID++
+		query.Where("firstname = ?", filter.Firstname)
 	}
 	if filter.Lastname != "" {
-		conditions = append(conditions, fmt.Sprintf("lastname = $%d", argID))
-		args = append(args, filter.Lastname)
-		argID++
+		query.Where("lastname = ?", filter.Lastname)
 	}
 	if filter.Gender != "" {
-		conditions = append(conditions, fmt.Sprintf("gender = $%d", argID))
-		args = append(args, filter.Gender)
-		argID++
+		query.Where("gender = ?", filter.Gender)
 	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
-	}
-
-	var limit, offset int64
-
 	if filter.Limit > 0 {
-		limit = filter.Limit
+		query.Limit(int(filter.Limit))
 	}
 	if filter.Page > 0 && filter.Limit > 0 {
-		offset = (filter.Page - 1) * filter.Limit
-	}
-
-	query := fmt.Sprintf(`
-							SELECT 
-								id, 
-								firstname,
-								lastname,
-								username,
-								email,
-								phone,
-								gender,
-								role 
-							FROM users %s 
-							ORDER BY created_at DESC 
-							LIMIT $%d OFFSET $%d`, whereClause, argID, argID+1)
-
-	args = append(args, limit, offset)
-
-	rows, err := u.DB.QueryContext(ctx, query, args...)
+		query.Offset(int((filter.Page - 1) * filter.Limit))
+	}
+
+	count, err := query.Order("created_at DESC").ScanAndCount(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("querying users: %w", err)
 	}
-	defer rows.Close()
-
-	var response []*pb.UserGetList
-	for rows.Next() {
-		var user pb.UserGetList
-		err := rows.Scan(
-			&user.Id,
-			&user.FirstName,
-			&user.LastName,
-			&user.Username,
-			&user.Email,
-			&user.PhoneNumber,
-			&user.Gender,
-			&user.Role,
-		)
-		if err != nil {
-			return nil, err
-		}
-		response = append(response, &user)
-	}
-	countArgs := args[:len(args)-2]
-	countQuery := `SELECT COUNT(*) FROM users`
-	if whereClause != "" {
-		countQuery += " " + whereClause
-	}
 
-	var totalCount int32
-	if len(countArgs) > 0 {
-		err := u.DB.QueryRow(countQuery, countArgs...).Scan(&totalCount)
-		if err != nil {
-			return nil, fmt.Errorf("counting users: %w", err)
-		}
-	} else {
-		err := u.DB.QueryRow(countQuery).Scan(&totalCount)
-		if err != nil {
-			return nil, fmt.Errorf("counting users: %w", err)
-		}
+	response := make([]*pb.UserGetList, 0, len(users))
+	for i := range users {
+		response = append(response, users[i].ToGetList())
 	}
 
 	return &pb.UserGetAll{
 		User:  response,
-		Count: totalCount,
+		Count: int32(count),
 	}, nil
 }
 
 func (u *Repository) Login(ctx context.Context, request *pb.LoginRequest) (*pb.LoginResponse, error) {
-
-	query := `
-		SELECT id, email, password
-		FROM users WHERE email = $1 AND deleted_at is null
-	`
-
-	var user pb.LoginResponse
-	var hashedPassword string
-
-	err := u.DB.QueryRowContext(ctx, query, request.Email).Scan(
-		&user.Id, &user.Email, &hashedPassword,
-	)
+	var user entity.User
+
+	err := u.db.NewSelect().
+		Model(&user).
+		Column("id", "email", "password").
+		Where("email = ?", request.Email).
+		Where("deleted_at IS NULL").
+		Scan(ctx)
 	if err != nil {
 		return nil, errors.New("user not found")
 	}
 
-	if !config.CheckPasswordHash(request.Password, hashedPassword) {
+	if user.Password == nil || !config.CheckPasswordHash(request.Password, *user.Password) {
 		return nil, errors.New("invalid password")
 	}
 
-	return &user, nil
+	return &pb.LoginResponse{
+		Id:    user.ID,
+		Email: *user.Email,
+	}, nil
+}
+
+// Erase irreversibly replaces the user's personal data and drops any role
+// beyond the default one. The row itself is kept (and marked deleted) so
+// that posts and logs still reference it. It returns sql.ErrNoRows for
+// unknown and already erased users.
+func (u *Repository) Erase(ctx context.Context, request *pb.ById) (*pb.UserEraseResponse, error) {
+	var user entity.User
+
+	_, err := u.db.NewUpdate().
+		Model(&user).
+		Set("firstname = NULL").
+		Set("lastname = NULL").
+		Set("username = NULL").
+		Set("phone = NULL").
+		Set("gender = NULL").
+		Set("email = 'erased-' || id || '@erased.invalid'").
+		// "!" is never produced by bcrypt, so no password matches it
+		Set("password = '!'").
+		Set("role = ?", defaultRole).
+		Set("erased_at = NOW()").
+		Set("updated_at = NOW()").
+		Set("deleted_at = COALESCE(deleted_at, NOW())").
+		Where("id = ?", request.Id).
+		Where("erased_at IS NULL").
+		Returning("erased_at").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("erasing user: %w", err)
+	}
+	if user.ErasedAt == nil {
+		return nil, sql.ErrNoRows
+	}
+
+	return &pb.UserEraseResponse{
+		Id:       request.Id,
+		ErasedAt: user.ErasedAt.Format(time.RFC3339),
+	}, nil
 }
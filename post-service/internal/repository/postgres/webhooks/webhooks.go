@@ -0,0 +1,254 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"posts/internal/entity"
+	"posts/internal/pkg/config"
+	pb "posts/internal/pkg/genproto"
+)
+
+type Repository struct {
+	db bun.IDB
+}
+
+func NewRepository(database bun.IDB) *Repository {
+	return &Repository{db: database}
+}
+
+// Create stores a webhook and returns it together with its secret.
+func (r *Repository) Create(ctx context.Context, request *pb.WebhookCreateRequest) (*pb.Webhook, error) {
+	webhook := entity.WebhookFromCreateRequest(request)
+
+	_, err := r.db.NewInsert().
+		Model(webhook).
+		Column("url", "events", "secret", "active", "created_by").
+		Returning("id, created_at").
+		Exec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert webhook: %w", err)
+	}
+
+	response := webhook.ToProto()
+	response.Secret = webhook.Secret
+	return response, nil
+}
+
+func (r *Repository) GetDetail(ctx context.Context, id int64) (*entity.Webhook, error) {
+	var webhook entity.Webhook
+
+	err := r.db.NewSelect().
+		Model(&webhook).
+		Where("id = ?", id).
+		Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *Repository) GetList(ctx context.Context, request *pb.WebhookListRequest) (*pb.WebhookList, error) {
+	var webhooks []entity.Webhook
+
+	query := r.db.NewSelect().Model(&webhooks)
+	if request.Limit > 0 {
+		query.Limit(int(request.Limit))
+	}
+	if request.Page > 0 && request.Limit > 0 {
+		query.Offset(int((request.Page - 1) * request.Limit))
+	}
+
+	count, err := query.Order("id").ScanAndCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying webhooks: %w", err)
+	}
+
+	response := make([]*pb.Webhook, 0, len(webhooks))
+	for i := range webhooks {
+		response = append(response, webhooks[i].ToProto())
+	}
+	return &pb.WebhookList{Webhook: response, Count: int32(count)}, nil
+}
+
+// Update changes the fields set in the request. Activating a webhook
+// clears its failure count.
+func (r *Repository) Update(ctx context.Context, request *pb.WebhookUpdateRequest) (*pb.Webhook, error) {
+	var webhook entity.Webhook
+
+	query := r.db.NewUpdate().
+		Model(&webhook).
+		Set("updated_at = NOW()").
+		Where("id = ?", request.Id)
+	if request.Url != "" {
+		query.Set("url = ?", request.Url)
+	}
+	if len(request.Events) > 0 {
+		query.Set("events = ?", pgdialect.Array(request.Events))
+	}
+	if request.Secret != "" {
+		query.Set("secret = ?", request.Secret)
+	}
+	if request.Active != nil {
+		query.Set("active = ?", *request.Active).
+			Set("failure_count = 0").
+			Set("disabled_at = NULL")
+	}
+
+	err := query.Returning("*").Scan(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return webhook.ToProto(), nil
+}
+
+func (r *Repository) Delete(ctx context.Context, id int64) error {
+	res, err := r.db.NewDelete().
+		Model((*entity.Webhook)(nil)).
+		Where("id = ?", id).
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("deleting webhook: %w", err)
+	}
+	return config.CheckRowsAffected(res, "webhook")
+}
+
+// Enqueue adds a pending delivery of payload to every active webhook
+// subscribed to event and returns how many were added.
+func (r *Repository) Enqueue(ctx context.Context, event, payload string) (int64, error) {
+	res, err := r.db.NewRaw(`INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT w.id, ?, ? FROM webhooks AS w
+		WHERE w.active AND ? = ANY(w.events)`, event, payload, event).
+		Exec(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("enqueueing webhook deliveries: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// Claim returns up to limit due deliveries of active webhooks and moves
+// their next attempt lease into the future, so that concurrent workers
+// skip them while they are being sent.
+func (r *Repository) Claim(ctx context.Context, limit int, lease time.Duration) ([]entity.WebhookDelivery, error) {
+	var deliveries []entity.WebhookDelivery
+
+	err := r.db.NewRaw(`WITH due AS (
+			SELECT wd.id FROM webhook_deliveries AS wd
+			JOIN webhooks AS w ON w.id = wd.webhook_id
+			WHERE wd.status = ? AND wd.next_attempt_at <= NOW() AND w.active
+			ORDER BY wd.next_attempt_at
+			LIMIT ?
+			FOR UPDATE OF wd SKIP LOCKED
+		)
+		UPDATE webhook_deliveries AS wd
+		SET next_attempt_at = NOW() + ? * INTERVAL '1 second'
+		FROM due WHERE wd.id = due.id
+		RETURNING wd.*`, entity.DeliveryPending, limit, int64(lease/time.Second)).
+		Scan(ctx, &deliveries)
+	if err != nil {
+		return nil, fmt.Errorf("claiming webhook deliveries: %w", err)
+	}
+	return deliveries, nil
+}
+
+// SaveAttempt stores the outcome of a delivery attempt.
+func (r *Repository) SaveAttempt(ctx context.Context, delivery *entity.WebhookDelivery) error {
+	_, err := r.db.NewUpdate().
+		Model(delivery).
+		Column("status", "attempts", "next_attempt_at", "response_code", "error", "delivered_at").
+		WherePK().
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("saving webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// Succeeded clears a webhook's failure count.
+func (r *Repository) Succeeded(ctx context.Context, webhookID int64) error {
+	_, err := r.db.NewUpdate().
+		Model((*entity.Webhook)(nil)).
+		Set("failure_count = 0").
+		Where("id = ?", webhookID).
+		Where("failure_count > 0").
+		Exec(ctx)
+	if err != nil {
+		return fmt.Errorf("resetting webhook failures: %w", err)
+	}
+	return nil
+}
+
+// Failed counts a failed attempt against a webhook and disables it once
+// disableAfter attempts in a row have failed. It reports whether the
+// webhook was disabled by this call.
+func (r *Repository) Failed(ctx context.Context, webhookID int64, disableAfter int) (bool, error) {
+	var active bool
+
+	err := r.db.NewUpdate().
+		Model((*entity.Webhook)(nil)).
+		Set("failure_count = failure_count + 1").
+		Set("active = failure_count + 1 < ?", disableAfter).
+		Set("disabled_at = CASE WHEN failure_count + 1 < ? THEN disabled_at ELSE NOW() END", disableAfter).
+		Where("id = ?", webhookID).
+		Where("active").
+		Returning("active").
+		Scan(ctx, &active)
+	if errors.Is(err, sql.ErrNoRows) {
+		// disabled or deleted meanwhile
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("counting webhook failure: %w", err)
+	}
+	return !active, nil
+}
+
+// Deliveries returns a webhook's delivery log, newest first.
+func (r *Repository) Deliveries(ctx context.Context, request *pb.WebhookDeliveryListRequest) (*pb.WebhookDeliveryList, error) {
+	var deliveries []entity.WebhookDelivery
+
+	query := r.db.NewSelect().
+		Model(&deliveries).
+		Where("webhook_id = ?", request.WebhookId)
+	if request.Status != "" {
+		query.Where("status = ?", request.Status)
+	}
+	if request.Limit > 0 {
+		query.Limit(int(request.Limit))
+	}
+	if request.Page > 0 && request.Limit > 0 {
+		query.Offset(int((request.Page - 1) * request.Limit))
+	}
+
+	count, err := query.Order("created_at DESC", "id DESC").ScanAndCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("querying webhook deliveries: %w", err)
+	}
+
+	response := make([]*pb.WebhookDelivery, 0, len(deliveries))
+	for i := range deliveries {
+		response = append(response, deliveries[i].ToProto())
+	}
+	return &pb.WebhookDeliveryList{Delivery: response, Count: int32(count)}, nil
+}
+
+// Redeliver queues a new delivery with the payload of an earlier one of the
+// same webhook. The earlier delivery stays in the log unchanged.
+func (r *Repository) Redeliver(ctx context.Context, request *pb.WebhookDeliveryRequest) (*pb.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+
+	err := r.db.NewRaw(`INSERT INTO webhook_deliveries (webhook_id, event, payload, redelivery_of)
+		SELECT webhook_id, event, payload, id FROM webhook_deliveries
+		WHERE id = ? AND webhook_id = ?
+		RETURNING *`, request.Id, request.WebhookId).
+		Scan(ctx, &delivery)
+	if err != nil {
+		return nil, err
+	}
+	return delivery.ToProto(), nil
+}
@@ -0,0 +1,226 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/entity"
+	"posts/internal/pkg/blob"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/repository/postgres/attachments"
+	"posts/internal/usecase/service"
+)
+
+func TestCreateAttachment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := attachments.NewRepository(bun.NewDB(db, pgdialect.New()))
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "attachments" ("post_id", "user_id", "storage_key", "file_name", "content_type", "size", "checksum", "created_by") VALUES (7, 3, 'attachments/7/abc', 'cat.png', 'image/png', 1024, 'deadbeef', 3) RETURNING id, created_at`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(11, created))
+
+	attachment, err := repo.Create(context.Background(), &pb.Attachment{
+		PostId:      7,
+		UserId:      3,
+		FileName:    "cat.png",
+		ContentType: "image/png",
+		Size:        1024,
+		Checksum:    "deadbeef",
+	}, "attachments/7/abc")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), attachment.Id)
+	assert.Equal(t, "cat.png", attachment.FileName)
+	assert.NotEmpty(t, attachment.CreatedAt)
+}
+
+func TestGetAttachmentList(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := attachments.NewRepository(bun.NewDB(db, pgdialect.New()))
+	columns := []string{"id", "post_id", "user_id", "storage_key", "file_name", "content_type", "size", "checksum", "created_at"}
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "a"."id", "a"."post_id", "a"."user_id", "a"."storage_key", "a"."file_name", "a"."content_type", "a"."size", "a"."checksum", "a"."created_at" FROM "attachments" AS "a" WHERE (post_id = 7) AND (deleted_at IS NULL) ORDER BY "id"`)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(11, 7, 3, "attachments/7/abc", "cat.png", "image/png", 1024, "deadbeef", created).
+			AddRow(12, 7, 3, "attachments/7/def", "notes.txt", "text/plain", 12, "cafebabe", created))
+
+	resp, err := repo.GetList(context.Background(), &pb.AttachmentListRequest{PostId: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.Count)
+	assert.Equal(t, "notes.txt", resp.Attachment[1].FileName)
+}
+
+func TestDeleteAttachment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := attachments.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "attachments" SET deleted_at = NOW(), deleted_by = 3 WHERE (id = 11) AND (deleted_at IS NULL)`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = repo.Delete(context.Background(), &pb.AttachmentDeleteRequest{Id: 11, UserId: 3})
+	assert.NoError(t, err)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "attachments"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	_, err = repo.Delete(context.Background(), &pb.AttachmentDeleteRequest{Id: 11, UserId: 3})
+	assert.Error(t, err)
+}
+
+func TestAttachmentGarbage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := attachments.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "a"."id", "a"."storage_key" FROM "attachments" AS "a" JOIN posts AS p ON p.id = a.post_id WHERE (a.deleted_at IS NOT NULL) OR (p.deleted_at IS NOT NULL) ORDER BY "a"."id" LIMIT 100`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "storage_key"}).AddRow(11, "attachments/7/abc"))
+	garbage, err := repo.Garbage(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.Len(t, garbage, 1)
+	assert.Equal(t, "attachments/7/abc", *garbage[0].StorageKey)
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "attachments" AS "a" WHERE (id IN (11))`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.Purge(context.Background(), []int64{11}))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "a"."storage_key" FROM "attachments" AS "a" WHERE (storage_key IN ('attachments/7/abc', 'attachments/7/zzz'))`)).
+		WillReturnRows(sqlmock.NewRows([]string{"storage_key"}).AddRow("attachments/7/abc"))
+	known, err := repo.KnownKeys(context.Background(), []string{"attachments/7/abc", "attachments/7/zzz"})
+	assert.NoError(t, err)
+	assert.True(t, known["attachments/7/abc"])
+	assert.False(t, known["attachments/7/zzz"])
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLocalBlobStore(t *testing.T) {
+	store, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	ctx := context.Background()
+
+	err = store.Put(ctx, "attachments/7/abc", strings.NewReader("hello"), 5, "text/plain")
+	assert.NoError(t, err)
+
+	r, err := store.Get(ctx, "attachments/7/abc")
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "hello", string(data))
+
+	var keys []string
+	err = store.List(ctx, "attachments/", func(info blob.Info) error {
+		keys = append(keys, info.Key)
+		assert.Equal(t, int64(5), info.Size)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"attachments/7/abc"}, keys)
+
+	assert.NoError(t, store.Delete(ctx, "attachments/7/abc"))
+	assert.NoError(t, store.Delete(ctx, "attachments/7/abc"))
+	_, err = store.Get(ctx, "attachments/7/abc")
+	assert.True(t, errors.Is(err, blob.ErrNotFound))
+
+	err = store.Put(ctx, "../escape", bytes.NewReader(nil), 0, "text/plain")
+	assert.Error(t, err)
+}
+
+func TestAttachmentsOfDraftOnlyForAuthor(t *testing.T) {
+	postID := int64(7)
+	f := &fakeStorage{
+		posts:       fakePost{post: &pb.PostGetResponse{Id: postID, UserId: 3, Status: entity.PostDraft}},
+		attachments: fakeAttachments{byID: map[int64]*entity.Attachment{11: {BasicEntity: entity.BasicEntity{ID: 11}, PostID: &postID}}},
+	}
+	s := service.NewAttachmentService(f, nil, service.AttachmentOptions{URLSecret: "secret"})
+	ctx := context.Background()
+
+	for _, viewer := range []int64{0, 4} {
+		_, err := s.Sign(ctx, &pb.AttachmentSignRequest{Id: 11, ViewerId: viewer})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		_, err = s.GetList(ctx, &pb.AttachmentListRequest{PostId: postID, ViewerId: viewer})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	}
+
+	signature, err := s.Sign(ctx, &pb.AttachmentSignRequest{Id: 11, ViewerId: 3})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, signature.Signature)
+
+	list, err := s.GetList(ctx, &pb.AttachmentListRequest{PostId: postID, ViewerId: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), list.Count)
+
+	_, err = s.Sign(ctx, &pb.AttachmentSignRequest{Id: 12, ViewerId: 3})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestAttachmentsOfPublishedPostForEveryone(t *testing.T) {
+	postID := int64(7)
+	f := &fakeStorage{
+		posts:       fakePost{post: &pb.PostGetResponse{Id: postID, UserId: 3, Status: entity.PostPublished}},
+		attachments: fakeAttachments{byID: map[int64]*entity.Attachment{11: {BasicEntity: entity.BasicEntity{ID: 11}, PostID: &postID}}},
+	}
+	s := service.NewAttachmentService(f, nil, service.AttachmentOptions{URLSecret: "secret"})
+
+	_, err := s.Sign(context.Background(), &pb.AttachmentSignRequest{Id: 11})
+	assert.NoError(t, err)
+}
+
+// uploadStream sends a single chunk.
+type uploadStream struct {
+	grpc.ServerStream
+	chunk *pb.AttachmentUploadChunk
+}
+
+func (s *uploadStream) Context() context.Context { return context.Background() }
+func (s *uploadStream) Recv() (*pb.AttachmentUploadChunk, error) {
+	if s.chunk == nil {
+		return nil, io.EOF
+	}
+	chunk := s.chunk
+	s.chunk = nil
+	return chunk, nil
+}
+func (s *uploadStream) SendAndClose(*pb.Attachment) error { return nil }
+
+func TestUploadToDeletedPostIsRefused(t *testing.T) {
+	// deleted posts are not found by GetDetail
+	f := &fakeStorage{posts: fakePost{post: &pb.PostGetResponse{Id: 7, UserId: 3}}}
+	s := service.NewAttachmentService(f, nil, service.AttachmentOptions{URLSecret: "secret"})
+
+	err := s.Upload(&uploadStream{chunk: &pb.AttachmentUploadChunk{
+		Meta: &pb.AttachmentMeta{PostId: 8, UserId: 3, FileName: "cat.png", ContentType: "image/png", Size: 4},
+	}})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
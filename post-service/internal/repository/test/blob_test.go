@@ -0,0 +1,246 @@
+package test
+
+import (
+	"bufio"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"posts/internal/pkg/blob"
+)
+
+func TestLocalBlobStoreRejectsShortUpload(t *testing.T) {
+	root := t.TempDir()
+	store, err := blob.NewLocal(root)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	ctx := context.Background()
+
+	err = store.Put(ctx, "attachments/7/abc", strings.NewReader("hel"), 5, "text/plain")
+	assert.Error(t, err)
+
+	// neither the blob nor its temporary file is left behind
+	_, err = store.Get(ctx, "attachments/7/abc")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+	entries, err := os.ReadDir(filepath.Join(root, "attachments", "7"))
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestLocalBlobStoreListsPrefixOnly(t *testing.T) {
+	root := t.TempDir()
+	store, err := blob.NewLocal(root)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, key := range []string{"attachments/7/abc", "attachments/8/def", "other/xyz"} {
+		assert.NoError(t, store.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"))
+	}
+	// an upload in progress is not a blob yet
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "attachments", "7", ".upload-123"), nil, 0o644))
+
+	var keys []string
+	err = store.List(ctx, "attachments/", func(info blob.Info) error {
+		keys = append(keys, info.Key)
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"attachments/7/abc", "attachments/8/def"}, keys)
+
+	stop := errors.New("stop")
+	err = store.List(ctx, "", func(info blob.Info) error { return stop })
+	assert.ErrorIs(t, err, stop)
+}
+
+func TestS3BlobStore(t *testing.T) {
+	server := httptest.NewServer(newFakeS3())
+	defer server.Close()
+	ctx := context.Background()
+
+	store, err := blob.NewS3(ctx, blob.S3Config{
+		Endpoint:  strings.TrimPrefix(server.URL, "http://"),
+		Region:    "us-east-1",
+		Bucket:    "attachments",
+		AccessKey: "key",
+		SecretKey: "secret",
+	})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	assert.NoError(t, store.Put(ctx, "attachments/7/abc", strings.NewReader("hello"), 5, "text/plain"))
+	assert.NoError(t, store.Put(ctx, "other/xyz", strings.NewReader("x"), 1, "text/plain"))
+
+	r, err := store.Get(ctx, "attachments/7/abc")
+	assert.NoError(t, err)
+	data, _ := io.ReadAll(r)
+	r.Close()
+	assert.Equal(t, "hello", string(data))
+
+	var listed []blob.Info
+	err = store.List(ctx, "attachments/", func(info blob.Info) error {
+		listed = append(listed, info)
+		return nil
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, listed, 1) {
+		assert.Equal(t, "attachments/7/abc", listed[0].Key)
+		assert.Equal(t, int64(5), listed[0].Size)
+	}
+
+	assert.NoError(t, store.Delete(ctx, "attachments/7/abc"))
+	_, err = store.Get(ctx, "attachments/7/abc")
+	assert.ErrorIs(t, err, blob.ErrNotFound)
+}
+
+// fakeS3 is an in-memory S3 endpoint that understands just the requests
+// blob.S3 makes. Signatures are not checked.
+type fakeS3 struct {
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{buckets: map[string]map[string][]byte{}}
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objects, exists := s.buckets[bucket]
+
+	if key == "" {
+		switch {
+		case r.Method == http.MethodPut:
+			s.buckets[bucket] = map[string][]byte{}
+		case !exists:
+			s3Error(w, r, http.StatusNotFound, "NoSuchBucket")
+		case r.Method == http.MethodGet:
+			s.list(w, objects, r.URL.Query().Get("prefix"))
+		}
+		return
+	}
+	if !exists {
+		s3Error(w, r, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		data, err := readS3Body(r)
+		if err != nil {
+			s3Error(w, r, http.StatusBadRequest, "IncompleteBody")
+			return
+		}
+		objects[key] = data
+		w.Header().Set("ETag", `"etag"`)
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodHead, http.MethodGet:
+		data, ok := objects[key]
+		if !ok {
+			s3Error(w, r, http.StatusNotFound, "NoSuchKey")
+			return
+		}
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+		w.Header().Set("Last-Modified", time.Now().UTC().Format(http.TimeFormat))
+		if r.Method == http.MethodGet {
+			w.Write(data)
+		}
+	}
+}
+
+func (s *fakeS3) list(w http.ResponseWriter, objects map[string][]byte, prefix string) {
+	type content struct {
+		Key          string
+		Size         int64
+		LastModified string
+		ETag         string
+	}
+	result := struct {
+		XMLName  xml.Name `xml:"ListBucketResult"`
+		Prefix   string
+		KeyCount int
+		Contents []content
+	}{Prefix: prefix}
+
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result.Contents = append(result.Contents, content{
+			Key:          key,
+			Size:         int64(len(objects[key])),
+			LastModified: time.Now().UTC().Format(time.RFC3339),
+			ETag:         `"etag"`,
+		})
+	}
+	result.KeyCount = len(keys)
+
+	w.Header().Set("Content-Type", "application/xml")
+	xml.NewEncoder(w).Encode(result)
+}
+
+// readS3Body reads an upload, undoing the aws-chunked encoding clients use
+// for signed streaming uploads over plain HTTP.
+func readS3Body(r *http.Request) ([]byte, error) {
+	if !strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+		return io.ReadAll(r.Body)
+	}
+
+	var data []byte
+	body := bufio.NewReader(r.Body)
+	for {
+		header, err := body.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		sizeHex, _, _ := strings.Cut(strings.TrimSpace(header), ";")
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+		if size == 0 {
+			return data, nil
+		}
+		chunk := make([]byte, size+2) // data and its trailing CRLF
+		if _, err := io.ReadFull(body, chunk); err != nil {
+			return nil, err
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+func s3Error(w http.ResponseWriter, r *http.Request, code int, s3Code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(code)
+	if r.Method != http.MethodHead {
+		xml.NewEncoder(w).Encode(struct {
+			XMLName xml.Name `xml:"Error"`
+			Code    string
+		}{Code: s3Code})
+	}
+}
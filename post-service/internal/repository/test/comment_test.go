@@ -0,0 +1,114 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/entity"
+	"posts/internal/pkg/config"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/repository/postgres/comments"
+	"posts/internal/usecase/service"
+)
+
+func TestCreateComment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := comments.NewRepository(bun.NewDB(db, pgdialect.New()))
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "comments" ("post_id", "parent_id", "user_id", "content", "created_by") VALUES (3, 9, 2, 'Nice post', 2) RETURNING id, created_at`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(10, created))
+
+	resp, err := repo.Create(context.Background(), &pb.CommentCreateRequest{PostId: 3, ParentId: 9, UserId: 2, Content: "Nice post"})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), resp.Id)
+	assert.Equal(t, int64(9), resp.ParentId)
+	assert.Equal(t, created.Format(time.RFC3339), resp.CreatedAt)
+}
+
+func TestGetCommentListTopLevel(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	repo := comments.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectQuery(`AS replies_count.*LEFT JOIN "users" AS "user".*WHERE \(c.post_id = 3\) AND \(c.deleted_at IS NULL\) AND \(c.parent_id IS NULL\) ORDER BY "c"."created_at" ASC, "c"."id" ASC LIMIT 20`).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "post_id", "parent_id", "user_id", "content", "created_at", "updated_at", "replies_count",
+			"user__id", "user__firstname", "user__lastname", "user__username",
+		}).AddRow(10, 3, nil, 2, "Nice post", nil, nil, 4, 2, "John", "Doe", "johndoe"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "comments" AS "c"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	resp, err := repo.GetList(context.Background(), &pb.FilterComment{PostId: 3, Limit: 20})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.Count)
+	assert.Len(t, resp.Comment, 1)
+	assert.Equal(t, int64(4), resp.Comment[0].RepliesCount)
+	assert.Equal(t, "johndoe", resp.Comment[0].User.Username)
+}
+
+func TestDeleteCommentNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := comments.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "comments" SET deleted_at = NOW(), deleted_by = 2 WHERE (id = 10) AND (deleted_at IS NULL)`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	_, err = repo.Delete(context.Background(), &pb.CommentDeleteRequest{Id: 10, UserId: 2})
+	assert.EqualError(t, err, "comment not found")
+}
+
+func TestCreateCommentKeepsRetryableErrors(t *testing.T) {
+	serialization := &pq.Error{Code: "40001"}
+	post := fakePost{post: &pb.PostGetResponse{Id: 3, UserId: 5, Status: entity.PostPublished}}
+	comments := service.NewCommentService(&fakeStorage{posts: fakeCommentedPosts{fakePost: post, err: fmt.Errorf("updating comments count: %w", serialization)}}, nil)
+
+	_, err := comments.Create(context.Background(), &pb.CommentCreateRequest{PostId: 3, UserId: 2, Content: "Nice post"})
+	var pqErr *pq.Error
+	assert.ErrorAs(t, err, &pqErr)
+	assert.NotEqual(t, codes.NotFound, status.Code(err))
+
+	comments = service.NewCommentService(&fakeStorage{posts: fakeCommentedPosts{fakePost: post, err: config.CheckRowsAffected(sqlmock.NewResult(0, 0), "post")}}, nil)
+	_, err = comments.Create(context.Background(), &pb.CommentCreateRequest{PostId: 3, UserId: 2, Content: "Nice post"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestCommentsOfDraftOnlyForAuthor(t *testing.T) {
+	post := fakePost{post: &pb.PostGetResponse{Id: 3, UserId: 5, Status: entity.PostDraft}}
+	comments := service.NewCommentService(&fakeStorage{posts: fakeCommentedPosts{fakePost: post}}, nil)
+	ctx := context.Background()
+
+	_, err := comments.Create(ctx, &pb.CommentCreateRequest{PostId: 3, UserId: 2, Content: "Nice post"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	for _, viewer := range []int64{0, 2} {
+		_, err = comments.GetList(ctx, &pb.FilterComment{PostId: 3, ViewerId: viewer})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	}
+	_, err = comments.GetList(ctx, &pb.FilterComment{PostId: 4, ViewerId: 5})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
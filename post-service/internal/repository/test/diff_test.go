@@ -0,0 +1,56 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"posts/internal/pkg/diff"
+)
+
+func TestDiffLines(t *testing.T) {
+	got, err := diff.Lines("a\nb\nc\nd\n", "a\nc\nx\nd")
+	assert.NoError(t, err)
+	assert.Equal(t, "  a\n- b\n  c\n+ x\n  d\n", got)
+}
+
+func TestDiffLinesEqualAndEmpty(t *testing.T) {
+	got, err := diff.Lines("same\ntext", "same\ntext")
+	assert.NoError(t, err)
+	assert.Equal(t, "  same\n  text\n", got)
+
+	got, err = diff.Lines("", "new")
+	assert.NoError(t, err)
+	assert.Equal(t, "+ new\n", got)
+
+	got, err = diff.Lines("old", "")
+	assert.NoError(t, err)
+	assert.Equal(t, "- old\n", got)
+
+	got, err = diff.Lines("", "")
+	assert.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestDiffLinesRepeatedLines(t *testing.T) {
+	// the shared prefix and suffix must not overlap
+	got, err := diff.Lines("x\nx", "x\nx\nx")
+	assert.NoError(t, err)
+	assert.Equal(t, "  x\n  x\n+ x\n", got)
+}
+
+func TestDiffLinesTooLarge(t *testing.T) {
+	var a, b strings.Builder
+	for i := 0; i <= diff.MaxLines; i++ {
+		a.WriteString("a\n")
+		b.WriteString("b\n")
+	}
+
+	_, err := diff.Lines(a.String(), b.String())
+	assert.ErrorIs(t, err, diff.ErrTooLarge)
+
+	// a long text with a small change only diffs the change
+	got, err := diff.Lines(a.String()+"tail", a.String()+"end")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(got, "- tail\n+ end\n"))
+}
@@ -0,0 +1,188 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/repository"
+)
+
+// fakeStorage is a repository.StorageI whose transactions run inline.
+// Only the repositories a test sets can be used; the others panic.
+type fakeStorage struct {
+	repository.StorageI
+	users         repository.UserI
+	posts         repository.PostI
+	logs          repository.LogI
+	tags          repository.TagI
+	comments      repository.CommentI
+	revisions     repository.RevisionI
+	attachments   repository.AttachmentI
+	notifications repository.NotificationI
+}
+
+func (s *fakeStorage) User() repository.UserI { return s.users }
+func (s *fakeStorage) Post() repository.PostI { return s.posts }
+func (s *fakeStorage) Log() repository.LogI   { return s.logs }
+func (s *fakeStorage) Tag() repository.TagI   { return s.tags }
+func (s *fakeStorage) Comment() repository.CommentI {
+	return s.comments
+}
+func (s *fakeStorage) Revision() repository.RevisionI {
+	return s.revisions
+}
+func (s *fakeStorage) Attachment() repository.AttachmentI {
+	return s.attachments
+}
+func (s *fakeStorage) Notification() repository.NotificationI {
+	return s.notifications
+}
+
+func (s *fakeStorage) WithTx(ctx context.Context, fn func(repository.StorageI) error) error {
+	return fn(s)
+}
+
+// owned holds a user's rows in a fake repository and records what
+// happened to them.
+type owned struct {
+	count      int64
+	deleted    bool
+	anonymized bool
+}
+
+func (o *owned) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	return o.count, nil
+}
+
+func (o *owned) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	o.deleted = true
+	return o.count, nil
+}
+
+func (o *owned) AnonymizeByUser(ctx context.Context, userID int64) (int64, error) {
+	o.anonymized = true
+	return o.count, nil
+}
+
+type fakePosts struct {
+	repository.PostI
+	*owned
+}
+
+func (p fakePosts) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	return p.owned.CountByUser(ctx, userID)
+}
+
+func (p fakePosts) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	return p.owned.DeleteByUser(ctx, userID)
+}
+
+func (p fakePosts) AnonymizeByUser(ctx context.Context, userID int64) (int64, error) {
+	return p.owned.AnonymizeByUser(ctx, userID)
+}
+
+type fakeLogs struct {
+	repository.LogI
+	*owned
+}
+
+func (l fakeLogs) CountByUser(ctx context.Context, userID int64) (int64, error) {
+	return l.owned.CountByUser(ctx, userID)
+}
+
+func (l fakeLogs) DeleteByUser(ctx context.Context, userID int64) (int64, error) {
+	return l.owned.DeleteByUser(ctx, userID)
+}
+
+func (l fakeLogs) AnonymizeByUser(ctx context.Context, userID int64) (int64, error) {
+	return l.owned.AnonymizeByUser(ctx, userID)
+}
+
+type fakeUsers struct {
+	repository.UserI
+	deleted []int64
+}
+
+func (u *fakeUsers) Delete(ctx context.Context, id *pb.ById) (*pb.Void, error) {
+	u.deleted = append(u.deleted, id.Id)
+	return &pb.Void{}, nil
+}
+
+type fakeTags struct {
+	repository.TagI
+	userTags []int64
+	cleaned  []int64
+}
+
+func (t *fakeTags) IDsForUser(ctx context.Context, userID int64) ([]int64, error) {
+	return t.userTags, nil
+}
+
+func (t *fakeTags) DeleteUnused(ctx context.Context, tagIDs []int64) (int64, error) {
+	t.cleaned = append(t.cleaned, tagIDs...)
+	return int64(len(tagIDs)), nil
+}
+
+// fakeCommentedPosts serves a single post and fails AddComments with err.
+type fakeCommentedPosts struct {
+	fakePost
+	err error
+}
+
+func (p fakeCommentedPosts) AddComments(ctx context.Context, postID int64, delta int64) error {
+	return p.err
+}
+
+// fakePost serves a single post.
+type fakePost struct {
+	repository.PostI
+	post *pb.PostGetResponse
+}
+
+func (p fakePost) GetDetail(ctx context.Context, request *pb.GetById) (*pb.PostGetResponse, error) {
+	if request.Id != p.post.Id {
+		return nil, sql.ErrNoRows
+	}
+	return p.post, nil
+}
+
+// fakeRevisions serves the same revision for every revision number.
+type fakeRevisions struct {
+	repository.RevisionI
+	revision *pb.PostRevision
+}
+
+func (r fakeRevisions) List(ctx context.Context, request *pb.RevisionListRequest) (*pb.RevisionList, error) {
+	return &pb.RevisionList{Revision: []*pb.PostRevision{r.revision}, Count: 1}, nil
+}
+
+func (r fakeRevisions) Get(ctx context.Context, request *pb.RevisionRequest) (*pb.PostRevision, error) {
+	return r.revision, nil
+}
+
+// fakeAttachments holds attachments by id.
+type fakeAttachments struct {
+	repository.AttachmentI
+	byID map[int64]*entity.Attachment
+}
+
+func (a fakeAttachments) GetDetail(ctx context.Context, id int64) (*entity.Attachment, error) {
+	attachment, ok := a.byID[id]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return attachment, nil
+}
+
+func (a fakeAttachments) GetList(ctx context.Context, request *pb.AttachmentListRequest) (*pb.AttachmentList, error) {
+	list := &pb.AttachmentList{}
+	for _, attachment := range a.byID {
+		if *attachment.PostID == request.PostId {
+			list.Attachment = append(list.Attachment, attachment.ToProto())
+		}
+	}
+	list.Count = int32(len(list.Attachment))
+	return list, nil
+}
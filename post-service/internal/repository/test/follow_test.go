@@ -0,0 +1,88 @@
+package test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"posts/internal/pkg/cursor"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/repository/postgres/follows"
+)
+
+func TestFollowUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := follows.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "follows" AS "f" ("follower_id", "followee_id") VALUES (1, 2) ON CONFLICT DO NOTHING`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	followed, err := repo.Follow(context.Background(), &pb.FollowRequest{FollowerId: 1, FolloweeId: 2})
+	assert.NoError(t, err)
+	assert.True(t, followed)
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "follows" AS "f" WHERE (follower_id = 1) AND (followee_id = 2)`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	_, err = repo.Unfollow(context.Background(), &pb.FollowRequest{FollowerId: 1, FolloweeId: 2})
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListFollowers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	repo := follows.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "u"."id", "u"."firstname", "u"."lastname", "u"."username", "u"."email", "u"."phone", "u"."gender", "u"."role", "u"."created_at" FROM "users" AS "u" JOIN follows AS f ON f.follower_id = u.id WHERE (f.followee_id = 2) AND (u.deleted_at IS NULL) ORDER BY "f"."created_at" DESC, "u"."id" LIMIT 10`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "alice").AddRow(3, "carol"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "users" AS "u" JOIN follows AS f ON f.follower_id = u.id WHERE (f.followee_id = 2) AND (u.deleted_at IS NULL)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(2))
+
+	resp, err := repo.Followers(context.Background(), &pb.FollowListRequest{UserId: 2, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.Count)
+	assert.Equal(t, "carol", resp.User[1].Username)
+}
+
+func TestFeed(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+
+	published := time.Date(2024, 3, 7, 12, 0, 0, 123456000, time.UTC)
+	after := &cursor.Position{Time: published.Add(time.Hour), ID: 9}
+	columns := []string{"id", "user_id", "title", "status", "published_at", "user__id", "user__username"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "posts" AS "p" LEFT JOIN "users" AS "user" ON ("user"."id" = "p"."user_id") AND "user"."deleted_at" IS NULL WHERE (p.user_id IN (SELECT f.followee_id FROM follows AS f WHERE f.follower_id = 1)) AND (p.status = 'published') AND (p.deleted_at IS NULL) AND ((p.published_at, p.id) < ('2024-03-07 13:00:00.123456+00:00', 9)) ORDER BY "p"."published_at" DESC, "p"."id" DESC LIMIT 3`)).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(8, 2, "Eight", "published", published, 2, "bob").
+			AddRow(7, 3, "Seven", "published", published, 3, "carol").
+			AddRow(6, 2, "Six", "published", published, 2, "bob"))
+
+	page, err := repo.Feed(context.Background(), 1, 2, after)
+	assert.NoError(t, err)
+	assert.Len(t, page.Post, 2)
+	assert.Equal(t, "carol", page.Post[1].User.Username)
+
+	next, err := cursor.Decode(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), next.ID)
+	assert.True(t, published.Equal(next.Time))
+
+	_, err = cursor.Decode("not a cursor")
+	assert.ErrorIs(t, err, cursor.ErrInvalid)
+}
@@ -0,0 +1,75 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+	"posts/internal/pkg/health"
+)
+
+func TestReadyReportsEachCheck(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	mock.ExpectPing()
+
+	checks := health.Checker{
+		"postgres": health.DB(db),
+		"kafka":    func(ctx context.Context) error { return errors.New("connection refused") },
+	}
+
+	rec := httptest.NewRecorder()
+	health.Ready(checks).ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, 503, rec.Code)
+
+	var report health.Report
+	assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.Equal(t, health.StatusFail, report.Status)
+	assert.Equal(t, health.StatusOK, report.Checks["postgres"].Status)
+	assert.Equal(t, health.StatusFail, report.Checks["kafka"].Status)
+	assert.Equal(t, "connection refused", report.Checks["kafka"].Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	delete(checks, "kafka")
+	mock.ExpectPing()
+	rec = httptest.NewRecorder()
+	health.Ready(checks).ServeHTTP(rec, httptest.NewRequest("GET", "/readyz", nil))
+	assert.Equal(t, 200, rec.Code)
+}
+
+func TestGRPCHealthCheck(t *testing.T) {
+	lis := bufconn.Listen(1 << 16)
+	server := grpc.NewServer()
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	go server.Serve(lis)
+	defer server.Stop()
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("failed to dial: %v", err)
+	}
+	defer conn.Close()
+	check := health.GRPC(healthpb.NewHealthClient(conn), "")
+
+	assert.NoError(t, check(context.Background()))
+
+	healthServer.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	assert.EqualError(t, check(context.Background()), "status NOT_SERVING")
+}
@@ -0,0 +1,129 @@
+package test
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"posts/internal/entity"
+	"posts/internal/pkg/blob"
+	"posts/internal/repository"
+	"posts/internal/usecase/service"
+)
+
+// fakePartitions records what happens to log partitions, in order.
+type fakePartitions struct {
+	repository.LogI
+	existing []entity.LogPartition
+	logs     map[string][]*entity.Log
+	calls    []string
+}
+
+func (p *fakePartitions) CreatePartition(ctx context.Context, part entity.LogPartition) error {
+	p.calls = append(p.calls, "create "+part.Name)
+	return nil
+}
+
+func (p *fakePartitions) Partitions(ctx context.Context) ([]entity.LogPartition, error) {
+	return p.existing, nil
+}
+
+func (p *fakePartitions) ExportPartition(ctx context.Context, part entity.LogPartition, fn func(*entity.Log) error) error {
+	p.calls = append(p.calls, "export "+part.Name)
+	for _, log := range p.logs[part.Name] {
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *fakePartitions) DropPartition(ctx context.Context, part entity.LogPartition) error {
+	p.calls = append(p.calls, "drop "+part.Name)
+	return nil
+}
+
+// failingStore fails every upload.
+type failingStore struct {
+	blob.Store
+	err error
+}
+
+func (s failingStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	return s.err
+}
+
+// monthsAgo returns the partition of the month n months before now.
+func monthsAgo(n int) entity.LogPartition {
+	now := time.Now().UTC()
+	return entity.LogPartitionFor(time.Date(now.Year(), now.Month()-time.Month(n), 1, 0, 0, 0, 0, time.UTC))
+}
+
+func TestEnforceLogRetention(t *testing.T) {
+	message := "disk full"
+	logs := &fakePartitions{
+		existing: []entity.LogPartition{monthsAgo(12), monthsAgo(9), monthsAgo(4), monthsAgo(0)},
+		logs:     map[string][]*entity.Log{monthsAgo(12).Name: {{Message: &message}}},
+	}
+	archive, err := blob.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	ctx := context.Background()
+
+	// months 12 and 9 ago ended more than 180 days ago, month 4 did not
+	retention := service.NewLogRetention(&fakeStorage{logs: logs}, service.LogRetentionOptions{
+		Retention: 180 * 24 * time.Hour,
+		Archive:   archive,
+	})
+	dropped, err := retention.Enforce(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, dropped)
+	assert.Equal(t, []string{
+		"create " + monthsAgo(0).Name, "create " + monthsAgo(-1).Name, "create " + monthsAgo(-2).Name,
+		"export " + monthsAgo(12).Name, "drop " + monthsAgo(12).Name,
+		"export " + monthsAgo(9).Name, "drop " + monthsAgo(9).Name,
+	}, logs.calls)
+
+	r, err := archive.Get(ctx, "log-archive/"+monthsAgo(12).Name+".ndjson.gz")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer r.Close()
+	zr, err := gzip.NewReader(r)
+	assert.NoError(t, err)
+	var archived entity.Log
+	assert.NoError(t, json.NewDecoder(zr).Decode(&archived))
+	assert.Equal(t, "disk full", *archived.Message)
+}
+
+func TestEnforceLogRetentionStopsWhenArchivingFails(t *testing.T) {
+	logs := &fakePartitions{existing: []entity.LogPartition{monthsAgo(12), monthsAgo(9)}}
+	retention := service.NewLogRetention(&fakeStorage{logs: logs}, service.LogRetentionOptions{
+		Retention: 180 * 24 * time.Hour,
+		Archive:   failingStore{err: errors.New("bucket unreachable")},
+	})
+
+	dropped, err := retention.Enforce(context.Background())
+	assert.Error(t, err)
+	assert.Zero(t, dropped)
+	assert.NotContains(t, logs.calls, "drop "+monthsAgo(12).Name)
+	assert.NotContains(t, logs.calls, "export "+monthsAgo(9).Name)
+}
+
+func TestEnforceLogRetentionKeepsLogsWithoutRetention(t *testing.T) {
+	logs := &fakePartitions{existing: []entity.LogPartition{monthsAgo(12)}}
+	retention := service.NewLogRetention(&fakeStorage{logs: logs}, service.LogRetentionOptions{})
+
+	dropped, err := retention.Enforce(context.Background())
+	assert.NoError(t, err)
+	assert.Zero(t, dropped)
+	assert.Equal(t, []string{
+		"create " + monthsAgo(0).Name, "create " + monthsAgo(-1).Name, "create " + monthsAgo(-2).Name,
+	}, logs.calls)
+}
@@ -0,0 +1,241 @@
+package test
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/postgres"
+	"posts/internal/repository"
+	"posts/internal/repository/postgres/logs"
+	"posts/internal/usecase/service"
+)
+
+func TestLogsSince(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := logs.NewRepository(bun.NewDB(db, pgdialect.New()))
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "l"."id", "l"."level", "l"."message", "l"."service_name", "l"."created_at", "l"."created_by", "l"."attributes" FROM "logs" AS "l" WHERE (id > 41) AND (deleted_at IS NULL) AND (level = 'ERROR') AND (service_name = 'api-gateway') ORDER BY "id" LIMIT 200`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "level", "message", "service_name", "created_at", "created_by", "attributes"}).
+			AddRow(42, "ERROR", "failed to send", "api-gateway", created, nil, `{"topic":"post-created"}`))
+
+	resp, err := repo.Since(context.Background(), &pb.LogTailRequest{Level: "ERROR", ServiceName: "api-gateway"}, 41, 200)
+	assert.NoError(t, err)
+	if assert.Len(t, resp, 1) {
+		assert.Equal(t, int64(42), resp[0].Id)
+		assert.Equal(t, "post-created", resp[0].Attributes["topic"])
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT COALESCE(max(id), 0) FROM "logs"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"coalesce"}).AddRow(42))
+	last, err := repo.LastID(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), last)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// fakeSource is a NotificationSource fed by the test.
+type fakeSource struct {
+	notifications chan *pq.Notification
+	closed        bool
+}
+
+func (s *fakeSource) NotificationChannel() <-chan *pq.Notification { return s.notifications }
+func (s *fakeSource) Ping() error                                  { return nil }
+func (s *fakeSource) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestListenerWakesSubscribers(t *testing.T) {
+	source := &fakeSource{notifications: make(chan *pq.Notification)}
+	listener := postgres.NewListenerFrom(source)
+
+	first, _ := listener.Subscribe()
+	second, stop := listener.Subscribe()
+
+	source.notifications <- &pq.Notification{Channel: "logs_inserted"}
+	for _, wake := range []<-chan struct{}{first, second} {
+		select {
+		case <-wake:
+		case <-time.After(time.Second):
+			t.Fatal("subscriber was not woken")
+		}
+	}
+
+	// a reconnect wakes subscribers too; ended subscriptions are not
+	stop()
+	source.notifications <- nil
+	select {
+	case <-first:
+	case <-time.After(time.Second):
+		t.Fatal("subscriber was not woken after a reconnect")
+	}
+	assert.Empty(t, second)
+
+	// notifications arriving before a subscriber looks are coalesced
+	source.notifications <- &pq.Notification{}
+	source.notifications <- &pq.Notification{}
+	assert.NoError(t, listener.Close())
+	assert.Len(t, first, 1)
+	assert.True(t, source.closed)
+}
+
+// fakeNotifier wakes Tail when the test inserts logs.
+type fakeNotifier struct {
+	wake chan struct{}
+}
+
+func (n *fakeNotifier) Subscribe() (<-chan struct{}, func()) {
+	return n.wake, func() {}
+}
+
+// tailLogs holds the committed logs Tail can see.
+type tailLogs struct {
+	repository.LogI
+	mu   sync.Mutex
+	rows []*pb.LogGetResponse
+}
+
+func (l *tailLogs) commit(ids ...int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, id := range ids {
+		l.rows = append(l.rows, &pb.LogGetResponse{Id: id})
+	}
+	sort.Slice(l.rows, func(i, j int) bool { return l.rows[i].Id < l.rows[j].Id })
+}
+
+func (l *tailLogs) LastID(ctx context.Context) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.rows) == 0 {
+		return 0, nil
+	}
+	return l.rows[len(l.rows)-1].Id, nil
+}
+
+func (l *tailLogs) Since(ctx context.Context, filter *pb.LogTailRequest, afterID int64, limit int) ([]*pb.LogGetResponse, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var result []*pb.LogGetResponse
+	for _, row := range l.rows {
+		if row.Id > afterID && len(result) < limit {
+			result = append(result, row)
+		}
+	}
+	return result, nil
+}
+
+// tailStream collects what Tail sends; started is closed once the tail
+// has started.
+type tailStream struct {
+	grpc.ServerStream
+	ctx     context.Context
+	started chan struct{}
+	sent    chan int64
+}
+
+func newTailStream(ctx context.Context) *tailStream {
+	return &tailStream{ctx: ctx, started: make(chan struct{}), sent: make(chan int64, 10)}
+}
+
+func (s *tailStream) Context() context.Context { return s.ctx }
+func (s *tailStream) SendHeader(md metadata.MD) error {
+	close(s.started)
+	return nil
+}
+func (s *tailStream) Send(log *pb.LogGetResponse) error {
+	s.sent <- log.Id
+	return nil
+}
+
+func (s *tailStream) next(t *testing.T) int64 {
+	t.Helper()
+	select {
+	case id := <-s.sent:
+		return id
+	case <-time.After(time.Second):
+		t.Fatal("no log was sent")
+		return 0
+	}
+}
+
+func TestTailSendsLogsCommittedLate(t *testing.T) {
+	rows := &tailLogs{}
+	rows.commit(1, 2)
+	notifier := &fakeNotifier{wake: make(chan struct{}, 1)}
+	s := service.NewLogService(&fakeStorage{logs: rows}, nil, notifier)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := newTailStream(ctx)
+	done := make(chan error, 1)
+	go func() { done <- s.Tail(&pb.LogTailRequest{}, stream) }()
+	<-stream.started
+
+	// 3 is inserted before 4 but commits after it
+	rows.commit(4)
+	notifier.wake <- struct{}{}
+	assert.Equal(t, int64(4), stream.next(t))
+
+	rows.commit(3)
+	notifier.wake <- struct{}{}
+	assert.Equal(t, int64(3), stream.next(t))
+
+	rows.commit(5)
+	notifier.wake <- struct{}{}
+	assert.Equal(t, int64(5), stream.next(t))
+
+	cancel()
+	assert.NoError(t, <-done)
+	// nothing went out twice, and logs from before the tail not at all
+	assert.Empty(t, stream.sent)
+}
+
+func TestTailResumesAfterID(t *testing.T) {
+	rows := &tailLogs{}
+	rows.commit(1, 2, 3)
+	s := service.NewLogService(&fakeStorage{logs: rows}, nil, &fakeNotifier{wake: make(chan struct{})})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := newTailStream(ctx)
+	done := make(chan error, 1)
+	go func() { done <- s.Tail(&pb.LogTailRequest{AfterId: 1}, stream) }()
+
+	assert.Equal(t, int64(2), stream.next(t))
+	assert.Equal(t, int64(3), stream.next(t))
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestTailRejectsInvalidRequests(t *testing.T) {
+	stream := newTailStream(context.Background())
+
+	err := service.NewLogService(nil, nil, nil).Tail(&pb.LogTailRequest{}, stream)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	s := service.NewLogService(nil, nil, &fakeNotifier{})
+	err = s.Tail(&pb.LogTailRequest{Level: "LOUD"}, stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	err = s.Tail(&pb.LogTailRequest{AfterId: -1}, stream)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
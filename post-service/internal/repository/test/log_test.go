@@ -2,14 +2,20 @@ package test
 
 import (
 	"context"
+	"fmt"
 	"regexp"
 	"testing"
+	"time"
 
+	"posts/internal/entity"
+	"posts/internal/pkg/cursor"
 	pb "posts/internal/pkg/genproto"
 	"posts/internal/repository/postgres/logs"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
 )
 
 func TestGetDetail(t *testing.T) {
@@ -19,19 +25,19 @@ func TestGetDetail(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := logs.NewRepository(db)
+	repo := logs.NewRepository(bun.NewDB(db, pgdialect.New()))
 	ctx := context.Background()
 	request := &pb.GetId{Id: 1}
 
-	mock.ExpectQuery(regexp.QuoteMeta("SELECT id, level, message, service_name, created_at FROM logs WHERE id = $1")).
-		WithArgs(request.Id).
-		WillReturnRows(sqlmock.NewRows([]string{"id", "level", "message", "service_name", "created_at"}).
-			AddRow(1, "INFO", "Test log", "TestService", "2024-03-07 12:00:00"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "l"."id", "l"."level", "l"."message", "l"."service_name", "l"."created_at", "l"."created_by", "l"."attributes" FROM "logs" AS "l" WHERE (id = 1)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "level", "message", "service_name", "created_at", "created_by", "attributes"}).
+			AddRow(1, "INFO", "Test log", "TestService", time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC), nil, []byte(`{"trace_id": "abc"}`)))
 
 	resp, err := repo.GetDetail(ctx, request)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 	assert.Equal(t, int64(1), resp.Id)
+	assert.Equal(t, map[string]string{"trace_id": "abc"}, resp.Attributes)
 }
 
 func TestUpdate(t *testing.T) {
@@ -41,7 +47,7 @@ func TestUpdate(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := logs.NewRepository(db)
+	repo := logs.NewRepository(bun.NewDB(db, pgdialect.New()))
 	ctx := context.Background()
 	request := &pb.LogUpdateRequest{
 		Id:          1,
@@ -50,8 +56,7 @@ func TestUpdate(t *testing.T) {
 		ServiceName: "UpdatedService",
 	}
 
-	mock.ExpectExec(regexp.QuoteMeta("UPDATE logs SET updated_at = NOW(), level = $1, message = $2, service_name = $3 WHERE id = $4")).
-		WithArgs(request.Level, request.Message, request.ServiceName, request.Id).
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "logs" SET updated_at = NOW(), level = 'ERROR', message = 'Updated log', service_name = 'UpdatedService' WHERE (id = 1)`)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	_, err = repo.Update(ctx, request)
@@ -65,14 +70,173 @@ func TestDelete(t *testing.T) {
 	}
 	defer db.Close()
 
-	repo := logs.NewRepository(db)
+	repo := logs.NewRepository(bun.NewDB(db, pgdialect.New()))
 	ctx := context.Background()
 	request := &pb.GetId{Id: 1}
 
-	mock.ExpectExec(regexp.QuoteMeta("update logs set deleted_at = NOW() WHERE id = $1")).
-		WithArgs(request.Id).
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "logs" SET deleted_at = NOW() WHERE (id = 1)`)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	_, err = repo.Delete(ctx, request)
 	assert.NoError(t, err)
 }
+
+func TestCreateBatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := logs.NewRepository(bun.NewDB(db, pgdialect.New()))
+	ctx := context.Background()
+	sent := time.Now().UTC().Truncate(time.Second).Add(-time.Minute)
+	records := []*pb.LogRecord{
+		{Level: "WARNING", Message: "slow query", ServiceName: "post-service", Time: sent.Format(time.RFC3339)},
+		{Level: "ERROR", Message: "failed to send", ServiceName: "api-gateway", Time: sent.Add(time.Second).Format(time.RFC3339), Attributes: map[string]string{"topic": "post-created"}},
+		// clocks this far off get the insertion time
+		{Level: "ERROR", Message: "from the future", ServiceName: "post-service", Time: sent.AddDate(0, 3, 0).Format(time.RFC3339)},
+		{Level: "ERROR", Message: "from the past", ServiceName: "post-service", Time: sent.AddDate(-1, 0, 0).Format(time.RFC3339)},
+	}
+
+	mock.ExpectExec(regexp.QuoteMeta(fmt.Sprintf(`INSERT INTO "logs" ("level", "message", "service_name", "attributes", "created_at") VALUES ('WARNING', 'slow query', 'post-service', DEFAULT, '%s'), ('ERROR', 'failed to send', 'api-gateway', '{"topic":"post-created"}', '%s'), ('ERROR', 'from the future', 'post-service', DEFAULT, DEFAULT), ('ERROR', 'from the past', 'post-service', DEFAULT, DEFAULT)`,
+		sent.Format("2006-01-02 15:04:05-07:00"), sent.Add(time.Second).Format("2006-01-02 15:04:05-07:00")))).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+
+	n, err := repo.CreateBatch(ctx, records)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+
+	n, err = repo.CreateBatch(ctx, nil)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogPartitionNames(t *testing.T) {
+	p := entity.LogPartitionFor(time.Date(2024, 12, 31, 23, 59, 0, 0, time.UTC))
+	assert.Equal(t, "logs_y2024m12", p.Name)
+	assert.Equal(t, time.Date(2024, 12, 1, 0, 0, 0, 0, time.UTC), p.From)
+	assert.Equal(t, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), p.To)
+
+	parsed, ok := entity.ParseLogPartition("logs_y2024m12")
+	assert.True(t, ok)
+	assert.Equal(t, p, parsed)
+
+	for _, name := range []string{"logs_default", "logs_y2024m13", "logs_y2024m1", "logs_y2024m12_old"} {
+		_, ok := entity.ParseLogPartition(name)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestCreatePartition(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := logs.NewRepository(bun.NewDB(db, pgdialect.New()))
+	p := entity.LogPartitionFor(time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC))
+
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS "logs_y2024m03" PARTITION OF logs FOR VALUES FROM ('2024-03-01 00:00:00') TO ('2024-04-01 00:00:00')`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	assert.NoError(t, repo.CreatePartition(context.Background(), p))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDropPartitionRejectsOtherTables(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := logs.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	assert.Error(t, repo.DropPartition(context.Background(), entity.LogPartition{Name: "logs_default"}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetListByCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := logs.NewRepository(bun.NewDB(db, pgdialect.New()))
+	after := &cursor.Position{Time: time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC), ID: 10}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "l"."id", "l"."level", "l"."message", "l"."service_name", "l"."created_at", "l"."created_by", "l"."attributes" FROM "logs" AS "l" WHERE (deleted_at IS NULL) AND (level = 'ERROR') AND ((created_at, id) < ('2024-03-07 12:00:00+00:00', 10)) ORDER BY "created_at" DESC, "id" DESC LIMIT 3`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "level", "message", "service_name", "created_at", "created_by"}).
+			AddRow(9, "ERROR", "a", "post-service", time.Date(2024, 3, 7, 11, 0, 0, 0, time.UTC), nil).
+			AddRow(8, "ERROR", "b", "post-service", time.Date(2024, 3, 7, 10, 0, 0, 0, time.UTC), nil).
+			AddRow(7, "ERROR", "c", "post-service", time.Date(2024, 3, 7, 9, 0, 0, 0, time.UTC), nil))
+
+	page, err := repo.GetList(context.Background(), &pb.FilterLog{Limit: 2, Level: "ERROR"}, after)
+	assert.NoError(t, err)
+	assert.Len(t, page.Log, 2)
+	assert.Equal(t, int32(2), page.Count)
+
+	next, err := cursor.Decode(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(8), next.ID)
+	assert.Equal(t, time.Date(2024, 3, 7, 10, 0, 0, 0, time.UTC), next.Time)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetListFilters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := logs.NewRepository(bun.NewDB(db, pgdialect.New()))
+	filter := &pb.FilterLog{
+		Limit:         10,
+		Levels:        []string{"WARNING", "ERROR"},
+		From:          "2024-03-07T14:00:00+02:00",
+		To:            "2024-03-08T00:00:00Z",
+		Query:         "timeout kafka",
+		AttributeKeys: []string{"trace_id", "path"},
+		Attributes:    map[string]string{"user_id": "42"},
+	}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "l"."id", "l"."level", "l"."message", "l"."service_name", "l"."created_at", "l"."created_by", "l"."attributes" FROM "logs" AS "l" WHERE (deleted_at IS NULL) AND (level IN ('WARNING', 'ERROR')) AND (created_at >= '2024-03-07 12:00:00+00:00') AND (created_at < '2024-03-08 00:00:00+00:00') AND (search_vector @@ websearch_to_tsquery('simple', 'timeout kafka')) AND (attributes ?& '{"trace_id","path"}') AND (attributes @> '{"user_id":"42"}'::jsonb) ORDER BY "created_at" DESC, "id" DESC LIMIT 11`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "level", "message", "service_name", "created_at", "created_by"}))
+
+	page, err := repo.GetList(context.Background(), filter, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Log)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestLogStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := logs.NewRepository(bun.NewDB(db, pgdialect.New()))
+	filter := &pb.FilterLog{ServiceName: "post-service", From: "2024-03-07T00:00:00Z"}
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "level", "service_name", count(*) AS count, date_trunc('hour', created_at) AS bucket FROM logs AS l WHERE (deleted_at IS NULL) AND (service_name = 'post-service') AND (created_at >= '2024-03-07 00:00:00+00:00') GROUP BY "level", "service_name", bucket ORDER BY bucket, "level", "service_name"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"level", "service_name", "count", "bucket"}).
+			AddRow("ERROR", "post-service", 3, time.Date(2024, 3, 7, 10, 0, 0, 0, time.UTC)).
+			AddRow("WARNING", "post-service", 5, time.Date(2024, 3, 7, 10, 0, 0, 0, time.UTC)))
+
+	counts, err := repo.Stats(context.Background(), filter, "hour")
+	assert.NoError(t, err)
+	if assert.Len(t, counts, 2) {
+		row := counts[1].ToProto()
+		assert.Equal(t, "WARNING", row.Level)
+		assert.Equal(t, "2024-03-07T10:00:00Z", row.Bucket)
+		assert.Equal(t, int64(5), row.Count)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,118 @@
+package test
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/encoding/protojson"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/logger"
+)
+
+func TestParseLogLevel(t *testing.T) {
+	level, err := logger.ParseLevel("TRACE")
+	assert.NoError(t, err)
+	assert.Equal(t, logger.LevelTrace, level)
+
+	level, err = logger.ParseLevel("warn")
+	assert.NoError(t, err)
+	assert.Equal(t, slog.LevelWarn, level)
+
+	_, err = logger.ParseLevel("fatal")
+	assert.Error(t, err)
+	_, err = logger.ParseLevel("verbose")
+	assert.Error(t, err)
+}
+
+func TestSetLogLevel(t *testing.T) {
+	l, err := logger.New(logger.Config{Level: "info"})
+	assert.NoError(t, err)
+	assert.Equal(t, "info", l.Level())
+	assert.False(t, l.Enabled(context.Background(), slog.LevelDebug))
+
+	assert.NoError(t, l.SetLevel("trace"))
+	assert.Equal(t, "trace", l.Level())
+	assert.True(t, l.Enabled(context.Background(), logger.LevelTrace))
+
+	assert.Error(t, l.SetLevel("loud"))
+	assert.Equal(t, "trace", l.Level())
+
+	_, err = logger.New(logger.Config{Level: "loud"})
+	assert.Error(t, err)
+}
+
+type recordingProducer struct {
+	mu       sync.Mutex
+	messages [][]byte
+}
+
+func (p *recordingProducer) ProduceMessages(ctx context.Context, topic string, message []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if topic == logger.TopicLogIngest {
+		p.messages = append(p.messages, message)
+	}
+	return nil
+}
+
+func (p *recordingProducer) batches(t *testing.T) []*pb.LogBatch {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	batches := make([]*pb.LogBatch, 0, len(p.messages))
+	for _, m := range p.messages {
+		var batch pb.LogBatch
+		assert.NoError(t, protojson.Unmarshal(m, &batch))
+		batches = append(batches, &batch)
+	}
+	return batches
+}
+
+func TestSinkShipsBatches(t *testing.T) {
+	producer := &recordingProducer{}
+	sink, err := logger.NewSink(producer, logger.SinkConfig{
+		Service:       "post-service",
+		Level:         "warn",
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+	})
+	assert.NoError(t, err)
+
+	l, err := logger.New(logger.Config{Level: "info", Sink: sink})
+	assert.NoError(t, err)
+
+	l.Info("not shipped")
+	l.Warn("slow query", "took", "2s")
+	l.With("topic", "post-created").Error("failed to decode", "error", "bad json")
+	logger.NotShipped(l.Logger).Error("failed to store logs")
+
+	// the two shipped records fill a batch; Close flushes the rest
+	assert.Eventually(t, func() bool { return len(producer.batches(t)) == 1 }, time.Second, 10*time.Millisecond)
+	l.Error("left over")
+	assert.NoError(t, sink.Close())
+
+	batches := producer.batches(t)
+	if assert.Len(t, batches, 2) {
+		first := batches[0].Record
+		if assert.Len(t, first, 2) {
+			assert.Equal(t, "WARNING", first[0].Level)
+			assert.Equal(t, "slow query", first[0].Message)
+			assert.Equal(t, map[string]string{"took": "2s"}, first[0].Attributes)
+			assert.Equal(t, "post-service", first[0].ServiceName)
+			assert.Equal(t, "ERROR", first[1].Level)
+			assert.Equal(t, "failed to decode", first[1].Message)
+			assert.Equal(t, map[string]string{"topic": "post-created", "error": "bad json"}, first[1].Attributes)
+		}
+		if assert.Len(t, batches[1].Record, 1) {
+			assert.Equal(t, "left over", batches[1].Record[0].Message)
+		}
+	}
+}
+
+func TestSinkRejectsUnknownLevels(t *testing.T) {
+	_, err := logger.NewSink(&recordingProducer{}, logger.SinkConfig{Level: "verbose"})
+	assert.Error(t, err)
+}
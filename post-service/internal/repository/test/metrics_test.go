@@ -0,0 +1,40 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/pkg/metrics"
+	"posts/internal/usecase/kafka"
+)
+
+func TestGRPCMetrics(t *testing.T) {
+	interceptor := metrics.UnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: "/test.Service/Get"}
+
+	_, err := interceptor(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	})
+	assert.Error(t, err)
+
+	rec := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	assert.Contains(t, string(body), `grpc_server_handled_total{code="NotFound",method="/test.Service/Get"} 1`)
+	assert.Contains(t, string(body), `grpc_server_handling_seconds_count{method="/test.Service/Get"} 1`)
+}
+
+func TestMetricsResult(t *testing.T) {
+	assert.Equal(t, "ok", metrics.Result(nil))
+	assert.Equal(t, "error", metrics.Result(errors.New("boom")))
+	assert.Equal(t, "log-ingest-dlq", kafka.DeadLetterTopic("log-ingest"))
+	assert.Equal(t, "comment-created-webhooks-dlq", kafka.GroupDeadLetterTopic("comment-created", "webhooks"))
+}
@@ -0,0 +1,192 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"posts/internal/pkg/migrate"
+)
+
+// migrationLock is the advisory lock key the migrator holds.
+const migrationLock int64 = 7_001_000_027
+
+var testMigrations = fstest.MapFS{
+	"001_users.up.sql":   {Data: []byte("CREATE TABLE users ();")},
+	"001_users.down.sql": {Data: []byte("DROP TABLE users;")},
+	"002_posts.up.sql":   {Data: []byte("CREATE TABLE posts ();")},
+	"002_posts.down.sql": {Data: []byte("DROP TABLE posts;")},
+	"README.md":          {Data: []byte("not a migration")},
+}
+
+func newMigrator(t *testing.T) (*migrate.Migrator, sqlmock.Sqlmock) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	m, err := migrate.New(db, testMigrations)
+	if err != nil {
+		t.Fatalf("failed to load migrations: %v", err)
+	}
+	return m, mock
+}
+
+func expectLocked(mock sqlmock.Sqlmock, version int64, dirty bool) {
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_lock($1)`)).
+		WithArgs(migrationLock).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectVersion(mock, version, dirty)
+}
+
+func expectVersion(mock sqlmock.Sqlmock, version int64, dirty bool) {
+	rows := sqlmock.NewRows([]string{"version", "dirty"})
+	if version > 0 {
+		rows.AddRow(version, dirty)
+	}
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT version, dirty FROM schema_migrations LIMIT 1`)).WillReturnRows(rows)
+}
+
+func expectApply(mock sqlmock.Sqlmock, body string, version int64) {
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(body)).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM schema_migrations`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	if version > 0 {
+		mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO schema_migrations (version, dirty) VALUES ($1, false)`)).
+			WithArgs(version).WillReturnResult(sqlmock.NewResult(0, 1))
+	}
+	mock.ExpectCommit()
+}
+
+func expectUnlocked(mock sqlmock.Sqlmock) {
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_unlock($1)`)).
+		WithArgs(migrationLock).WillReturnResult(sqlmock.NewResult(0, 0))
+}
+
+func TestMigrateUpAppliesPending(t *testing.T) {
+	m, mock := newMigrator(t)
+
+	expectLocked(mock, 1, false)
+	expectApply(mock, "CREATE TABLE posts ();", 2)
+	expectUnlocked(mock)
+
+	applied, err := m.Up(context.Background())
+	assert.NoError(t, err)
+	if assert.Len(t, applied, 1) {
+		assert.Equal(t, int64(2), applied[0].Version)
+		assert.Equal(t, "posts", applied[0].Name)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateUpStopsAtFailure(t *testing.T) {
+	m, mock := newMigrator(t)
+
+	expectLocked(mock, 0, false)
+	expectApply(mock, "CREATE TABLE users ();", 1)
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta("CREATE TABLE posts ();")).WillReturnError(errors.New("syntax error"))
+	mock.ExpectRollback()
+	expectUnlocked(mock)
+
+	applied, err := m.Up(context.Background())
+	assert.ErrorContains(t, err, "applying migration 2_posts: syntax error")
+	assert.Len(t, applied, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateRefusesDirtyDatabase(t *testing.T) {
+	m, mock := newMigrator(t)
+
+	expectLocked(mock, 2, true)
+	expectUnlocked(mock)
+	_, err := m.Up(context.Background())
+	assert.ErrorIs(t, err, migrate.ErrDirty)
+
+	expectLocked(mock, 2, true)
+	expectUnlocked(mock)
+	_, err = m.Down(context.Background())
+	assert.ErrorIs(t, err, migrate.ErrDirty)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateFailsWithoutLock(t *testing.T) {
+	m, mock := newMigrator(t)
+
+	mock.ExpectExec(regexp.QuoteMeta(`SELECT pg_advisory_lock($1)`)).
+		WithArgs(migrationLock).WillReturnError(errors.New("canceling statement due to lock timeout"))
+
+	applied, err := m.Up(context.Background())
+	assert.ErrorContains(t, err, "acquiring migration lock")
+	assert.Empty(t, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateDownRevertsLatest(t *testing.T) {
+	m, mock := newMigrator(t)
+
+	expectLocked(mock, 2, false)
+	expectApply(mock, "DROP TABLE posts;", 1)
+	expectUnlocked(mock)
+
+	reverted, err := m.Down(context.Background())
+	assert.NoError(t, err)
+	if assert.NotNil(t, reverted) {
+		assert.Equal(t, int64(2), reverted.Version)
+	}
+
+	// the first migration leaves no version behind
+	expectLocked(mock, 1, false)
+	expectApply(mock, "DROP TABLE users;", 0)
+	expectUnlocked(mock)
+
+	reverted, err = m.Down(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), reverted.Version)
+
+	expectLocked(mock, 0, false)
+	expectUnlocked(mock)
+
+	reverted, err = m.Down(context.Background())
+	assert.NoError(t, err)
+	assert.Nil(t, reverted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateStatus(t *testing.T) {
+	m, mock := newMigrator(t)
+
+	mock.ExpectExec(regexp.QuoteMeta(`CREATE TABLE IF NOT EXISTS schema_migrations`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	expectVersion(mock, 1, true)
+
+	status, err := m.Status(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), status.Version)
+	assert.True(t, status.Dirty)
+	assert.Len(t, status.Applied, 1)
+	if assert.Len(t, status.Pending, 1) {
+		assert.Equal(t, "posts", status.Pending[0].Name)
+	}
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMigrateRejectsDuplicateVersions(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	_, err = migrate.New(db, fstest.MapFS{
+		"001_users.up.sql": {Data: []byte("CREATE TABLE users ();")},
+		"001_posts.up.sql": {Data: []byte("CREATE TABLE posts ();")},
+	})
+	assert.ErrorContains(t, err, "duplicate migration version 1")
+}
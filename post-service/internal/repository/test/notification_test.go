@@ -0,0 +1,178 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/webhook"
+	"posts/internal/repository"
+	"posts/internal/repository/postgres/notifications"
+	"posts/internal/usecase/notify"
+	"posts/internal/usecase/service"
+)
+
+func newNotificationRepo(t *testing.T) (sqlmock.Sqlmock, *notifications.Repository, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	return mock, notifications.NewRepository(bun.NewDB(db, pgdialect.New())), func() { db.Close() }
+}
+
+func TestCreateNotification(t *testing.T) {
+	mock, repo, done := newNotificationRepo(t)
+	defer done()
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "notifications" ("user_id", "kind", "actor_id", "post_id", "comment_id", "title", "body") VALUES (2, 'comment', 1, 7, 11, 'alice commented on Hello', 'Nice post') RETURNING id, created_at`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, created))
+
+	n, err := repo.Create(context.Background(), &pb.Notification{
+		UserId:    2,
+		Kind:      "comment",
+		ActorId:   1,
+		PostId:    7,
+		CommentId: 11,
+		Title:     "alice commented on Hello",
+		Body:      "Nice post",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), n.Id)
+	assert.Equal(t, "", n.ReadAt)
+}
+
+func TestGetNotificationList(t *testing.T) {
+	mock, repo, done := newNotificationRepo(t)
+	defer done()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "n"."id", "n"."user_id", "n"."kind", "n"."actor_id", "n"."post_id", "n"."comment_id", "n"."title", "n"."body", "n"."read_at", "n"."created_at" FROM "notifications" AS "n" WHERE (user_id = 2) AND (read_at IS NULL) ORDER BY "created_at" DESC, "id" DESC LIMIT 10`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "kind", "title"}).AddRow(5, 2, "follow", "alice started following you"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "notifications" AS "n" WHERE (user_id = 2) AND (read_at IS NULL)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "notifications" AS "n" WHERE (user_id = 2) AND (read_at IS NULL)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	resp, err := repo.GetList(context.Background(), &pb.NotificationListRequest{UserId: 2, UnreadOnly: true, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.Count)
+	assert.Equal(t, int64(1), resp.UnreadCount)
+	assert.Equal(t, "follow", resp.Notification[0].Kind)
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	mock, repo, done := newNotificationRepo(t)
+	defer done()
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "notifications" AS "n" SET read_at = NOW() WHERE (user_id = 2) AND (read_at IS NULL) AND (id IN (5, 6))`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	updated, err := repo.MarkRead(context.Background(), &pb.NotificationReadRequest{UserId: 2, Ids: []int64{5, 6}})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), updated)
+
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "notifications" AS "n" SET read_at = NOW() WHERE (user_id = 2) AND (read_at IS NULL)`)).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	updated, err = repo.MarkRead(context.Background(), &pb.NotificationReadRequest{UserId: 2, All: true})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(4), updated)
+}
+
+func TestSetNotificationPreferences(t *testing.T) {
+	mock, repo, done := newNotificationRepo(t)
+	defer done()
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "notification_preferences" AS "np" ("user_id", "kind", "channel", "enabled") VALUES (2, 'comment', 'email', TRUE), (2, 'follow', 'in_app', FALSE) ON CONFLICT (user_id, kind, channel) DO UPDATE SET enabled = EXCLUDED.enabled`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	err := repo.SetPreferences(context.Background(), 2, []*pb.NotificationPreference{
+		{Kind: "comment", Channel: "email", Enabled: true},
+		{Kind: "follow", Channel: "in_app", Enabled: false},
+	})
+	assert.NoError(t, err)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "nw"."url", "nw"."secret" FROM "notification_webhooks" AS "nw" WHERE (user_id = 2)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "secret"}))
+	webhook, err := repo.Webhook(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "", webhook.URL)
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "notification_webhooks" AS "nw" ("user_id", "url", "secret") VALUES (2, 'https://example.com/hook', 's3cret') ON CONFLICT (user_id) DO UPDATE SET url = EXCLUDED.url, secret = EXCLUDED.secret, updated_at = NOW()`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	assert.NoError(t, repo.SetWebhook(context.Background(), 2, "https://example.com/hook", "s3cret"))
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestNotificationWebhookRefusesInternalAddress(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer server.Close()
+
+	to := notify.Recipient{UserID: 2, WebhookURL: server.URL, WebhookSecret: "secret"}
+	err := notify.NewWebhook().Deliver(context.Background(), to, &pb.Notification{UserId: 2, Kind: "follow"})
+	assert.ErrorIs(t, err, webhook.ErrForbiddenAddress)
+	assert.False(t, hit)
+}
+
+func TestUpdatePreferencesRejectsInternalWebhook(t *testing.T) {
+	s := service.NewNotificationService(nil)
+
+	_, err := s.UpdatePreferences(context.Background(), &pb.NotificationPreferences{
+		UserId:     2,
+		WebhookUrl: "http://127.0.0.1:7002/metrics",
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+// fakeNotifications has no preferences or webhook for anybody.
+type fakeNotifications struct {
+	repository.NotificationI
+}
+
+func (f fakeNotifications) Preferences(ctx context.Context, userID int64) ([]*pb.NotificationPreference, error) {
+	return nil, nil
+}
+
+func (f fakeNotifications) Webhook(ctx context.Context, userID int64) (*entity.NotificationWebhook, error) {
+	return &entity.NotificationWebhook{}, nil
+}
+
+// fakeChannel counts deliveries and fails them with err.
+type fakeChannel struct {
+	name      string
+	err       error
+	delivered int
+}
+
+func (c *fakeChannel) Name() string { return c.name }
+func (c *fakeChannel) Deliver(ctx context.Context, to notify.Recipient, n *pb.Notification) error {
+	c.delivered++
+	return c.err
+}
+
+func TestNotifySurvivesFailingChannel(t *testing.T) {
+	inApp := &fakeChannel{name: entity.ChannelInApp}
+	webhook := &fakeChannel{name: entity.ChannelWebhook, err: errors.New("connection refused")}
+	s := service.NewNotificationService(&fakeStorage{notifications: fakeNotifications{}}, inApp, webhook)
+
+	// a failed message would be retried and store the notification twice
+	err := s.Notify(context.Background(), &pb.Notification{UserId: 2, Kind: entity.NotifyFollow})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, inApp.delivered)
+	assert.Equal(t, 1, webhook.delivered)
+}
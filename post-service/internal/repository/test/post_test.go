@@ -3,12 +3,19 @@ package test
 import (
 	"context"
 	"database/sql"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	pb "posts/internal/pkg/genproto"
 	repository "posts/internal/repository/postgres/posts"
+	"posts/internal/usecase/service"
 )
 
 func setupTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *repository.Repository) {
@@ -16,7 +23,7 @@ func setupTestDB(t *testing.T) (*sql.DB, sqlmock.Sqlmock, *repository.Repository
 	if err != nil {
 		t.Fatalf("Failed to create mock database: %s", err)
 	}
-	repo := repository.NewRepository(db)
+	repo := repository.NewRepository(bun.NewDB(db, pgdialect.New()))
 	return db, mock, repo
 }
 
@@ -25,14 +32,15 @@ func TestCreatePost(t *testing.T) {
 	defer db.Close()
 
 	request := &pb.PostCreateRequest{
-		UserId:  1,
-		Title:   "Test Title",
-		Content: "Test Content",
+		UserId:   1,
+		Title:    "Test Title",
+		Content:  "Test Content",
+		Language: "english",
+		Status:   "draft",
 	}
 
-	mock.ExpectExec("INSERT INTO posts").
-		WithArgs(request.UserId, request.Title, request.Content).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "posts" ("user_id", "title", "content", "language", "status", "publish_at", "published_at") VALUES (1, 'Test Title', 'Test Content', 'english', 'draft', DEFAULT, DEFAULT) RETURNING id`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
 
 	resp, err := repo.Create(context.Background(), request)
 	assert.NoError(t, err)
@@ -40,6 +48,7 @@ func TestCreatePost(t *testing.T) {
 	assert.Equal(t, request.UserId, resp.UserId)
 	assert.Equal(t, request.Title, resp.Title)
 	assert.Equal(t, request.Content, resp.Content)
+	assert.Equal(t, int64(7), resp.Id)
 }
 
 func TestUpdatePost(t *testing.T) {
@@ -52,11 +61,205 @@ func TestUpdatePost(t *testing.T) {
 		Content: "Updated Content",
 	}
 
-	mock.ExpectExec("UPDATE posts SET").
-		WithArgs(request.Title, request.Content, request.Id).
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "posts" SET updated_at = NOW(), title = 'Updated Title', content = 'Updated Content' WHERE (id = 1)`)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	resp, err := repo.Update(context.Background(), request)
 	assert.NoError(t, err)
 	assert.NotNil(t, resp)
 }
+
+func TestGetPostDetailSkipsDeletedPosts(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "p"."id", "p"."user_id", "p"."title", "p"."content", "p"."created_at", "p"."created_by", "p"."comments_count", "p"."reactions_count", "p"."reaction_counts", "p"."status", "p"."publish_at", "p"."published_at", "p"."slug", ARRAY(SELECT t.slug FROM post_tags AS pt JOIN tags AS t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.slug) AS tags FROM "posts" AS "p" WHERE (id = 7) AND (deleted_at IS NULL)`)).
+		WillReturnError(sql.ErrNoRows)
+
+	_, err := repo.GetDetail(context.Background(), &pb.GetById{Id: 7})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchPosts(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+
+	request := &pb.PostSearchRequest{
+		Query:    "golang tips",
+		Language: "english",
+		Limit:    5,
+		Page:     2,
+	}
+
+	mock.ExpectQuery(`LATERAL \(SELECT websearch_to_tsquery\(p.language, 'golang tips'\) AS query\) AS q.*p.language = 'english'::regconfig.*LIMIT 5 OFFSET 5`).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"total_count", "id", "user_id", "title", "content", "title_highlight", "content_highlight", "rank", "created_at",
+		}).AddRow(6, 1, 1, "Golang tips", "Some tips", "<mark>Golang</mark> <mark>tips</mark>", "Some <mark>tips</mark>", 0.5, "2024-03-07 12:00:00"))
+
+	resp, err := repo.Search(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Equal(t, int32(6), resp.Count)
+	assert.Len(t, resp.Post, 1)
+	assert.Equal(t, "<mark>Golang</mark> <mark>tips</mark>", resp.Post[0].TitleHighlight)
+}
+
+func TestSearchPostsPrefix(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+
+	request := &pb.PostSearchRequest{
+		Query: "go rout",
+		Mode:  "prefix",
+	}
+
+	mock.ExpectQuery(`to_tsquery\(p.language, 'go:\* & rout:\*'\).*LIMIT 10`).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"total_count", "id", "user_id", "title", "content", "title_highlight", "content_highlight", "rank", "created_at",
+		}))
+
+	resp, err := repo.Search(context.Background(), request)
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Post)
+}
+
+func TestSearchPostsEmptyQuery(t *testing.T) {
+	db, _, repo := setupTestDB(t)
+	defer db.Close()
+
+	_, err := repo.Search(context.Background(), &pb.PostSearchRequest{Query: "   "})
+	assert.Error(t, err)
+}
+
+func TestGetPostListWithNullUserColumns(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "p"."id", "p"."user_id", "p"."title", "p"."content", "p"."created_at", "p"."created_by", "p"."comments_count", "p"."reactions_count", "p"."reaction_counts", "p"."status", "p"."publish_at", "p"."published_at", "p"."slug", ARRAY(SELECT t.slug FROM post_tags AS pt JOIN tags AS t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.slug) AS tags, "user"."id" AS "user__id", "user"."firstname" AS "user__firstname", "user"."lastname" AS "user__lastname", "user"."username" AS "user__username", "user"."email" AS "user__email", "user"."phone" AS "user__phone", "user"."gender" AS "user__gender", "user"."role" AS "user__role" FROM "posts" AS "p" LEFT JOIN "users" AS "user" ON ("user"."id" = "p"."user_id") AND "user"."deleted_at" IS NULL WHERE (p.deleted_at IS NULL) AND (p.status = 'published') AND (p.user_id = 1) ORDER BY "p"."created_at" DESC LIMIT 10`)).
+		WillReturnRows(sqlmock.NewRows([]string{
+			"id", "user_id", "title", "content", "created_at", "created_by", "comments_count", "reactions_count", "reaction_counts", "status", "publish_at", "published_at", "slug", "tags",
+			"user__id", "user__firstname", "user__lastname", "user__username", "user__email", "user__phone", "user__gender", "user__role",
+		}).AddRow(1, 1, "Title", "Content", nil, nil, 2, 3, `{"like": 2, "wow": 1}`, "published", nil, nil, "title", "{go,kafka}", 1, nil, "Doe", "johndoe", "john@example.com", nil, nil, "VIEWER"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "posts" AS "p"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	resp, err := repo.GetList(context.Background(), &pb.FilterPost{UserId: 1, Limit: 10})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), resp.Count)
+	assert.Len(t, resp.Post, 1)
+	assert.Equal(t, int64(2), resp.Post[0].CommentsCount)
+	assert.Equal(t, int64(3), resp.Post[0].ReactionsCount)
+	assert.Equal(t, map[string]int64{"like": 2, "wow": 1}, resp.Post[0].Reactions)
+	assert.Equal(t, []string{"go", "kafka"}, resp.Post[0].Tags)
+	assert.Equal(t, "", resp.Post[0].User.FirstName)
+	assert.Equal(t, "Doe", resp.Post[0].User.LastName)
+	assert.Equal(t, "", resp.Post[0].User.PhoneNumber)
+}
+
+func TestGetPostListPopular(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`ORDER BY "p"."reactions_count" DESC, "p"."created_at" DESC LIMIT 5`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "posts" AS "p"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	_, err := repo.GetList(context.Background(), &pb.FilterPost{SortBy: "popular", Limit: 5})
+	assert.NoError(t, err)
+
+	_, err = repo.GetList(context.Background(), &pb.FilterPost{SortBy: "random"})
+	assert.Error(t, err)
+}
+
+func TestAddReactionIsIdempotent(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+
+	query := regexp.QuoteMeta(`INSERT INTO "reactions" AS "r" ("post_id", "user_id", "kind") VALUES (1, 2, 'like') ON CONFLICT DO NOTHING`)
+	mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(query).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	request := &pb.PostReactionRequest{PostId: 1, UserId: 2, Kind: "like"}
+
+	added, err := repo.AddReaction(context.Background(), request)
+	assert.NoError(t, err)
+	assert.True(t, added)
+
+	added, err = repo.AddReaction(context.Background(), request)
+	assert.NoError(t, err)
+	assert.False(t, added)
+}
+
+func TestRefreshReactions(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(`UPDATE "posts" AS "p" SET reactions_count = \(SELECT COUNT\(\*\) FROM reactions WHERE post_id = 1\).*WHERE \(id = 1\) RETURNING reactions_count, reaction_counts`).
+		WillReturnRows(sqlmock.NewRows([]string{"reactions_count", "reaction_counts"}).AddRow(3, `{"like": 2, "sad": 1}`))
+
+	resp, err := repo.RefreshReactions(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), resp.PostId)
+	assert.Equal(t, int64(3), resp.Total)
+	assert.Equal(t, map[string]int64{"like": 2, "sad": 1}, resp.Reactions)
+}
+
+func TestGetPostListShowsViewerDrafts(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`WHERE (p.deleted_at IS NULL) AND ((p.status = 'published') OR (p.user_id = 4)) AND (p.status = 'draft')`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "posts" AS "p"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	_, err := repo.GetList(context.Background(), &pb.FilterPost{ViewerId: 4, Status: "draft"})
+	assert.NoError(t, err)
+}
+
+func TestPublishDuePosts(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+
+	published := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "posts" SET status = 'published', published_at = publish_at, updated_at = NOW() WHERE (id IN (SELECT "id" FROM "posts" WHERE (status = 'scheduled') AND (publish_at <= NOW() AT TIME ZONE 'UTC') AND (deleted_at IS NULL) ORDER BY publish_at LIMIT 100 FOR UPDATE SKIP LOCKED)) RETURNING id, user_id, title, published_at`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "published_at"}).
+			AddRow(3, 1, "Release notes", published).
+			AddRow(5, 2, "Weekly digest", published))
+
+	posts, err := repo.PublishDue(context.Background(), 100)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 2)
+	assert.Equal(t, int64(3), posts[0].Id)
+	assert.Equal(t, "2024-03-07T12:00:00Z", posts[1].PublishedAt)
+}
+
+func TestSearchPostsRejectsInvalidInput(t *testing.T) {
+	posts := service.NewPostService(nil, nil)
+
+	for _, request := range []*pb.PostSearchRequest{
+		{Query: "  "},
+		{Query: "golang", Mode: "fuzzy"},
+		{Query: "!!!", Mode: "prefix"},
+		{Query: "golang", Language: "english'); DROP TABLE posts; --"},
+		{Query: "golang", Language: "klingon"},
+	} {
+		_, err := posts.Search(context.Background(), request)
+		assert.Equal(t, codes.InvalidArgument, status.Code(err), request.String())
+	}
+}
+
+func TestCreatePostRejectsUnknownLanguage(t *testing.T) {
+	_, err := service.NewPostService(nil, nil).Create(context.Background(), &pb.PostCreateRequest{Title: "Hi", Language: "klingon"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGetPostListRejectsUnknownSort(t *testing.T) {
+	_, err := service.NewPostService(nil, nil).GetList(context.Background(), &pb.FilterPost{SortBy: "oldest"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
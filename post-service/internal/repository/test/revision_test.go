@@ -0,0 +1,146 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/repository/postgres/revisions"
+	"posts/internal/usecase/service"
+)
+
+func TestSnapshotPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := revisions.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectQuery(`INSERT INTO post_revisions \(post_id, revision, title, content\)\s+SELECT p.id, COALESCE\(\(SELECT MAX\(pr.revision\).*WHERE p.id = 7 AND p.deleted_at IS NULL\s+RETURNING revision`).
+		WillReturnRows(sqlmock.NewRows([]string{"revision"}).AddRow(3))
+
+	revision, err := repo.Snapshot(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), revision)
+}
+
+func TestSnapshotMissingPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := revisions.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectQuery(`INSERT INTO post_revisions`).
+		WillReturnRows(sqlmock.NewRows([]string{"revision"}))
+
+	_, err = repo.Snapshot(context.Background(), 7)
+	assert.True(t, errors.Is(err, sql.ErrNoRows))
+}
+
+func TestListRevisions(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+	mock.MatchExpectationsInOrder(false)
+
+	repo := revisions.NewRepository(bun.NewDB(db, pgdialect.New()))
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "pr"."post_id", "pr"."revision", "pr"."title", "pr"."content", "pr"."created_at" FROM "post_revisions" AS "pr" WHERE (post_id = 7) ORDER BY "revision" DESC LIMIT 2`)).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "revision", "title", "content", "created_at"}).
+			AddRow(7, 2, "Second", "Body two", created).
+			AddRow(7, 1, "First", "Body one", created))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT count(*) FROM "post_revisions" AS "pr" WHERE (post_id = 7)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(5))
+
+	resp, err := repo.List(context.Background(), &pb.RevisionListRequest{PostId: 7, Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(5), resp.Count)
+	assert.Equal(t, int64(2), resp.Revision[0].Revision)
+	assert.Equal(t, "First", resp.Revision[1].Title)
+}
+
+func TestRevisionsOfDraftOnlyForAuthor(t *testing.T) {
+	f := &fakeStorage{
+		posts:     fakePost{post: &pb.PostGetResponse{Id: 7, UserId: 3, Title: "Draft", Content: "secret\n", Status: entity.PostDraft}},
+		revisions: fakeRevisions{revision: &pb.PostRevision{PostId: 7, Revision: 1, Title: "Draft", Content: "old\n"}},
+	}
+	s := service.NewPostService(f, nil)
+	ctx := context.Background()
+
+	for _, viewer := range []int64{0, 4} {
+		_, err := s.ListRevisions(ctx, &pb.RevisionListRequest{PostId: 7, ViewerId: viewer})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		_, err = s.GetRevision(ctx, &pb.RevisionRequest{PostId: 7, Revision: 1, ViewerId: viewer})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		_, err = s.DiffRevisions(ctx, &pb.RevisionDiffRequest{PostId: 7, From: 1, ViewerId: viewer})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	}
+
+	list, err := s.ListRevisions(ctx, &pb.RevisionListRequest{PostId: 7, ViewerId: 3})
+	assert.NoError(t, err)
+	assert.Len(t, list.Revision, 1)
+
+	d, err := s.DiffRevisions(ctx, &pb.RevisionDiffRequest{PostId: 7, From: 1, ViewerId: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, "- old\n+ secret\n", d.ContentDiff)
+}
+
+func TestRevisionsOfPublishedPostForEveryone(t *testing.T) {
+	f := &fakeStorage{
+		posts:     fakePost{post: &pb.PostGetResponse{Id: 7, UserId: 3, Status: entity.PostPublished}},
+		revisions: fakeRevisions{revision: &pb.PostRevision{PostId: 7, Revision: 1}},
+	}
+	s := service.NewPostService(f, nil)
+
+	revision, err := s.GetRevision(context.Background(), &pb.RevisionRequest{PostId: 7, Revision: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), revision.Revision)
+
+	_, err = s.GetRevision(context.Background(), &pb.RevisionRequest{PostId: 8, Revision: 1})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestRestoreRevisionOnlyForAuthor(t *testing.T) {
+	ctx := context.Background()
+	revision := fakeRevisions{revision: &pb.PostRevision{PostId: 7, Revision: 1, Title: "Old"}}
+
+	// the fakes have no Snapshot or Update, so a refused restore must not
+	// get as far as writing anything
+	published := service.NewPostService(&fakeStorage{
+		posts:     fakePost{post: &pb.PostGetResponse{Id: 7, UserId: 3, Status: entity.PostPublished}},
+		revisions: revision,
+	}, nil)
+	for _, viewer := range []int64{0, 4} {
+		_, err := published.RestoreRevision(ctx, &pb.RevisionRequest{PostId: 7, Revision: 1, ViewerId: viewer})
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	}
+
+	draft := service.NewPostService(&fakeStorage{
+		posts:     fakePost{post: &pb.PostGetResponse{Id: 7, UserId: 3, Status: entity.PostDraft}},
+		revisions: revision,
+	}, nil)
+	_, err := draft.RestoreRevision(ctx, &pb.RevisionRequest{PostId: 7, Revision: 1, ViewerId: 4})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = published.RestoreRevision(ctx, &pb.RevisionRequest{PostId: 8, Revision: 1, ViewerId: 3})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
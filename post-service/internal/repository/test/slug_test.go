@@ -0,0 +1,70 @@
+package test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"posts/internal/pkg/slug"
+)
+
+func TestASCIISlug(t *testing.T) {
+	assert.Equal(t, "privet-mir", slug.ASCII("Привет, мир!"))
+	assert.Equal(t, "creme-brulee-a-la-strasse", slug.ASCII("Crème Brûlée à la Straße"))
+	assert.Equal(t, "dont-panic", slug.ASCII("Don't Panic"))
+	assert.Equal(t, "ozbekiston-haqida", slug.ASCII("O‘zbekiston ҳақида"))
+	assert.Equal(t, "go", slug.ASCII("日本語 Go"))
+	assert.Equal(t, "", slug.ASCII("日本語"))
+}
+
+func TestUniqueSlugs(t *testing.T) {
+	slugs := slug.Unique([]string{"  Go Lang ", "go-lang", "Kafka!!", "", "---", "Привет Мир"})
+	assert.Equal(t, []string{"go-lang", "kafka", "привет-мир"}, slugs)
+}
+
+func TestSlugWithSuffix(t *testing.T) {
+	assert.Equal(t, "hello", slug.WithSuffix("hello", map[string]bool{"hello-2": true}))
+	assert.Equal(t, "hello-3", slug.WithSuffix("hello", map[string]bool{"hello": true, "hello-2": true}))
+}
+
+func TestGetPostBySlug(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "p"."id", "p"."user_id", "p"."title", "p"."content", "p"."created_at", "p"."created_by", "p"."comments_count", "p"."reactions_count", "p"."reaction_counts", "p"."status", "p"."publish_at", "p"."published_at", "p"."slug", ARRAY(SELECT t.slug FROM post_tags AS pt JOIN tags AS t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.slug) AS tags FROM "posts" AS "p" WHERE (id = (SELECT ps.post_id FROM post_slugs AS ps WHERE ps.slug = 'old-title')) AND (deleted_at IS NULL)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "title", "status", "slug"}).
+			AddRow(5, 1, "New Title", "published", "new-title"))
+
+	post, err := repo.GetBySlug(context.Background(), "old-title")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), post.Id)
+	assert.Equal(t, "new-title", post.Slug)
+}
+
+func TestTakenSlugs(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "slug" FROM "post_slugs" WHERE ((slug = 'hello') OR (slug LIKE 'hello-%')) AND (post_id <> 5)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"slug"}).AddRow("hello").AddRow("hello-2"))
+
+	taken, err := repo.TakenSlugs(context.Background(), "hello", 5)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]bool{"hello": true, "hello-2": true}, taken)
+	assert.Equal(t, "hello-3", slug.WithSuffix("hello", taken))
+}
+
+func TestSetSlug(t *testing.T) {
+	db, mock, repo := setupTestDB(t)
+	defer db.Close()
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO post_slugs (slug, post_id) VALUES ('hello-3', 5) ON CONFLICT (slug) DO NOTHING`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "posts" SET slug = 'hello-3' WHERE (id = 5)`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	assert.NoError(t, repo.SetSlug(context.Background(), 5, "hello-3"))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,91 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/repository"
+	repo "posts/internal/repository/postgres"
+)
+
+func TestWithTxCommits(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	storage := repo.NewStorage(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET deleted_at = NOW() WHERE (id = 1)`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "posts" SET deleted_at = NOW() WHERE (user_id = 1) AND (deleted_at IS NULL)`)).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	var deleted int64
+	err = storage.WithTx(context.Background(), func(tx repository.StorageI) error {
+		if _, err := tx.User().Delete(context.Background(), &pb.ById{Id: 1}); err != nil {
+			return err
+		}
+		deleted, err = tx.Post().DeleteByUser(context.Background(), 1)
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTxRollsBackOnError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	storage := repo.NewStorage(db)
+	failure := errors.New("boom")
+
+	mock.ExpectBegin()
+	mock.ExpectRollback()
+
+	err = storage.WithTx(context.Background(), func(tx repository.StorageI) error {
+		return failure
+	})
+	assert.ErrorIs(t, err, failure)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWithTxRetriesSerializationFailure(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	storage := repo.NewStorage(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users"`).WillReturnError(&pq.Error{Code: "40001"})
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE "users"`).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	attempts := 0
+	err = storage.WithTx(context.Background(), func(tx repository.StorageI) error {
+		attempts++
+		_, err := tx.User().Delete(context.Background(), &pb.ById{Id: 1})
+		return err
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/repository/postgres/tags"
+)
+
+func TestSetPostTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := tags.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "post_tags" AS "pt" WHERE (post_id = 5)`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "tags" AS "t" ("slug", "name") VALUES ('go', 'Go'), ('kafka', 'kafka') ON CONFLICT (slug) DO UPDATE SET slug = EXCLUDED.slug RETURNING id`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3).AddRow(8))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "post_tags" ("post_id", "tag_id") VALUES (5, 3), (5, 8)`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	slugs, err := repo.SetForPost(context.Background(), 5, []string{"Go", "kafka", "GO"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"go", "kafka"}, slugs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClearPostTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := tags.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "post_tags" AS "pt" WHERE (post_id = 5)`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	slugs, err := repo.SetForPost(context.Background(), 5, nil)
+	assert.NoError(t, err)
+	assert.Empty(t, slugs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := tags.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectQuery(`WHERE \(t.slug LIKE 'go%'\) GROUP BY "t"."id" ORDER BY posts_count DESC, t.slug ASC LIMIT 50`).
+		WillReturnRows(sqlmock.NewRows([]string{"slug", "name", "posts_count", "total_count"}).
+			AddRow("go", "Go", 12, 2).
+			AddRow("gophers", "Gophers", 1, 2))
+
+	resp, err := repo.List(context.Background(), &pb.TagListRequest{Prefix: "Go"})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), resp.Count)
+	assert.Equal(t, int64(12), resp.Tag[0].PostsCount)
+}
+
+func TestDeleteUnusedTagsOnlyChecksGivenTags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := tags.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "tags" AS "t" WHERE (t.id IN (3, 8)) AND (NOT EXISTS`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	n, err := repo.DeleteUnused(context.Background(), []int64{3, 8})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+
+	// nothing dropped, nothing to check
+	n, err = repo.DeleteUnused(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Zero(t, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTagIDsForUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	repo := tags.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT DISTINCT "pt"."tag_id" FROM "post_tags" AS "pt" JOIN posts AS p ON p.id = pt.post_id WHERE (p.user_id = 7)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"tag_id"}).AddRow(3).AddRow(8))
+
+	ids, err := repo.IDsForUser(context.Background(), 7)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{3, 8}, ids)
+}
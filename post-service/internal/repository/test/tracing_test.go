@@ -0,0 +1,84 @@
+package test
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/tracing"
+	repo "posts/internal/repository/postgres"
+)
+
+// recordSpans installs a tracer provider that keeps spans in memory until
+// the test ends.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := tracing.NewProvider("test", sdktrace.WithSyncer(exporter))
+
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+func TestTraceCrossesKafkaAndDatabase(t *testing.T) {
+	exporter := recordSpans(t)
+	tracer := otel.Tracer("test")
+
+	ctx, publish := tracer.Start(context.Background(), "publish")
+	msg := kafka.Message{Topic: "log-update", Headers: []kafka.Header{{Key: "other", Value: []byte("kept")}}}
+	tracing.InjectKafka(ctx, &msg)
+	publish.End()
+	assert.Len(t, msg.Headers, 2)
+
+	ctx, process := tracer.Start(tracing.ExtractKafka(context.Background(), &msg), "process")
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "l"."id"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "level", "message", "service_name", "created_at", "created_by", "attributes"}).
+			AddRow(1, "INFO", "Test log", "TestService", time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC), nil, []byte(`{}`)))
+
+	_, err = repo.NewStorage(db).Log().GetDetail(ctx, &pb.GetId{Id: 1})
+	assert.NoError(t, err)
+	process.End()
+
+	spans := exporter.GetSpans()
+	if assert.Len(t, spans, 3) {
+		traceID := spans[0].SpanContext.TraceID()
+		for _, span := range spans {
+			assert.Equal(t, traceID, span.SpanContext.TraceID(), span.Name)
+		}
+		// publish, then the query, then the processing that ran it
+		assert.Equal(t, "publish", spans[0].Name)
+		assert.Equal(t, spans[2].SpanContext.SpanID(), spans[1].Parent.SpanID())
+		assert.Equal(t, spans[0].SpanContext.SpanID(), spans[2].Parent.SpanID())
+	}
+}
+
+func TestTracingRejectsUnknownExporter(t *testing.T) {
+	_, err := tracing.Init(context.Background(), tracing.Config{Exporter: "zipkin"})
+	assert.Error(t, err)
+
+	shutdown, err := tracing.Init(context.Background(), tracing.Config{Exporter: "none"})
+	assert.NoError(t, err)
+	assert.NoError(t, shutdown(context.Background()))
+}
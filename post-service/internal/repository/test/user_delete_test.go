@@ -0,0 +1,109 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/usecase/service"
+)
+
+func TestParseDeletionPolicy(t *testing.T) {
+	for input, want := range map[string]service.DeletionPolicy{
+		"cascade":     service.DeletionCascade,
+		" Anonymize ": service.DeletionAnonymize,
+		"BLOCK":       service.DeletionBlock,
+	} {
+		policy, err := service.ParseDeletionPolicy(input)
+		assert.NoError(t, err, input)
+		assert.Equal(t, want, policy, input)
+	}
+
+	for _, input := range []string{"", "purge"} {
+		_, err := service.ParseDeletionPolicy(input)
+		assert.Error(t, err, input)
+	}
+}
+
+type deletionFixture struct {
+	stg   *fakeStorage
+	users *fakeUsers
+	posts *owned
+	logs  *owned
+	tags  *fakeTags
+}
+
+func newDeletionFixture(posts, logs int64) deletionFixture {
+	f := deletionFixture{
+		users: &fakeUsers{},
+		posts: &owned{count: posts},
+		logs:  &owned{count: logs},
+		tags:  &fakeTags{userTags: []int64{4, 9}},
+	}
+	f.stg = &fakeStorage{
+		users: f.users,
+		posts: fakePosts{owned: f.posts},
+		logs:  fakeLogs{owned: f.logs},
+		tags:  f.tags,
+	}
+	return f
+}
+
+func TestDeleteUserCascadesAndAnonymizes(t *testing.T) {
+	f := newDeletionFixture(3, 5)
+	users := service.NewUserService(f.stg, service.DeletionPolicies{
+		Posts: service.DeletionCascade,
+		Logs:  service.DeletionAnonymize,
+	}, nil)
+
+	resp, err := users.Delete(context.Background(), &pb.ById{Id: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{7}, f.users.deleted)
+
+	assert.True(t, f.posts.deleted)
+	assert.False(t, f.posts.anonymized)
+	assert.True(t, f.logs.anonymized)
+	assert.False(t, f.logs.deleted)
+	assert.Equal(t, []int64{4, 9}, f.tags.cleaned)
+
+	assert.Equal(t, []*pb.RelationDeletion{
+		{Relation: "posts", Policy: "cascade", Affected: 3},
+		{Relation: "logs", Policy: "anonymize", Affected: 5},
+	}, resp.Relations)
+}
+
+func TestDeleteUserBlockedByRelatedRows(t *testing.T) {
+	f := newDeletionFixture(2, 0)
+	users := service.NewUserService(f.stg, service.DeletionPolicies{
+		Posts: service.DeletionBlock,
+		Logs:  service.DeletionCascade,
+	}, nil)
+
+	_, err := users.Delete(context.Background(), &pb.ById{Id: 7})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+	assert.Empty(t, f.users.deleted)
+	assert.False(t, f.logs.deleted)
+	assert.Empty(t, f.tags.cleaned)
+}
+
+func TestDeleteUserBlockAllowsNoRelatedRows(t *testing.T) {
+	f := newDeletionFixture(0, 4)
+	users := service.NewUserService(f.stg, service.DeletionPolicies{
+		Posts: service.DeletionBlock,
+		Logs:  service.DeletionBlock,
+	}, nil)
+
+	_, err := users.Delete(context.Background(), &pb.ById{Id: 7})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	f.logs.count = 0
+	resp, err := users.Delete(context.Background(), &pb.ById{Id: 7})
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{7}, f.users.deleted)
+	assert.False(t, f.posts.deleted || f.posts.anonymized)
+	assert.Empty(t, f.tags.cleaned)
+	assert.Len(t, resp.Relations, 2)
+}
@@ -2,19 +2,28 @@ package test
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	repo "posts/internal/repository/postgres"
 	us "posts/internal/repository/postgres/users"
+	"regexp"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"google.golang.org/grpc"
 	pb "posts/internal/pkg/genproto"
+	"posts/internal/usecase/service"
 )
 
 func TestNewRepository(t *testing.T) {
 	db, _, _ := sqlmock.New()
 	defer db.Close()
 
-	repo := us.NewRepository(db)
+	repo := us.NewRepository(bun.NewDB(db, pgdialect.New()))
 	assert.NotNil(t, repo)
 }
 
@@ -22,12 +31,11 @@ func TestCreateUser(t *testing.T) {
 	db, mock, _ := sqlmock.New()
 	defer db.Close()
 
-	repo := us.NewRepository(db)
+	repo := us.NewRepository(bun.NewDB(db, pgdialect.New()))
 	ctx := context.Background()
 
-	mock.ExpectExec("INSERT INTO users").
-		WithArgs("John", "Doe", "johndoe", "john@example.com", "+1234567890", "M", sqlmock.AnyArg(), "ADMIN").
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(`INSERT INTO "users" \("firstname", "lastname", "username", "email", "phone", "gender", "password", "role"\) VALUES \('John', 'Doe', 'johndoe', 'john@example.com', '\+1234567890', 'M', '\$2a\$.+', 'ADMIN'\) RETURNING id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 	req := &pb.UserCreateRequest{
 		FirstName:   "John",
@@ -49,11 +57,10 @@ func TestUpdateUser(t *testing.T) {
 	db, mock, _ := sqlmock.New()
 	defer db.Close()
 
-	repo := us.NewRepository(db)
+	repo := us.NewRepository(bun.NewDB(db, pgdialect.New()))
 	ctx := context.Background()
 
-	mock.ExpectExec("UPDATE users").
-		WithArgs("John", "Doe", 1).
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET updated_at = NOW(), firstname = 'John', lastname = 'Doe' WHERE (id = 1)`)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	req := &pb.UserUpdateRequest{
@@ -70,14 +77,143 @@ func TestDeleteUser(t *testing.T) {
 	db, mock, _ := sqlmock.New()
 	defer db.Close()
 
-	repo := us.NewRepository(db)
+	repo := us.NewRepository(bun.NewDB(db, pgdialect.New()))
 	ctx := context.Background()
 
-	mock.ExpectExec("UPDATE users SET deleted_at").
-		WithArgs(1).
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET deleted_at = NOW() WHERE (id = 1)`)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	req := &pb.ById{Id: 1}
 	_, err := repo.Delete(ctx, req)
 	assert.NoError(t, err)
 }
+
+func TestEraseUser(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := us.NewRepository(bun.NewDB(db, pgdialect.New()))
+	erasedAt := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "users" AS "u" SET firstname = NULL, lastname = NULL, username = NULL, phone = NULL, gender = NULL, email = 'erased-' || id || '@erased.invalid', password = '!', role = 'VIEWER', erased_at = NOW(), updated_at = NOW(), deleted_at = COALESCE(deleted_at, NOW()) WHERE (id = 1) AND (erased_at IS NULL) RETURNING erased_at`)).
+		WillReturnRows(sqlmock.NewRows([]string{"erased_at"}).AddRow(erasedAt))
+
+	resp, err := repo.Erase(context.Background(), &pb.ById{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-03-07T12:00:00Z", resp.ErasedAt)
+}
+
+func TestEraseUserAlreadyErased(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := us.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectQuery(`UPDATE "users"`).
+		WillReturnRows(sqlmock.NewRows([]string{"erased_at"}))
+
+	_, err := repo.Erase(context.Background(), &pb.ById{Id: 1})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestGetUserDetailSkipsDeletedUsers(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	repo := us.NewRepository(bun.NewDB(db, pgdialect.New()))
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "u"."id", "u"."firstname", "u"."lastname", "u"."username", "u"."email", "u"."phone", "u"."gender", "u"."role", "u"."created_at", "u"."created_by" FROM "users" AS "u" WHERE (id = 1) AND (deleted_at IS NULL)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err := repo.GetDetail(context.Background(), &pb.ById{Id: 1})
+	assert.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestEraseUserRemovesFollowsAndNotifications(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	users := service.NewUserService(repo.NewStorage(db), service.DeletionPolicies{}, nil)
+	erasedAt := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`UPDATE "users" AS "u" SET .* role = 'VIEWER', .* WHERE \(id = 1\) AND \(erased_at IS NULL\)`).
+		WillReturnRows(sqlmock.NewRows([]string{"erased_at"}).AddRow(erasedAt))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "follows" AS "f" WHERE (follower_id = 1 OR followee_id = 1)`)).
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "notifications" AS "n" WHERE (user_id = 1 OR actor_id = 1)`)).
+		WillReturnResult(sqlmock.NewResult(0, 6))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "notification_preferences" AS "np" WHERE (user_id = 1)`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(regexp.QuoteMeta(`DELETE FROM "notification_webhooks" AS "nw" WHERE (user_id = 1)`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	resp, err := users.Erase(context.Background(), &pb.ById{Id: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, "2024-03-07T12:00:00Z", resp.ErasedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// exportStream collects the chunks of an export.
+type exportStream struct {
+	grpc.ServerStream
+	data []byte
+}
+
+func (s *exportStream) Context() context.Context { return context.Background() }
+func (s *exportStream) Send(chunk *pb.UserExportChunk) error {
+	s.data = append(s.data, chunk.Data...)
+	return nil
+}
+
+func TestExportDataCoversUserRelations(t *testing.T) {
+	db, mock, _ := sqlmock.New()
+	defer db.Close()
+
+	users := service.NewUserService(repo.NewStorage(db), service.DeletionPolicies{}, nil)
+	created := time.Date(2024, 3, 7, 12, 0, 0, 0, time.UTC)
+
+	// deleted users are still exported
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "users" AS "u" WHERE (id = 1)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "username"}).AddRow(1, "johndoe"))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "posts" AS "p" WHERE (user_id = 1)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "comments" AS "c" WHERE (user_id = 1) ORDER BY "id"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "post_id", "user_id", "content"}).AddRow(10, 3, 1, "Nice post"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT "r"."post_id", "r"."user_id", "r"."kind", "r"."created_at" FROM "reactions" AS "r" WHERE (user_id = 1)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "user_id", "kind", "created_at"}).AddRow(3, 1, "like", created))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "follows" AS "f" WHERE (follower_id = 1 OR followee_id = 1)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"follower_id", "followee_id", "created_at"}).AddRow(1, 2, created))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "notifications" AS "n" WHERE (user_id = 1) ORDER BY "id"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "kind", "title"}).AddRow(5, 1, "follow", "Jane started following you"))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "notification_preferences" AS "np" WHERE (user_id = 1)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id", "kind", "channel", "enabled"}).AddRow(1, "follow", "email", false))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "notification_webhooks" AS "nw" WHERE (user_id = 1)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"url", "secret"}).AddRow("https://example.com/hook", "s3cret"))
+	mock.ExpectQuery(regexp.QuoteMeta(`FROM "logs" AS "l" WHERE (created_by = 1)`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	stream := &exportStream{}
+	assert.NoError(t, users.ExportData(&pb.UserExportRequest{Id: 1}, stream))
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	var export struct {
+		Comments      []map[string]any `json:"comments"`
+		Reactions     []map[string]any `json:"reactions"`
+		Follows       []map[string]any `json:"follows"`
+		Notifications struct {
+			Received    []map[string]any `json:"received"`
+			Preferences []map[string]any `json:"preferences"`
+			WebhookURL  string           `json:"webhook_url"`
+		} `json:"notifications"`
+	}
+	assert.NoError(t, json.Unmarshal(stream.data, &export))
+	assert.Len(t, export.Comments, 1)
+	assert.Equal(t, "like", export.Reactions[0]["kind"])
+	assert.Equal(t, float64(2), export.Follows[0]["followee_id"])
+	assert.Equal(t, "Jane started following you", export.Notifications.Received[0]["title"])
+	assert.Len(t, export.Notifications.Preferences, 1)
+	assert.Equal(t, "https://example.com/hook", export.Notifications.WebhookURL)
+	assert.NotContains(t, string(stream.data), "s3cret")
+}
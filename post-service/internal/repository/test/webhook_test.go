@@ -0,0 +1,181 @@
+package test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/webhook"
+	"posts/internal/repository/postgres/webhooks"
+	"posts/internal/usecase/service"
+)
+
+func newWebhookRepo(t *testing.T) (sqlmock.Sqlmock, *webhooks.Repository, func()) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to create mock db: %v", err)
+	}
+	return mock, webhooks.NewRepository(bun.NewDB(db, pgdialect.New())), func() { db.Close() }
+}
+
+func TestCreateWebhook(t *testing.T) {
+	mock, repo, done := newWebhookRepo(t)
+	defer done()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "webhooks" ("url", "events", "secret", "active", "created_by") VALUES ('https://example.com/hook', '{"post.published","user.followed"}', '0123456789abcdef', TRUE, 1) RETURNING id, created_at`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, time.Now()))
+
+	w, err := repo.Create(context.Background(), &pb.WebhookCreateRequest{
+		Url:       "https://example.com/hook",
+		Events:    []string{"post.published", "user.followed"},
+		Secret:    "0123456789abcdef",
+		CreatedBy: 1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), w.Id)
+	assert.Equal(t, "0123456789abcdef", w.Secret)
+	assert.True(t, w.Active)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestEnqueueWebhookDeliveries(t *testing.T) {
+	mock, repo, done := newWebhookRepo(t)
+	defer done()
+
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO webhook_deliveries (webhook_id, event, payload)
+		SELECT w.id, 'user.followed', '{"event":"user.followed"}' FROM webhooks AS w
+		WHERE w.active AND 'user.followed' = ANY(w.events)`)).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	n, err := repo.Enqueue(context.Background(), "user.followed", `{"event":"user.followed"}`)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookFailedDisables(t *testing.T) {
+	mock, repo, done := newWebhookRepo(t)
+	defer done()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "webhooks" AS "w" SET failure_count = failure_count + 1, active = failure_count + 1 < 20, disabled_at = CASE WHEN failure_count + 1 < 20 THEN disabled_at ELSE NOW() END WHERE (id = 3) AND (active) RETURNING active`)).
+		WillReturnRows(sqlmock.NewRows([]string{"active"}).AddRow(false))
+
+	disabled, err := repo.Failed(context.Background(), 3, 20)
+	assert.NoError(t, err)
+	assert.True(t, disabled)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookFailedAlreadyDisabled(t *testing.T) {
+	mock, repo, done := newWebhookRepo(t)
+	defer done()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`UPDATE "webhooks" AS "w" SET failure_count = failure_count + 1`)).
+		WillReturnRows(sqlmock.NewRows([]string{"active"}))
+
+	disabled, err := repo.Failed(context.Background(), 3, 20)
+	assert.NoError(t, err)
+	assert.False(t, disabled)
+}
+
+func TestRedeliverWebhook(t *testing.T) {
+	mock, repo, done := newWebhookRepo(t)
+	defer done()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO webhook_deliveries (webhook_id, event, payload, redelivery_of)
+		SELECT webhook_id, event, payload, id FROM webhook_deliveries
+		WHERE id = 9 AND webhook_id = 3
+		RETURNING *`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "event", "payload", "status", "attempts", "redelivery_of"}).
+			AddRow(12, 3, "post.published", `{"event":"post.published"}`, "pending", 0, 9))
+
+	d, err := repo.Redeliver(context.Background(), &pb.WebhookDeliveryRequest{Id: 9, WebhookId: 3})
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), d.Id)
+	assert.Equal(t, int64(9), d.RedeliveryOf)
+	assert.Equal(t, "pending", d.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestWebhookSignature(t *testing.T) {
+	body := []byte(`{"event":"post.published"}`)
+	sentAt := time.Unix(1700000000, 0)
+
+	header := webhook.Sign("secret", sentAt, body)
+	assert.Regexp(t, `^t=1700000000,v1=[0-9a-f]{64}$`, header)
+
+	assert.NoError(t, webhook.Verify("secret", header, body, 5*time.Minute, sentAt.Add(time.Minute)))
+	assert.ErrorIs(t, webhook.Verify("other", header, body, 5*time.Minute, sentAt), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("secret", header, []byte(`{}`), 5*time.Minute, sentAt), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("secret", header, body, 5*time.Minute, sentAt.Add(time.Hour)), webhook.ErrInvalidSignature)
+	assert.ErrorIs(t, webhook.Verify("secret", "v1=abc", body, 5*time.Minute, sentAt), webhook.ErrInvalidSignature)
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	assert.Equal(t, 30*time.Second, webhook.Backoff(1))
+	assert.Equal(t, time.Minute, webhook.Backoff(2))
+	assert.Equal(t, 4*time.Minute, webhook.Backoff(4))
+	assert.Equal(t, 6*time.Hour, webhook.Backoff(20))
+}
+
+func TestWebhookPublicAddresses(t *testing.T) {
+	for _, addr := range []string{"93.184.216.34", "2606:2800:220:1:248:1893:25c8:1946"} {
+		assert.True(t, webhook.Public(netip.MustParseAddr(addr)), addr)
+	}
+	for _, addr := range []string{
+		"127.0.0.1", "::1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"fe80::1", "fc00::1", "0.0.0.0", "::", "100.64.0.1", "::ffff:127.0.0.1", "224.0.0.1",
+	} {
+		assert.False(t, webhook.Public(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestCheckWebhookURL(t *testing.T) {
+	ctx := context.Background()
+
+	assert.NoError(t, webhook.CheckURL(ctx, "https://93.184.216.34/hook"))
+	for _, raw := range []string{"ftp://93.184.216.34/", "/hook", "https://", "not a url"} {
+		assert.ErrorIs(t, webhook.CheckURL(ctx, raw), webhook.ErrInvalidURL, raw)
+	}
+	for _, raw := range []string{"http://127.0.0.1:8080/", "http://localhost/", "http://[::1]/", "http://169.254.169.254/latest/meta-data"} {
+		assert.ErrorIs(t, webhook.CheckURL(ctx, raw), webhook.ErrForbiddenAddress, raw)
+	}
+}
+
+func TestWebhookClientRefusesInternalAddress(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer server.Close()
+
+	// a host that passed CheckURL may resolve elsewhere by the time of
+	// the request; the dial itself is refused
+	_, err := webhook.NewClient(time.Second).Post(server.URL, "application/json", nil)
+	assert.ErrorIs(t, err, webhook.ErrForbiddenAddress)
+	assert.False(t, hit)
+}
+
+func TestCreateWebhookRejectsInternalURL(t *testing.T) {
+	s := service.NewWebhookService(nil, service.WebhookOptions{})
+
+	_, err := s.Create(context.Background(), &pb.WebhookCreateRequest{
+		Url:    "http://169.254.169.254/latest/meta-data",
+		Events: []string{"post.published"},
+	})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = s.Update(context.Background(), &pb.WebhookUpdateRequest{Id: 1, Url: "http://localhost:7001"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
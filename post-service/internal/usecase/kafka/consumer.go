@@ -4,68 +4,146 @@ package kafka
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
 	"sync"
 
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel/trace"
+	"posts/internal/pkg/metrics"
+	"posts/internal/pkg/tracing"
 )
 
+// Handler processes a message in a context carrying the producer's trace.
+// Messages it fails are moved to the topic's dead-letter topic.
+type Handler func(ctx context.Context, message []byte) error
+
 type KafkaConsumerManager struct {
-	consumers map[string]*kafka.Reader
-	handlers  map[string]func(message []byte)
-	mu        sync.Mutex
+	consumers   map[string]*consumer
+	deadLetters KafkaProducer
+	mu          sync.Mutex
+}
+
+type consumer struct {
+	topic           string
+	groupID         string
+	deadLetterTopic string
+	reader          *kafka.Reader
+	handler         Handler
 }
 
-func NewKafkaConsumerManager() *KafkaConsumerManager {
+// NewKafkaConsumerManager returns a manager that publishes failed messages
+// with deadLetters; with nil they are dropped.
+func NewKafkaConsumerManager(deadLetters KafkaProducer) *KafkaConsumerManager {
 	return &KafkaConsumerManager{
-		consumers: make(map[string]*kafka.Reader),
-		handlers:  make(map[string]func(message []byte)),
+		consumers:   make(map[string]*consumer),
+		deadLetters: deadLetters,
 	}
 }
 
+// DeadLetterTopic returns the topic failed messages of topic are moved to.
+func DeadLetterTopic(topic string) string {
+	return topic + "-dlq"
+}
+
+// GroupDeadLetterTopic returns the topic messages of topic that the
+// handler of consumer group groupID failed are moved to; see
+// RegisterGroups.
+func GroupDeadLetterTopic(topic, groupID string) string {
+	return DeadLetterTopic(topic + "-" + groupID)
+}
+
 var ErrConsumerAlreadyExists = errors.New("consumer for this topic already exists")
 
-func (kcm *KafkaConsumerManager) RegisterConsumer(brokers []string, topic, groupID string, handler func(message []byte)) error {
+func (kcm *KafkaConsumerManager) RegisterConsumer(brokers []string, topic, groupID string, handler Handler) error {
 	kcm.mu.Lock()
 	defer kcm.mu.Unlock()
 
-	if _, exists := kcm.consumers[topic]; exists {
+	return kcm.register(topic, brokers, topic, groupID, DeadLetterTopic(topic), handler)
+}
+
+// RegisterGroups subscribes every handler to topic in a consumer group of
+// its own, named by its key. Each handler keeps its own offset, and a
+// message one of them fails is moved to that group's dead-letter topic
+// only, so the others don't see it again when it is replayed.
+func (kcm *KafkaConsumerManager) RegisterGroups(brokers []string, topic string, handlers map[string]Handler) error {
+	kcm.mu.Lock()
+	defer kcm.mu.Unlock()
+
+	for groupID, handler := range handlers {
+		key := topic + "/" + groupID
+		if err := kcm.register(key, brokers, topic, groupID, GroupDeadLetterTopic(topic, groupID), handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (kcm *KafkaConsumerManager) register(key string, brokers []string, topic, groupID, deadLetterTopic string, handler Handler) error {
+	if _, exists := kcm.consumers[key]; exists {
 		return ErrConsumerAlreadyExists
 	}
 
-	reader := kafka.NewReader(kafka.ReaderConfig{
-		Brokers: brokers,
-		Topic:   topic,
-		GroupID: groupID,
-	})
-	kcm.consumers[topic] = reader
-	kcm.handlers[topic] = handler
+	c := &consumer{
+		topic:           topic,
+		groupID:         groupID,
+		deadLetterTopic: deadLetterTopic,
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: brokers,
+			Topic:   topic,
+			GroupID: groupID,
+		}),
+		handler: handler,
+	}
+	kcm.consumers[key] = c
 
-	go kcm.consumeMessages(topic)
+	go kcm.consumeMessages(c)
 
 	return nil
 }
 
-func (kcm *KafkaConsumerManager) consumeMessages(topic string) {
-	reader := kcm.consumers[topic]
-	handler := kcm.handlers[topic]
-
+func (kcm *KafkaConsumerManager) consumeMessages(c *consumer) {
+	topic := c.topic
 	for {
-		msg, err := reader.ReadMessage(context.Background())
+		msg, err := c.reader.ReadMessage(context.Background())
 		if err != nil {
-			log.Printf("Error reading message from topic %s: %v", topic, err)
+			slog.Error("failed to read kafka message", "topic", topic, "group", c.groupID, "error", err)
 			continue
 		}
-		handler(msg.Value)
+		metrics.KafkaLag.WithLabelValues(topic, c.groupID).Set(float64(c.reader.Stats().Lag))
+
+		ctx, span := tracer.Start(tracing.ExtractKafka(context.Background(), &msg), topic+" process",
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(tracing.KafkaAttributes(topic)...),
+		)
+		err = c.handler(ctx, msg.Value)
+		metrics.KafkaConsumed.WithLabelValues(topic, c.groupID, metrics.Result(err)).Inc()
+		if err != nil {
+			kcm.deadLetter(ctx, c, msg.Value)
+		}
+		tracing.End(span, err)
+	}
+}
+
+// deadLetter moves a message that failed handling to the consumer's
+// dead-letter topic, where it can be inspected and replayed.
+func (kcm *KafkaConsumerManager) deadLetter(ctx context.Context, c *consumer, message []byte) {
+	if kcm.deadLetters == nil {
+		return
+	}
+	if err := kcm.deadLetters.ProduceMessages(ctx, c.deadLetterTopic, message); err != nil {
+		slog.Error("failed to dead-letter kafka message", "topic", c.topic, "group", c.groupID, "error", err)
+		return
 	}
+	metrics.KafkaDeadLettered.WithLabelValues(c.topic, c.groupID).Inc()
+	slog.Warn("moved kafka message to dead-letter topic", "topic", c.topic, "group", c.groupID, "dead_letter_topic", c.deadLetterTopic)
 }
 
 func (kcm *KafkaConsumerManager) Close() error {
 	kcm.mu.Lock()
 	defer kcm.mu.Unlock()
 
-	for _, reader := range kcm.consumers {
-		if err := reader.Close(); err != nil {
+	for _, c := range kcm.consumers {
+		if err := c.reader.Close(); err != nil {
 			return err
 		}
 	}
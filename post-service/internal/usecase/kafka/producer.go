@@ -4,10 +4,18 @@ import (
 	"context"
 
 	"github.com/segmentio/kafka-go"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
+	"posts/internal/pkg/metrics"
+	"posts/internal/pkg/tracing"
 )
 
+var tracer = otel.Tracer("posts/internal/usecase/kafka")
+
 type KafkaProducer interface {
-	ProduceMessages(topic string, message []byte) error
+	// ProduceMessages publishes message on topic, carrying the trace
+	// context of ctx in its headers.
+	ProduceMessages(ctx context.Context, topic string, message []byte) error
 	Close() error
 }
 
@@ -23,11 +31,19 @@ func NewKafkaProducer(brokers []string) (KafkaProducer, error) {
 	return &Producer{writer: writer}, nil
 }
 
-func (p *Producer) ProduceMessages(topic string, message []byte) error {
-	return p.writer.WriteMessages(context.Background(), kafka.Message{
-		Topic: topic,
-		Value: message,
-	})
+func (p *Producer) ProduceMessages(ctx context.Context, topic string, message []byte) error {
+	ctx, span := tracer.Start(ctx, topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(tracing.KafkaAttributes(topic)...),
+	)
+	msg := kafka.Message{Topic: topic, Value: message}
+	tracing.InjectKafka(ctx, &msg)
+
+	// the write is not abandoned when the caller's request ends
+	err := p.writer.WriteMessages(context.WithoutCancel(ctx), msg)
+	metrics.KafkaProduced.WithLabelValues(topic, metrics.Result(err)).Inc()
+	tracing.End(span, err)
+	return err
 }
 
 func (p *Producer) Close() error {
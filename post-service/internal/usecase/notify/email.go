@@ -0,0 +1,66 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/logger"
+)
+
+// Mailer sends plain-text email.
+type Mailer interface {
+	Send(ctx context.Context, to, subject, body string) error
+}
+
+// Email delivers notifications through a Mailer.
+type Email struct {
+	mailer Mailer
+}
+
+func NewEmail(mailer Mailer) *Email {
+	return &Email{mailer: mailer}
+}
+
+func (c *Email) Name() string {
+	return entity.ChannelEmail
+}
+
+func (c *Email) Deliver(ctx context.Context, to Recipient, n *pb.Notification) error {
+	if to.Email == "" {
+		return nil
+	}
+	return c.mailer.Send(ctx, to.Email, n.Title, n.Body)
+}
+
+// SMTPMailer sends email through an SMTP server. Auth may be nil for
+// servers that accept unauthenticated mail.
+type SMTPMailer struct {
+	Addr string
+	From string
+	Auth smtp.Auth
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, to, subject, body string) error {
+	// header values must not smuggle in extra headers
+	clean := strings.NewReplacer("\r", "", "\n", " ")
+	msg := fmt.Sprintf("From: %s\r\nTo: %s\r\nSubject: %s\r\nContent-Type: text/plain; charset=utf-8\r\n\r\n%s\r\n",
+		clean.Replace(m.From), clean.Replace(to), clean.Replace(subject), body)
+
+	if err := smtp.SendMail(m.Addr, m.Auth, m.From, []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("sending email to %s: %w", to, err)
+	}
+	return nil
+}
+
+// LogMailer only logs the email it is asked to send. It stands in for SMTP
+// in development.
+type LogMailer struct{}
+
+func (LogMailer) Send(ctx context.Context, to, subject, body string) error {
+	logger.FromContext(ctx).Info("email", "to", to, "subject", subject)
+	return nil
+}
@@ -0,0 +1,33 @@
+package notify
+
+import (
+	"context"
+
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/repository"
+)
+
+// InApp stores notifications for GET /api/v1/notifications.
+type InApp struct {
+	stg repository.NotificationI
+}
+
+func NewInApp(stg repository.NotificationI) *InApp {
+	return &InApp{stg: stg}
+}
+
+func (c *InApp) Name() string {
+	return entity.ChannelInApp
+}
+
+// Deliver stores n and fills in its id and creation time.
+func (c *InApp) Deliver(ctx context.Context, to Recipient, n *pb.Notification) error {
+	stored, err := c.stg.Create(ctx, n)
+	if err != nil {
+		return err
+	}
+	n.Id = stored.Id
+	n.CreatedAt = stored.CreatedAt
+	return nil
+}
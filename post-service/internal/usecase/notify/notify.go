@@ -0,0 +1,46 @@
+// Package notify delivers notifications over pluggable channels.
+package notify
+
+import (
+	"context"
+
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+)
+
+// Recipient is where a user's notifications can be delivered. Empty
+// addresses mean the user cannot be reached on that channel.
+type Recipient struct {
+	UserID        int64
+	Email         string
+	WebhookURL    string
+	WebhookSecret string
+}
+
+// Channel delivers notifications one way, such as by email.
+type Channel interface {
+	// Name is the channel's key in notification preferences.
+	Name() string
+	// Deliver sends n to the recipient. Channels the recipient has no
+	// address for return nil without sending anything.
+	Deliver(ctx context.Context, to Recipient, n *pb.Notification) error
+}
+
+// Kinds lists every notification kind users can set preferences for.
+var Kinds = []string{entity.NotifyFollow, entity.NotifyComment, entity.NotifyReply, entity.NotifyPasswordChanged}
+
+// Channels lists every channel users can set preferences for.
+var Channels = []string{entity.ChannelInApp, entity.ChannelEmail, entity.ChannelWebhook}
+
+// Default reports whether a kind is delivered on a channel when the user
+// has not said otherwise. Email is reserved for security notices; webhooks
+// get everything once the user sets a URL.
+func Default(kind, channel string) bool {
+	switch channel {
+	case entity.ChannelInApp, entity.ChannelWebhook:
+		return true
+	case entity.ChannelEmail:
+		return kind == entity.NotifyPasswordChanged
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/webhook"
+)
+
+// webhookTimeout bounds a single webhook request.
+const webhookTimeout = 10 * time.Second
+
+// Webhook posts notifications as JSON to a URL chosen by the user, signed
+// with the user's secret. Internal addresses are refused when dialing.
+type Webhook struct {
+	client *http.Client
+}
+
+func NewWebhook() *Webhook {
+	return &Webhook{client: webhook.NewClient(webhookTimeout)}
+}
+
+func (c *Webhook) Name() string {
+	return entity.ChannelWebhook
+}
+
+func (c *Webhook) Deliver(ctx context.Context, to Recipient, n *pb.Notification) error {
+	if to.WebhookURL == "" {
+		return nil
+	}
+
+	payload, err := protojson.Marshal(n)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, to.WebhookURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "posts-notifications")
+	req.Header.Set(webhook.EventHeader, n.Kind)
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(to.WebhookSecret, time.Now(), payload))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
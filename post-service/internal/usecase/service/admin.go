@@ -0,0 +1,34 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/logger"
+)
+
+// AdminService changes how the service runs without a restart.
+type AdminService struct {
+	log *logger.Logger
+	pb.UnimplementedAdminServiceServer
+}
+
+func NewAdminService(log *logger.Logger) *AdminService {
+	return &AdminService{log: log}
+}
+
+func (s *AdminService) GetLogLevel(ctx context.Context, request *pb.LogLevelRequest) (*pb.LogLevel, error) {
+	return &pb.LogLevel{Level: s.log.Level()}, nil
+}
+
+// SetLogLevel changes the least severe level logged until the next change
+// or restart.
+func (s *AdminService) SetLogLevel(ctx context.Context, request *pb.LogLevel) (*pb.LogLevel, error) {
+	if err := s.log.SetLevel(request.Level); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	logger.FromContext(ctx).Info("log level changed", "level", s.log.Level())
+	return &pb.LogLevel{Level: s.log.Level()}, nil
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/pkg/blob"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/logger"
+	"posts/internal/repository"
+)
+
+const (
+	// attachmentPrefix is the blob key prefix of every attachment.
+	attachmentPrefix = "attachments/"
+	// defaultURLTTL is how long a download URL stays valid when the caller
+	// does not ask for a specific lifetime.
+	defaultURLTTL = 15 * time.Minute
+	// maxURLTTL caps the lifetime callers may ask for.
+	maxURLTTL = 7 * 24 * time.Hour
+	// gcBatchSize is how many rows or keys the garbage collector handles
+	// per query.
+	gcBatchSize = 100
+)
+
+// AttachmentOptions configures AttachmentService.
+type AttachmentOptions struct {
+	// MaxSize is the largest accepted upload in bytes.
+	MaxSize int64
+	// URLSecret signs download URLs.
+	URLSecret string
+}
+
+type AttachmentService struct {
+	stg   repository.StorageI
+	blobs blob.Store
+	opts  AttachmentOptions
+	pb.UnimplementedAttachmentServiceServer
+}
+
+func NewAttachmentService(stg repository.StorageI, blobs blob.Store, opts AttachmentOptions) *AttachmentService {
+	return &AttachmentService{stg: stg, blobs: blobs, opts: opts}
+}
+
+// Upload stores a file sent as a stream of chunks. The first chunk carries
+// the metadata; the blob is written before the row, so a failed insert
+// leaves at most an orphaned blob for the garbage collector.
+func (s *AttachmentService) Upload(stream pb.AttachmentService_UploadServer) error {
+	ctx := stream.Context()
+
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	meta := first.Meta
+	if meta == nil {
+		return status.Error(codes.InvalidArgument, "first chunk must carry attachment metadata")
+	}
+	if meta.UserId == 0 {
+		return status.Error(codes.Unauthenticated, "uploader is required")
+	}
+	meta.FileName = path.Base(strings.ReplaceAll(strings.TrimSpace(meta.FileName), `\`, "/"))
+	if meta.FileName == "" || meta.FileName == "." || meta.FileName == "/" {
+		return status.Error(codes.InvalidArgument, "file name is required")
+	}
+	if meta.ContentType == "" {
+		return status.Error(codes.InvalidArgument, "content type is required")
+	}
+	if meta.Size <= 0 {
+		return status.Error(codes.InvalidArgument, "size must be positive")
+	}
+	if s.opts.MaxSize > 0 && meta.Size > s.opts.MaxSize {
+		return status.Errorf(codes.InvalidArgument, "file is larger than %d bytes", s.opts.MaxSize)
+	}
+
+	post, err := s.stg.Post().GetDetail(ctx, &pb.GetById{Id: meta.PostId})
+	if err != nil {
+		return notFound(err, "post")
+	}
+	if post.UserId != meta.UserId {
+		return status.Error(codes.PermissionDenied, "only the author can attach files to a post")
+	}
+
+	key, err := newAttachmentKey(meta.PostId)
+	if err != nil {
+		return err
+	}
+
+	body := &uploadReader{stream: stream, buf: first.Data, limit: meta.Size}
+	hash := sha256.New()
+	if err := s.blobs.Put(ctx, key, io.TeeReader(body, hash), meta.Size, meta.ContentType); err != nil {
+		var st interface{ GRPCStatus() *status.Status }
+		if errors.As(err, &st) {
+			return st.GRPCStatus().Err()
+		}
+		return fmt.Errorf("storing attachment: %w", err)
+	}
+	// Stores may stop reading after size bytes; anything left means the
+	// client sent more than it declared.
+	if _, err := io.Copy(io.Discard, body); err != nil || body.read != meta.Size {
+		s.discard(ctx, key)
+		return status.Errorf(codes.InvalidArgument, "received %d bytes, expected %d", body.read, meta.Size)
+	}
+
+	attachment, err := s.stg.Attachment().Create(ctx, &pb.Attachment{
+		PostId:      meta.PostId,
+		UserId:      meta.UserId,
+		FileName:    meta.FileName,
+		ContentType: meta.ContentType,
+		Size:        meta.Size,
+		Checksum:    hex.EncodeToString(hash.Sum(nil)),
+	}, key)
+	if err != nil {
+		s.discard(ctx, key)
+		return err
+	}
+	return stream.SendAndClose(attachment)
+}
+
+// GetList lists the attachments of a post the viewer can see.
+func (s *AttachmentService) GetList(ctx context.Context, request *pb.AttachmentListRequest) (*pb.AttachmentList, error) {
+	if err := checkVisible(ctx, s.stg, request.PostId, request.ViewerId); err != nil {
+		return nil, err
+	}
+	return s.stg.Attachment().GetList(ctx, request)
+}
+
+// Delete hides an attachment; its blob is removed by the garbage
+// collector. The uploader and the post's author may delete it.
+func (s *AttachmentService) Delete(ctx context.Context, request *pb.AttachmentDeleteRequest) (*pb.AttachmentVoid, error) {
+	if request.UserId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "user is required")
+	}
+	found, err := s.stg.Attachment().GetDetail(ctx, request.Id)
+	if err != nil {
+		return nil, notFound(err, "attachment")
+	}
+	attachment := found.ToProto()
+	if attachment.UserId != request.UserId {
+		post, err := s.stg.Post().GetDetail(ctx, &pb.GetById{Id: attachment.PostId})
+		if err != nil || post.UserId != request.UserId {
+			return nil, status.Error(codes.PermissionDenied, "attachment belongs to another user")
+		}
+	}
+	return s.stg.Attachment().Delete(ctx, request)
+}
+
+// Sign returns a signature that grants access to an attachment's content
+// until it expires. Only viewers who can see the post get one.
+func (s *AttachmentService) Sign(ctx context.Context, request *pb.AttachmentSignRequest) (*pb.AttachmentSignature, error) {
+	found, err := s.stg.Attachment().GetDetail(ctx, request.Id)
+	if err != nil {
+		return nil, notFound(err, "attachment")
+	}
+	if err := checkVisible(ctx, s.stg, found.ToProto().PostId, request.ViewerId); err != nil {
+		return nil, status.Error(codes.NotFound, "attachment not found")
+	}
+
+	ttl := time.Duration(request.TtlSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = defaultURLTTL
+	}
+	if ttl > maxURLTTL {
+		ttl = maxURLTTL
+	}
+	expires := time.Now().Add(ttl).Unix()
+
+	return &pb.AttachmentSignature{
+		Id:        request.Id,
+		Expires:   expires,
+		Signature: s.signature(request.Id, expires),
+	}, nil
+}
+
+// Download streams an attachment to holders of a valid signature. Only
+// the first chunk carries the content type, file name and size.
+func (s *AttachmentService) Download(request *pb.AttachmentDownloadRequest, stream pb.AttachmentService_DownloadServer) error {
+	ctx := stream.Context()
+
+	if request.Expires < time.Now().Unix() {
+		return status.Error(codes.PermissionDenied, "download link has expired")
+	}
+	if !hmac.Equal([]byte(request.Signature), []byte(s.signature(request.Id, request.Expires))) {
+		return status.Error(codes.PermissionDenied, "invalid download signature")
+	}
+
+	found, err := s.stg.Attachment().GetDetail(ctx, request.Id)
+	if err != nil {
+		return notFound(err, "attachment")
+	}
+	attachment := found.ToProto()
+	r, err := s.blobs.Get(ctx, *found.StorageKey)
+	if errors.Is(err, blob.ErrNotFound) {
+		return status.Errorf(codes.NotFound, "attachment %d has no content", request.Id)
+	}
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	buf := make([]byte, streamChunkSize)
+	sent := false
+	for {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 || !sent {
+			chunk := &pb.AttachmentChunk{Data: append([]byte(nil), buf[:n]...)}
+			if !sent {
+				chunk.ContentType = attachment.ContentType
+				chunk.FileName = attachment.FileName
+				chunk.Size = attachment.Size
+				sent = true
+			}
+			if err := stream.Send(chunk); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// CollectGarbage removes the blobs of deleted attachments and of deleted
+// posts, then blobs older than grace that no attachment refers to, such
+// as leftovers of interrupted uploads. It returns the number of blobs
+// removed.
+func (s *AttachmentService) CollectGarbage(ctx context.Context, grace time.Duration) (int, error) {
+	removed := 0
+
+	for {
+		garbage, err := s.stg.Attachment().Garbage(ctx, gcBatchSize)
+		if err != nil {
+			return removed, err
+		}
+		ids := make([]int64, 0, len(garbage))
+		for _, attachment := range garbage {
+			if err := s.blobs.Delete(ctx, *attachment.StorageKey); err != nil {
+				return removed, fmt.Errorf("deleting blob %s: %w", *attachment.StorageKey, err)
+			}
+			ids = append(ids, attachment.ID)
+			removed++
+		}
+		if err := s.stg.Attachment().Purge(ctx, ids); err != nil {
+			return removed, err
+		}
+		if len(garbage) < gcBatchSize {
+			break
+		}
+	}
+
+	cutoff := time.Now().Add(-grace)
+	var candidates []string
+	sweep := func() error {
+		known, err := s.stg.Attachment().KnownKeys(ctx, candidates)
+		if err != nil {
+			return err
+		}
+		for _, key := range candidates {
+			if known[key] {
+				continue
+			}
+			if err := s.blobs.Delete(ctx, key); err != nil {
+				return fmt.Errorf("deleting blob %s: %w", key, err)
+			}
+			removed++
+		}
+		candidates = candidates[:0]
+		return nil
+	}
+
+	err := s.blobs.List(ctx, attachmentPrefix, func(info blob.Info) error {
+		if info.Modified.After(cutoff) {
+			return nil
+		}
+		candidates = append(candidates, info.Key)
+		if len(candidates) < gcBatchSize {
+			return nil
+		}
+		return sweep()
+	})
+	if err != nil {
+		return removed, err
+	}
+	return removed, sweep()
+}
+
+func (s *AttachmentService) signature(id, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(s.opts.URLSecret))
+	mac.Write([]byte(strconv.FormatInt(id, 10) + ":" + strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// discard removes a blob whose upload could not be completed. Failures
+// are only logged: the garbage collector picks the blob up later.
+func (s *AttachmentService) discard(ctx context.Context, key string) {
+	if err := s.blobs.Delete(context.WithoutCancel(ctx), key); err != nil {
+		logger.FromContext(ctx).Warn("failed to discard blob", "key", key, "error", err)
+	}
+}
+
+func newAttachmentKey(postID int64) (string, error) {
+	var random [16]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s%d/%s", attachmentPrefix, postID, hex.EncodeToString(random[:])), nil
+}
+
+// uploadReader reads the data of an upload stream, failing once more than
+// limit bytes arrive.
+type uploadReader struct {
+	stream pb.AttachmentService_UploadServer
+	buf    []byte
+	limit  int64
+	read   int64
+	done   bool
+}
+
+func (r *uploadReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.done {
+			return 0, io.EOF
+		}
+		chunk, err := r.stream.Recv()
+		if err == io.EOF {
+			r.done = true
+			continue
+		}
+		if err != nil {
+			return 0, err
+		}
+		r.buf = chunk.Data
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	r.read += int64(n)
+	if r.read > r.limit {
+		return n, status.Errorf(codes.InvalidArgument, "received more than the declared %d bytes", r.limit)
+	}
+	return n, nil
+}
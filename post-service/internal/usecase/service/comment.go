@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/repository"
+	"posts/internal/usecase/kafka"
+)
+
+type CommentService struct {
+	stg      repository.StorageI
+	producer kafka.KafkaProducer
+	pb.UnimplementedCommentServiceServer
+}
+
+func NewCommentService(stg repository.StorageI, producer kafka.KafkaProducer) *CommentService {
+	return &CommentService{stg: stg, producer: producer}
+}
+
+// Create adds a comment or a reply to a live post and bumps the post's
+// comment counter in the same transaction. Posts the author can't see
+// can't be commented on.
+func (s *CommentService) Create(ctx context.Context, request *pb.CommentCreateRequest) (*pb.CommentGet, error) {
+	if request.UserId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "comment author is required")
+	}
+	request.Content = strings.TrimSpace(request.Content)
+	if request.Content == "" {
+		return nil, status.Error(codes.InvalidArgument, "comment content is empty")
+	}
+
+	var comment *pb.CommentGet
+	err := s.stg.WithTx(ctx, func(tx repository.StorageI) error {
+		if err := checkVisible(ctx, tx, request.PostId, request.UserId); err != nil {
+			return err
+		}
+		if request.ParentId != 0 {
+			parent, err := tx.Comment().GetDetail(ctx, request.ParentId)
+			if err != nil {
+				return notFound(err, "parent comment")
+			}
+			if parent.PostId != request.PostId {
+				return status.Error(codes.InvalidArgument, "parent comment belongs to another post")
+			}
+		}
+
+		if err := tx.Post().AddComments(ctx, request.PostId, 1); err != nil {
+			return notFound(err, "post")
+		}
+
+		var err error
+		comment, err = tx.Comment().Create(ctx, request)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	emit(ctx, s.producer, TopicCommentCreated, comment)
+	return comment, nil
+}
+
+// Update changes the content of a comment; only its author may edit it.
+func (s *CommentService) Update(ctx context.Context, request *pb.CommentUpdateRequest) (*pb.CommentVoid, error) {
+	request.Content = strings.TrimSpace(request.Content)
+	if request.Content == "" {
+		return nil, status.Error(codes.InvalidArgument, "comment content is empty")
+	}
+
+	comment, err := s.stg.Comment().GetDetail(ctx, request.Id)
+	if err != nil {
+		return nil, notFound(err, "comment")
+	}
+	if request.PostId != 0 && comment.PostId != request.PostId {
+		return nil, status.Error(codes.NotFound, "comment not found")
+	}
+	if comment.UserId == 0 || comment.UserId != request.UserId {
+		return nil, status.Error(codes.PermissionDenied, "only the author can edit a comment")
+	}
+
+	return s.stg.Comment().Update(ctx, request)
+}
+
+// Delete soft-deletes a comment. The comment's author and the author of the
+// post it belongs to may delete it.
+func (s *CommentService) Delete(ctx context.Context, request *pb.CommentDeleteRequest) (*pb.CommentVoid, error) {
+	err := s.stg.WithTx(ctx, func(tx repository.StorageI) error {
+		comment, err := tx.Comment().GetDetail(ctx, request.Id)
+		if err != nil {
+			return notFound(err, "comment")
+		}
+		if request.PostId != 0 && comment.PostId != request.PostId {
+			return status.Error(codes.NotFound, "comment not found")
+		}
+
+		if comment.UserId == 0 || comment.UserId != request.UserId {
+			post, err := tx.Post().GetDetail(ctx, &pb.GetById{Id: comment.PostId})
+			if err != nil {
+				return notFound(err, "post")
+			}
+			if post.UserId == 0 || post.UserId != request.UserId {
+				return status.Error(codes.PermissionDenied, "only the comment or post author can delete a comment")
+			}
+		}
+
+		if _, err := tx.Comment().Delete(ctx, request); err != nil {
+			return err
+		}
+		return tx.Post().AddComments(ctx, comment.PostId, -1)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.CommentVoid{}, nil
+}
+
+// GetList lists comments of a post the viewer can see.
+func (s *CommentService) GetList(ctx context.Context, request *pb.FilterComment) (*pb.CommentGetAll, error) {
+	if err := checkVisible(ctx, s.stg, request.PostId, request.ViewerId); err != nil {
+		return nil, err
+	}
+	return s.stg.Comment().GetList(ctx, request)
+}
+
+// notFound maps a missing row to a NotFound status and passes other errors
+// through unchanged.
+func notFound(err error, object string) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return status.Error(codes.NotFound, object+" not found")
+	}
+	return err
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"posts/internal/pkg/logger"
+	"posts/internal/usecase/kafka"
+)
+
+// Topics of the domain events the services emit.
+const (
+	TopicPostPublished   = "post-published"
+	TopicUserFollowed    = "user-followed"
+	TopicCommentCreated  = "comment-created"
+	TopicPasswordChanged = "password-changed"
+)
+
+// emit sends a domain event after the change it describes is committed. A
+// failed send is logged and not retried.
+func emit(ctx context.Context, producer kafka.KafkaProducer, topic string, event proto.Message) {
+	payload, err := protojson.Marshal(event)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to marshal event", "topic", topic, "error", err)
+		return
+	}
+	if err := producer.ProduceMessages(ctx, topic, payload); err != nil {
+		logger.FromContext(ctx).Error("failed to produce event", "topic", topic, "error", err)
+	}
+}
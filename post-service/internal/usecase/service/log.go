@@ -3,7 +3,12 @@ package service
 import (
 	"context"
 	"posts/internal/usecase/kafka"
+	"slices"
+	"strings"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/pkg/cursor"
 	pb "posts/internal/pkg/genproto"
 	"posts/internal/repository"
 )
@@ -11,24 +16,95 @@ import (
 type LogService struct {
 	stg      repository.StorageI
 	producer kafka.KafkaProducer
+	inserted LogNotifier
 	pb.UnimplementedLogServiceServer
 }
 
-func NewLogService(stg repository.StorageI, kafka kafka.KafkaProducer) *LogService {
-	return &LogService{stg: stg}
+// NewLogService returns the log service; Tail is unavailable when inserted
+// is nil.
+func NewLogService(stg repository.StorageI, kafka kafka.KafkaProducer, inserted LogNotifier) *LogService {
+	return &LogService{stg: stg, inserted: inserted}
 }
 func (s *LogService) Create(ctx context.Context, request *pb.LogCreateRequest) (*pb.LogCreateResponse, error) {
+	level, ok := logLevel(request.Level)
+	if !ok {
+		return nil, status.Errorf(codes.InvalidArgument, "level must be one of %v", storedLevels)
+	}
+	request.Level = level
 	return s.stg.Log().Create(ctx, request)
 }
 func (s *LogService) GetDetail(ctx context.Context, request *pb.GetId) (*pb.LogGetResponse, error) {
 	return s.stg.Log().GetDetail(ctx, request)
 }
+
+const (
+	defaultLogLimit = 50
+	maxLogLimit     = 500
+)
+
+// GetList pages through logs by cursor, or by page number when one is
+// given.
 func (s *LogService) GetList(ctx context.Context, request *pb.FilterLog) (*pb.LogGetAll, error) {
-	return s.stg.Log().GetList(ctx, request)
+	if err := validateLogFilter(request); err != nil {
+		return nil, err
+	}
+	if request.Page > 0 {
+		return s.stg.Log().GetList(ctx, request, nil)
+	}
+
+	if request.Limit <= 0 {
+		request.Limit = defaultLogLimit
+	}
+	if request.Limit > maxLogLimit {
+		request.Limit = maxLogLimit
+	}
+
+	after, err := cursor.Decode(request.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return s.stg.Log().GetList(ctx, request, after)
 }
 func (s *LogService) Update(ctx context.Context, request *pb.LogUpdateRequest) (*pb.LogVoid, error) {
+	if request.Level != "" {
+		level, ok := logLevel(request.Level)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "level must be one of %v", storedLevels)
+		}
+		request.Level = level
+	}
 	return s.stg.Log().Update(ctx, request)
 }
 func (s *LogService) Delete(ctx context.Context, request *pb.GetId) (*pb.LogVoid, error) {
 	return s.stg.Log().Delete(ctx, request)
 }
+
+// storedLevels are the levels the logs table accepts, from least to most
+// severe.
+var storedLevels = []string{"TRACE", "DEBUG", "INFO", "WARNING", "ERROR", "FATAL"}
+
+// logLevel returns the stored form of a level. Case is ignored and WARN,
+// as the services' loggers name it, stands for WARNING.
+func logLevel(level string) (string, bool) {
+	level = strings.ToUpper(level)
+	if level == "WARN" {
+		level = "WARNING"
+	}
+	return level, slices.Contains(storedLevels, level)
+}
+
+// Ingest stores a batch shipped by a service's logging sink. Records with
+// an unknown level or no message are skipped.
+func (s *LogService) Ingest(ctx context.Context, batch *pb.LogBatch) (int64, error) {
+	records := make([]*pb.LogRecord, 0, len(batch.Record))
+	for _, record := range batch.Record {
+		level, ok := logLevel(record.Level)
+		if record.Message == "" || !ok {
+			continue
+		}
+		record.Level = level
+		records = append(records, record)
+	}
+	return s.stg.Log().CreateBatch(ctx, records)
+}
@@ -0,0 +1,125 @@
+package service
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"posts/internal/entity"
+	"posts/internal/pkg/blob"
+	"posts/internal/pkg/logger"
+	"posts/internal/repository"
+)
+
+const (
+	// logArchivePrefix is the blob key prefix of archived log partitions.
+	logArchivePrefix = "log-archive/"
+	// logPartitionsAhead is how many months past the current one get a
+	// partition before logs arrive for them.
+	logPartitionsAhead = 2
+)
+
+type LogRetentionOptions struct {
+	// Retention is how long logs are kept. A monthly partition expires
+	// once its newest possible log is older; zero keeps logs forever.
+	Retention time.Duration
+	// Archive receives expired partitions as gzipped NDJSON before they
+	// are dropped; nil drops them without a copy.
+	Archive blob.Store
+}
+
+// LogRetention maintains the monthly partitions of the logs table.
+type LogRetention struct {
+	stg  repository.StorageI
+	opts LogRetentionOptions
+}
+
+func NewLogRetention(stg repository.StorageI, opts LogRetentionOptions) *LogRetention {
+	return &LogRetention{stg: stg, opts: opts}
+}
+
+// Enforce creates the partitions of the coming months and archives and
+// drops expired ones. It returns how many partitions were dropped.
+func (r *LogRetention) Enforce(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	// step from the first of the month: Jan 31 plus a month is March 3
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i <= logPartitionsAhead; i++ {
+		if err := r.stg.Log().CreatePartition(ctx, entity.LogPartitionFor(month.AddDate(0, i, 0))); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.opts.Retention <= 0 {
+		return 0, nil
+	}
+
+	partitions, err := r.stg.Log().Partitions(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	cutoff := now.Add(-r.opts.Retention)
+	dropped := 0
+	for _, p := range partitions {
+		if p.To.After(cutoff) {
+			break
+		}
+		if r.opts.Archive != nil {
+			if err := r.archive(ctx, p); err != nil {
+				return dropped, err
+			}
+		}
+		if err := r.stg.Log().DropPartition(ctx, p); err != nil {
+			return dropped, err
+		}
+		logger.FromContext(ctx).Info("dropped expired log partition", "partition", p.Name, "archived", r.opts.Archive != nil)
+		dropped++
+	}
+	return dropped, nil
+}
+
+// archive writes the partition to the archive store as one gzipped NDJSON
+// blob. The blob is staged in a temporary file because the store needs its
+// size up front.
+func (r *LogRetention) archive(ctx context.Context, p entity.LogPartition) error {
+	tmp, err := os.CreateTemp("", p.Name+"-*.ndjson.gz")
+	if err != nil {
+		return fmt.Errorf("archiving log partition %s: %w", p.Name, err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	buf := bufio.NewWriter(tmp)
+	zw := gzip.NewWriter(buf)
+	enc := json.NewEncoder(zw)
+	err = r.stg.Log().ExportPartition(ctx, p, func(log *entity.Log) error {
+		return enc.Encode(log)
+	})
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = buf.Flush()
+	}
+	if err != nil {
+		return fmt.Errorf("archiving log partition %s: %w", p.Name, err)
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err == nil {
+		_, err = tmp.Seek(0, io.SeekStart)
+	}
+	if err == nil {
+		err = r.opts.Archive.Put(ctx, logArchivePrefix+p.Name+".ndjson.gz", tmp, size, "application/gzip")
+	}
+	if err != nil {
+		return fmt.Errorf("archiving log partition %s: %w", p.Name, err)
+	}
+	return nil
+}
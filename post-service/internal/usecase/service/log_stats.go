@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "posts/internal/pkg/genproto"
+)
+
+// logBucket bounds the range Stats counts per bucket size: the range used
+// when none is given, and the longest one allowed.
+type logBucket struct {
+	defaultRange time.Duration
+	maxRange     time.Duration
+}
+
+var logBuckets = map[string]logBucket{
+	"minute": {defaultRange: time.Hour, maxRange: 24 * time.Hour},
+	"hour":   {defaultRange: 24 * time.Hour, maxRange: 31 * 24 * time.Hour},
+	"day":    {defaultRange: 30 * 24 * time.Hour, maxRange: 366 * 24 * time.Hour},
+}
+
+// Stats counts the logs matching the filter by level and service name and,
+// when a bucket is given, by time. Bucketed counts cover a bounded range
+// that ends now unless to is given.
+func (s *LogService) Stats(ctx context.Context, request *pb.LogStatsRequest) (*pb.LogStats, error) {
+	filter := request.Filter
+	if filter == nil {
+		filter = &pb.FilterLog{}
+	}
+	if err := validateLogFilter(filter); err != nil {
+		return nil, err
+	}
+
+	if request.Bucket != "" {
+		bucket, ok := logBuckets[request.Bucket]
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, "bucket must be minute, hour or day")
+		}
+
+		to := time.Now().UTC()
+		if filter.To != "" {
+			to, _ = time.Parse(time.RFC3339, filter.To)
+		}
+		from := to.Add(-bucket.defaultRange)
+		if filter.From != "" {
+			from, _ = time.Parse(time.RFC3339, filter.From)
+		}
+		if to.Sub(from) > bucket.maxRange {
+			return nil, status.Errorf(codes.InvalidArgument, "%s buckets cover at most %s", request.Bucket, bucket.maxRange)
+		}
+		filter.From = from.UTC().Format(time.RFC3339)
+		filter.To = to.UTC().Format(time.RFC3339)
+	}
+
+	counts, err := s.stg.Log().Stats(ctx, filter, request.Bucket)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	response := &pb.LogStats{
+		Row:  make([]*pb.LogStatsRow, 0, len(counts)),
+		From: filter.From,
+		To:   filter.To,
+	}
+	for i := range counts {
+		response.Row = append(response.Row, counts[i].ToProto())
+		response.Total += counts[i].Count
+	}
+	return response, nil
+}
+
+// validateLogFilter checks the levels and time range of a filter.
+func validateLogFilter(filter *pb.FilterLog) error {
+	if filter.Level != "" {
+		level, ok := logLevel(filter.Level)
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "level must be one of %v", storedLevels)
+		}
+		filter.Level = level
+	}
+	for i, level := range filter.Levels {
+		level, ok := logLevel(level)
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "level must be one of %v", storedLevels)
+		}
+		filter.Levels[i] = level
+	}
+
+	var from, to time.Time
+	var err error
+	if filter.From != "" {
+		if from, err = time.Parse(time.RFC3339, filter.From); err != nil {
+			return status.Error(codes.InvalidArgument, "from must be an RFC3339 time")
+		}
+	}
+	if filter.To != "" {
+		if to, err = time.Parse(time.RFC3339, filter.To); err != nil {
+			return status.Error(codes.InvalidArgument, "to must be an RFC3339 time")
+		}
+	}
+	if filter.From != "" && filter.To != "" && !from.Before(to) {
+		return status.Error(codes.InvalidArgument, "from must be before to")
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	pb "posts/internal/pkg/genproto"
+)
+
+const (
+	// tailBatchSize is the most logs Tail reads per query.
+	tailBatchSize = 200
+	// tailPollInterval makes Tail look for new logs even without a
+	// notification, in case one was lost.
+	tailPollInterval = 30 * time.Second
+	// tailLookback is how many ids below the newest sent log Tail reads
+	// again. Ids are taken when a row is inserted but become visible on
+	// commit, so a log can show up after one with a greater id; as long
+	// as the gap stays within this window it is still sent.
+	tailLookback = 1000
+)
+
+// LogNotifier wakes subscribers after logs are inserted; see
+// postgres.Listener.
+type LogNotifier interface {
+	Subscribe() (<-chan struct{}, func())
+}
+
+// Tail sends logs matching the request as they are inserted, in id order
+// except for logs committed late, which follow when they become visible.
+// Logs committed more than tailLookback ids late are missed.
+func (s *LogService) Tail(request *pb.LogTailRequest, stream pb.LogService_TailServer) error {
+	ctx := stream.Context()
+	if s.inserted == nil {
+		return status.Error(codes.Unavailable, "log tail is not available")
+	}
+	if request.Level != "" {
+		level, ok := logLevel(request.Level)
+		if !ok {
+			return status.Errorf(codes.InvalidArgument, "level must be one of %v", storedLevels)
+		}
+		request.Level = level
+	}
+	if request.AfterId < 0 {
+		return status.Error(codes.InvalidArgument, "after_id must not be negative")
+	}
+
+	// subscribe before reading the last id so that no insert falls
+	// between the two
+	wake, stop := s.inserted.Subscribe()
+	defer stop()
+
+	after := request.AfterId
+	if after == 0 {
+		var err error
+		if after, err = s.stg.Log().LastID(ctx); err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	// headers tell the caller that the tail has started
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(tailPollInterval)
+	defer ticker.Stop()
+
+	// ids above floor are read on every pass; sent keeps the logs among
+	// them from going out twice
+	start := after
+	floor := after
+	sent := make(map[int64]bool)
+
+	for {
+		for cursor := floor; ; {
+			logs, err := s.stg.Log().Since(ctx, request, cursor, tailBatchSize)
+			if err != nil {
+				return status.Error(codes.Internal, err.Error())
+			}
+			for _, log := range logs {
+				cursor = log.Id
+				if sent[log.Id] {
+					continue
+				}
+				if err := stream.Send(log); err != nil {
+					return err
+				}
+				sent[log.Id] = true
+				after = max(after, log.Id)
+			}
+			if len(logs) < tailBatchSize {
+				break
+			}
+		}
+
+		// the caller has seen everything up to start already
+		floor = max(start, after-tailLookback)
+		for id := range sent {
+			if id <= floor {
+				delete(sent, id)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-wake:
+		case <-ticker.C:
+		}
+	}
+}
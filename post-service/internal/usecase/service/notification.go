@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/logger"
+	"posts/internal/pkg/metrics"
+	"posts/internal/repository"
+	"posts/internal/usecase/notify"
+)
+
+// commentExcerpt bounds how much of a comment a notification quotes.
+const commentExcerpt = 140
+
+type NotificationService struct {
+	stg      repository.StorageI
+	channels []notify.Channel
+	pb.UnimplementedNotificationServiceServer
+}
+
+// NewNotificationService delivers notifications over the given channels,
+// in order; put the in-app channel first so that later channels see the
+// stored notification's id.
+func NewNotificationService(stg repository.StorageI, channels ...notify.Channel) *NotificationService {
+	return &NotificationService{stg: stg, channels: channels}
+}
+
+func (s *NotificationService) GetList(ctx context.Context, request *pb.NotificationListRequest) (*pb.NotificationList, error) {
+	if request.UserId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "user is required")
+	}
+	return s.stg.Notification().GetList(ctx, request)
+}
+
+// MarkRead marks some or all of a user's notifications as read. Ids of
+// other users' notifications are ignored.
+func (s *NotificationService) MarkRead(ctx context.Context, request *pb.NotificationReadRequest) (*pb.NotificationReadResponse, error) {
+	if request.UserId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "user is required")
+	}
+	if !request.All && len(request.Ids) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "no notifications given")
+	}
+
+	updated, err := s.stg.Notification().MarkRead(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	return &pb.NotificationReadResponse{Updated: updated}, nil
+}
+
+// GetPreferences returns the user's setting for every kind and channel,
+// with defaults filled in.
+func (s *NotificationService) GetPreferences(ctx context.Context, request *pb.NotificationPreferencesRequest) (*pb.NotificationPreferences, error) {
+	if request.UserId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "user is required")
+	}
+
+	enabled, err := s.preferences(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+	webhook, err := s.stg.Notification().Webhook(ctx, request.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &pb.NotificationPreferences{UserId: request.UserId, WebhookUrl: webhook.URL}
+	for _, kind := range notify.Kinds {
+		for _, channel := range notify.Channels {
+			response.Preference = append(response.Preference, &pb.NotificationPreference{
+				Kind:    kind,
+				Channel: channel,
+				Enabled: enabled(kind, channel),
+			})
+		}
+	}
+	return response, nil
+}
+
+// UpdatePreferences changes the given preferences and the webhook URL and
+// returns the resulting settings. Setting the URL gives the webhook a new
+// secret, which only this response carries.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, request *pb.NotificationPreferences) (*pb.NotificationPreferences, error) {
+	if request.UserId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "user is required")
+	}
+	for _, p := range request.Preference {
+		p.Kind = strings.ToLower(strings.TrimSpace(p.Kind))
+		p.Channel = strings.ToLower(strings.TrimSpace(p.Channel))
+		if !slices.Contains(notify.Kinds, p.Kind) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown notification kind %q", p.Kind)
+		}
+		if !slices.Contains(notify.Channels, p.Channel) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown notification channel %q", p.Channel)
+		}
+	}
+	webhook := strings.TrimSpace(request.WebhookUrl)
+	var secret string
+	if webhook != "" {
+		if err := checkURL(ctx, webhook); err != nil {
+			return nil, err
+		}
+		var err error
+		if secret, err = newSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	err := s.stg.WithTx(ctx, func(tx repository.StorageI) error {
+		if err := tx.Notification().SetPreferences(ctx, request.UserId, request.Preference); err != nil {
+			return err
+		}
+		if webhook != "" || request.ClearWebhookUrl {
+			return tx.Notification().SetWebhook(ctx, request.UserId, webhook, secret)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.GetPreferences(ctx, &pb.NotificationPreferencesRequest{UserId: request.UserId})
+	if err != nil {
+		return nil, err
+	}
+	response.WebhookSecret = secret
+	return response, nil
+}
+
+// Notify delivers n on every channel its recipient has enabled for its
+// kind. A failing channel does not stop the others, and is logged and
+// counted rather than returned: a retry of the event would repeat the
+// deliveries that succeeded, such as the stored in-app notification.
+func (s *NotificationService) Notify(ctx context.Context, n *pb.Notification) error {
+	enabled, err := s.preferences(ctx, n.UserId)
+	if err != nil {
+		return err
+	}
+
+	to := notify.Recipient{UserID: n.UserId}
+	if enabled(n.Kind, entity.ChannelEmail) {
+		user, err := s.stg.User().GetDetail(ctx, &pb.ById{Id: n.UserId})
+		if errors.Is(err, sql.ErrNoRows) {
+			// deleted users are not notified
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		to.Email = user.Email
+	}
+	if enabled(n.Kind, entity.ChannelWebhook) {
+		webhook, err := s.stg.Notification().Webhook(ctx, n.UserId)
+		if err != nil {
+			return err
+		}
+		to.WebhookURL, to.WebhookSecret = webhook.URL, webhook.Secret
+	}
+
+	for _, channel := range s.channels {
+		if !enabled(n.Kind, channel.Name()) {
+			continue
+		}
+		err := channel.Deliver(ctx, to, n)
+		metrics.NotificationsDelivered.WithLabelValues(channel.Name(), metrics.Result(err)).Inc()
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to deliver notification",
+				"channel", channel.Name(), "user_id", n.UserId, "kind", n.Kind, "error", err)
+		}
+	}
+	return nil
+}
+
+// OnUserFollowed tells a user about a new follower.
+func (s *NotificationService) OnUserFollowed(ctx context.Context, event *pb.UserFollowed) error {
+	return s.Notify(ctx, &pb.Notification{
+		UserId:  event.FolloweeId,
+		Kind:    entity.NotifyFollow,
+		ActorId: event.FollowerId,
+		Title:   s.actorName(ctx, event.FollowerId) + " started following you",
+	})
+}
+
+// OnCommentCreated tells a post's author about a new comment, and the
+// parent comment's author about a reply. Nobody is told about their own
+// comments.
+func (s *NotificationService) OnCommentCreated(ctx context.Context, comment *pb.CommentGet) error {
+	actor := s.actorName(ctx, comment.UserId)
+	body := excerpt(comment.Content, commentExcerpt)
+	var errs []error
+
+	var parentAuthor int64
+	if comment.ParentId != 0 {
+		parent, err := s.stg.Comment().GetDetail(ctx, comment.ParentId)
+		if err != nil {
+			return notFound(err, "parent comment")
+		}
+		parentAuthor = parent.UserId
+		if parentAuthor != 0 && parentAuthor != comment.UserId {
+			errs = append(errs, s.Notify(ctx, &pb.Notification{
+				UserId:    parentAuthor,
+				Kind:      entity.NotifyReply,
+				ActorId:   comment.UserId,
+				PostId:    comment.PostId,
+				CommentId: comment.Id,
+				Title:     actor + " replied to your comment",
+				Body:      body,
+			}))
+		}
+	}
+
+	post, err := s.stg.Post().GetDetail(ctx, &pb.GetById{Id: comment.PostId})
+	if err != nil {
+		return notFound(err, "post")
+	}
+	// a reply to the post author's own comment was announced above
+	if post.UserId != 0 && post.UserId != comment.UserId && post.UserId != parentAuthor {
+		errs = append(errs, s.Notify(ctx, &pb.Notification{
+			UserId:    post.UserId,
+			Kind:      entity.NotifyComment,
+			ActorId:   comment.UserId,
+			PostId:    comment.PostId,
+			CommentId: comment.Id,
+			Title:     actor + " commented on " + post.Title,
+			Body:      body,
+		}))
+	}
+	return errors.Join(errs...)
+}
+
+// OnPasswordChanged warns a user that their password was changed.
+func (s *NotificationService) OnPasswordChanged(ctx context.Context, event *pb.PasswordChanged) error {
+	userID, err := s.stg.User().IDByEmail(ctx, event.Email)
+	if err != nil {
+		return notFound(err, "user")
+	}
+	return s.Notify(ctx, &pb.Notification{
+		UserId: userID,
+		Kind:   entity.NotifyPasswordChanged,
+		Title:  "Your password was changed",
+		Body: "The password of your account was changed at " + event.ChangedAt +
+			". If this was not you, reset your password right away.",
+	})
+}
+
+// preferences returns a lookup of the user's settings with defaults for
+// everything the user has not set.
+func (s *NotificationService) preferences(ctx context.Context, userID int64) (func(kind, channel string) bool, error) {
+	stored, err := s.stg.Notification().Preferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	set := make(map[[2]string]bool, len(stored))
+	for _, p := range stored {
+		set[[2]string{p.Kind, p.Channel}] = p.Enabled
+	}
+
+	return func(kind, channel string) bool {
+		if enabled, ok := set[[2]string{kind, channel}]; ok {
+			return enabled
+		}
+		return notify.Default(kind, channel)
+	}, nil
+}
+
+// actorName is how notifications refer to the user who caused them.
+func (s *NotificationService) actorName(ctx context.Context, userID int64) string {
+	user, err := s.stg.User().GetDetail(ctx, &pb.ById{Id: userID})
+	if err != nil {
+		logger.FromContext(ctx).Warn("failed to look up user for a notification", "user_id", userID, "error", err)
+		return "Someone"
+	}
+	if user.Username != "" {
+		return user.Username
+	}
+	if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+		return name
+	}
+	return "Someone"
+}
+
+// excerpt shortens s to at most n runes.
+func excerpt(s string, n int) string {
+	runes := []rune(strings.TrimSpace(s))
+	if len(runes) <= n {
+		return string(runes)
+	}
+	return string(runes[:n-1]) + "…"
+}
@@ -3,11 +3,24 @@ package service
 import (
 	"context"
 	"posts/internal/usecase/kafka"
+	"strings"
+	"time"
+	"unicode"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"posts/internal/entity"
 	pb "posts/internal/pkg/genproto"
 	"posts/internal/repository"
 )
 
+// maxTagsPerPost bounds how many tags a single post may carry.
+const maxTagsPerPost = 10
+
+// PublishBatchSize bounds how many posts one PublishDue call publishes.
+const PublishBatchSize = 100
+
 type PostService struct {
 	stg      repository.StorageI
 	producer kafka.KafkaProducer
@@ -15,20 +28,323 @@ type PostService struct {
 }
 
 func NewPostService(stg repository.StorageI, kafka kafka.KafkaProducer) *PostService {
-	return &PostService{stg: stg}
+	return &PostService{stg: stg, producer: kafka}
 }
+
 func (s *PostService) Create(ctx context.Context, request *pb.PostCreateRequest) (*pb.PostCreateResponse, error) {
-	return s.stg.Post().Create(ctx, request)
+	if len(request.Tags) > maxTagsPerPost {
+		return nil, status.Errorf(codes.InvalidArgument, "a post can have at most %d tags", maxTagsPerPost)
+	}
+	if request.Language != "" && !searchLanguages[request.Language] {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown language %q", request.Language)
+	}
+
+	// new posts are published right away unless told otherwise
+	if request.Status == "" && request.PublishAt == "" {
+		request.Status = entity.PostPublished
+	}
+	var err error
+	request.Status, request.PublishAt, err = lifecycle(request.Status, request.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+	if request.Status == entity.PostArchived {
+		return nil, status.Error(codes.InvalidArgument, "a new post cannot be archived")
+	}
+
+	var post *pb.PostCreateResponse
+	err = s.stg.WithTx(ctx, func(tx repository.StorageI) error {
+		var err error
+		post, err = tx.Post().Create(ctx, request)
+		if err != nil {
+			return err
+		}
+		post.Slug, err = assignSlug(ctx, tx, post.Id, request.Title)
+		if err != nil {
+			return err
+		}
+		post.Tags, err = tx.Tag().SetForPost(ctx, post.Id, request.Tags)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return post, nil
 }
+
+// GetDetail returns a post; posts that are not published are only shown to
+// their author.
 func (s *PostService) GetDetail(ctx context.Context, request *pb.GetById) (*pb.PostGetResponse, error) {
-	return s.stg.Post().GetDetail(ctx, request)
+	post, err := s.stg.Post().GetDetail(ctx, request)
+	if err != nil {
+		return nil, notFound(err, "post")
+	}
+	if !visible(post, request.ViewerId) {
+		return nil, status.Error(codes.NotFound, "post not found")
+	}
+	return post, nil
+}
+
+// visible reports whether viewer may see post: everyone sees published
+// posts, only the author sees the others.
+func visible(post *pb.PostGetResponse, viewerID int64) bool {
+	return post.Status == entity.PostPublished || (post.UserId != 0 && post.UserId == viewerID)
+}
+
+// checkVisible returns NotFound unless post postID exists and viewer may
+// see it; things that belong to a post follow its visibility.
+func checkVisible(ctx context.Context, stg repository.StorageI, postID, viewerID int64) error {
+	post, err := stg.Post().GetDetail(ctx, &pb.GetById{Id: postID})
+	if err != nil {
+		return notFound(err, "post")
+	}
+	if !visible(post, viewerID) {
+		return status.Error(codes.NotFound, "post not found")
+	}
+	return nil
 }
+
 func (s *PostService) GetList(ctx context.Context, request *pb.FilterPost) (*pb.PostGetAll, error) {
+	if !postSorts[request.SortBy] {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown sort %q, expected latest or popular", request.SortBy)
+	}
 	return s.stg.Post().GetList(ctx, request)
 }
+
+// postSorts are the orders GetList accepts; empty means latest.
+var postSorts = map[string]bool{
+	"":        true,
+	"latest":  true,
+	"popular": true,
+}
+
+// Update changes the post and, when tags are given or cleared, replaces its
+// tags and prunes tags nobody uses any more. When the title or content
+// changes, the previous version is kept as a revision first; a new title
+// also gives the post a new slug.
 func (s *PostService) Update(ctx context.Context, request *pb.PostUpdateRequest) (*pb.PostVoid, error) {
-	return s.stg.Post().Update(ctx, request)
+	if len(request.Tags) > maxTagsPerPost {
+		return nil, status.Errorf(codes.InvalidArgument, "a post can have at most %d tags", maxTagsPerPost)
+	}
+
+	var err error
+	request.Status, request.PublishAt, err = lifecycle(request.Status, request.PublishAt)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.stg.WithTx(ctx, func(tx repository.StorageI) error {
+		if changesText(request) {
+			if _, err := tx.Revision().Snapshot(ctx, request.Id); err != nil {
+				return notFound(err, "post")
+			}
+		}
+		if _, err := tx.Post().Update(ctx, request); err != nil {
+			return err
+		}
+		if request.Title != "" && request.Title != "string" {
+			if _, err := assignSlug(ctx, tx, request.Id, request.Title); err != nil {
+				return err
+			}
+		}
+		if len(request.Tags) == 0 && !request.ClearTags {
+			return nil
+		}
+		previous, err := tx.Tag().IDsForPost(ctx, request.Id)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Tag().SetForPost(ctx, request.Id, request.Tags); err != nil {
+			return err
+		}
+		_, err = tx.Tag().DeleteUnused(ctx, previous)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostVoid{}, nil
 }
+
+// PublishDue publishes scheduled posts whose time has come and emits a
+// post-published event for each. Events are sent after the posts are
+// committed; a failed send is logged and not retried.
+func (s *PostService) PublishDue(ctx context.Context) (int, error) {
+	published, err := s.stg.Post().PublishDue(ctx, PublishBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, post := range published {
+		emit(ctx, s.producer, TopicPostPublished, post)
+	}
+	return len(published), nil
+}
+
 func (s *PostService) Delete(ctx context.Context, request *pb.GetById) (*pb.PostVoid, error) {
-	return s.stg.Post().Delete(ctx, request)
+	err := s.stg.WithTx(ctx, func(tx repository.StorageI) error {
+		if _, err := tx.Post().Delete(ctx, request); err != nil {
+			return err
+		}
+		tagIDs, err := tx.Tag().IDsForPost(ctx, request.Id)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Tag().DeleteUnused(ctx, tagIDs)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostVoid{}, nil
+}
+func (s *PostService) ListTags(ctx context.Context, request *pb.TagListRequest) (*pb.TagList, error) {
+	return s.stg.Tag().List(ctx, request)
+}
+func (s *PostService) Search(ctx context.Context, request *pb.PostSearchRequest) (*pb.PostSearchResponse, error) {
+	if err := validateSearch(request); err != nil {
+		return nil, err
+	}
+	return s.stg.Post().Search(ctx, request)
+}
+
+// searchModes are the query syntaxes Search accepts; empty means
+// websearch.
+var searchModes = map[string]bool{
+	"":          true,
+	"websearch": true,
+	"plain":     true,
+	"phrase":    true,
+	"prefix":    true,
+}
+
+// searchLanguages are the text search configurations a post's language
+// may name; they are built into PostgreSQL. The language is used as a
+// regconfig, so nothing else may reach the database.
+var searchLanguages = map[string]bool{
+	"simple":     true,
+	"danish":     true,
+	"dutch":      true,
+	"english":    true,
+	"finnish":    true,
+	"french":     true,
+	"german":     true,
+	"hungarian":  true,
+	"italian":    true,
+	"norwegian":  true,
+	"portuguese": true,
+	"romanian":   true,
+	"russian":    true,
+	"spanish":    true,
+	"swedish":    true,
+	"turkish":    true,
+}
+
+func validateSearch(request *pb.PostSearchRequest) error {
+	query := strings.TrimSpace(request.Query)
+	if query == "" {
+		return status.Error(codes.InvalidArgument, "search query is empty")
+	}
+	mode := strings.ToLower(request.Mode)
+	if !searchModes[mode] {
+		return status.Errorf(codes.InvalidArgument, "unknown search mode %q, expected websearch, plain, phrase or prefix", request.Mode)
+	}
+	if request.Language != "" && !searchLanguages[request.Language] {
+		return status.Errorf(codes.InvalidArgument, "unknown language %q", request.Language)
+	}
+	// prefix mode only keeps letters and digits
+	if mode == "prefix" && strings.IndexFunc(query, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+		return status.Error(codes.InvalidArgument, "search query has no searchable terms")
+	}
+	return nil
+}
+
+// reactionKinds are the reactions a post accepts; keep in sync with the
+// CHECK constraint on reactions.kind.
+var reactionKinds = map[string]bool{
+	"like":  true,
+	"love":  true,
+	"laugh": true,
+	"wow":   true,
+	"sad":   true,
+	"angry": true,
+}
+
+// React adds the user's reaction of the given kind to a post. Reacting twice
+// with the same kind is a no-op.
+func (s *PostService) React(ctx context.Context, request *pb.PostReactionRequest) (*pb.PostReactions, error) {
+	return s.react(ctx, request, repository.PostI.AddReaction)
+}
+
+// Unreact removes the user's reaction of the given kind from a post.
+// Removing a reaction that does not exist is a no-op.
+func (s *PostService) Unreact(ctx context.Context, request *pb.PostReactionRequest) (*pb.PostReactions, error) {
+	return s.react(ctx, request, repository.PostI.RemoveReaction)
+}
+
+func (s *PostService) react(ctx context.Context, request *pb.PostReactionRequest,
+	apply func(repository.PostI, context.Context, *pb.PostReactionRequest) (bool, error)) (*pb.PostReactions, error) {
+	request.Kind = strings.ToLower(strings.TrimSpace(request.Kind))
+	if !reactionKinds[request.Kind] {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown reaction %q", request.Kind)
+	}
+	if request.UserId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "reacting user is required")
+	}
+
+	var reactions *pb.PostReactions
+	err := s.stg.WithTx(ctx, func(tx repository.StorageI) error {
+		current, err := tx.Post().Reactions(ctx, request.PostId)
+		if err != nil {
+			return notFound(err, "post")
+		}
+
+		changed, err := apply(tx.Post(), ctx, request)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			reactions = current
+			return nil
+		}
+
+		reactions, err = tx.Post().RefreshReactions(ctx, request.PostId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reactions, nil
+}
+
+// lifecycle validates a requested status and publish time. A publish time
+// alone means the post is scheduled; only scheduled posts may have one, and
+// it must lie in the future. The publish time is returned in UTC.
+func lifecycle(postStatus, publishAt string) (string, string, error) {
+	postStatus = strings.ToLower(strings.TrimSpace(postStatus))
+	if postStatus == "" && publishAt != "" {
+		postStatus = entity.PostScheduled
+	}
+
+	switch postStatus {
+	case "", entity.PostDraft, entity.PostPublished, entity.PostArchived:
+		if publishAt != "" {
+			return "", "", status.Error(codes.InvalidArgument, "publish_at is only allowed for scheduled posts")
+		}
+		return postStatus, "", nil
+	case entity.PostScheduled:
+		if publishAt == "" {
+			return "", "", status.Error(codes.InvalidArgument, "scheduled posts need publish_at")
+		}
+		at, err := time.Parse(time.RFC3339, publishAt)
+		if err != nil {
+			return "", "", status.Errorf(codes.InvalidArgument, "publish_at must be an RFC3339 time: %v", err)
+		}
+		if !at.After(time.Now()) {
+			return "", "", status.Error(codes.InvalidArgument, "publish_at must be in the future")
+		}
+		return postStatus, at.UTC().Format(time.RFC3339), nil
+	default:
+		return "", "", status.Errorf(codes.InvalidArgument, "unknown status %q, expected draft, scheduled, published or archived", postStatus)
+	}
 }
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/pkg/cursor"
+	pb "posts/internal/pkg/genproto"
+)
+
+const (
+	defaultFeedLimit = 20
+	maxFeedLimit     = 100
+)
+
+// Feed returns the newest published posts of the authors the user follows.
+// Pages are chained with NextCursor, so posts published while the reader
+// pages through never shift or repeat entries.
+func (s *PostService) Feed(ctx context.Context, request *pb.FeedRequest) (*pb.FeedPage, error) {
+	if request.UserId == 0 {
+		return nil, status.Error(codes.Unauthenticated, "reader is required")
+	}
+
+	limit := int(request.Limit)
+	if limit <= 0 {
+		limit = defaultFeedLimit
+	}
+	if limit > maxFeedLimit {
+		limit = maxFeedLimit
+	}
+
+	after, err := cursor.Decode(request.Cursor)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return s.stg.Post().Feed(ctx, request.UserId, limit, after)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/pkg/diff"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/repository"
+)
+
+// ListRevisions lists the revisions of a post. Revisions follow the
+// visibility of their post, so drafts and scheduled posts only show their
+// history to the author.
+func (s *PostService) ListRevisions(ctx context.Context, request *pb.RevisionListRequest) (*pb.RevisionList, error) {
+	if _, err := s.GetDetail(ctx, &pb.GetById{Id: request.PostId, ViewerId: request.ViewerId}); err != nil {
+		return nil, err
+	}
+	return s.stg.Revision().List(ctx, request)
+}
+
+func (s *PostService) GetRevision(ctx context.Context, request *pb.RevisionRequest) (*pb.PostRevision, error) {
+	if _, err := s.GetDetail(ctx, &pb.GetById{Id: request.PostId, ViewerId: request.ViewerId}); err != nil {
+		return nil, err
+	}
+
+	revision, err := s.stg.Revision().Get(ctx, request)
+	if err != nil {
+		return nil, notFound(err, "revision")
+	}
+	return revision, nil
+}
+
+// DiffRevisions compares two revisions of a post line by line. A zero To
+// compares against the post as it is now.
+func (s *PostService) DiffRevisions(ctx context.Context, request *pb.RevisionDiffRequest) (*pb.RevisionDiff, error) {
+	post, err := s.GetDetail(ctx, &pb.GetById{Id: request.PostId, ViewerId: request.ViewerId})
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := s.stg.Revision().Get(ctx, &pb.RevisionRequest{PostId: request.PostId, Revision: request.From})
+	if err != nil {
+		return nil, notFound(err, "revision")
+	}
+
+	var toTitle, toContent string
+	if request.To == 0 {
+		toTitle, toContent = post.Title, post.Content
+	} else {
+		to, err := s.stg.Revision().Get(ctx, &pb.RevisionRequest{PostId: request.PostId, Revision: request.To})
+		if err != nil {
+			return nil, notFound(err, "revision")
+		}
+		toTitle, toContent = to.Title, to.Content
+	}
+
+	titleDiff, err := diff.Lines(from.Title, toTitle)
+	if err != nil {
+		return nil, tooLarge(err)
+	}
+	contentDiff, err := diff.Lines(from.Content, toContent)
+	if err != nil {
+		return nil, tooLarge(err)
+	}
+
+	return &pb.RevisionDiff{
+		PostId:      request.PostId,
+		From:        request.From,
+		To:          request.To,
+		TitleDiff:   titleDiff,
+		ContentDiff: contentDiff,
+	}, nil
+}
+
+func tooLarge(err error) error {
+	if errors.Is(err, diff.ErrTooLarge) {
+		return status.Errorf(codes.FailedPrecondition, "revisions differ in more than %d lines", diff.MaxLines)
+	}
+	return err
+}
+
+// RestoreRevision brings back the title and content of an older revision.
+// The restore is a regular update, so the version it replaces becomes a new
+// revision and the history stays linear. Only the author can restore.
+func (s *PostService) RestoreRevision(ctx context.Context, request *pb.RevisionRequest) (*pb.PostVoid, error) {
+	err := s.stg.WithTx(ctx, func(tx repository.StorageI) error {
+		post, err := tx.Post().GetDetail(ctx, &pb.GetById{Id: request.PostId})
+		if err != nil {
+			return notFound(err, "post")
+		}
+		if !visible(post, request.ViewerId) {
+			return status.Error(codes.NotFound, "post not found")
+		}
+		if post.UserId == 0 || post.UserId != request.ViewerId {
+			return status.Error(codes.PermissionDenied, "only the author can restore a revision")
+		}
+
+		revision, err := tx.Revision().Get(ctx, request)
+		if err != nil {
+			return notFound(err, "revision")
+		}
+		if _, err := tx.Revision().Snapshot(ctx, request.PostId); err != nil {
+			return notFound(err, "post")
+		}
+
+		_, err = tx.Post().Update(ctx, &pb.PostUpdateRequest{
+			Id:      request.PostId,
+			Title:   revision.Title,
+			Content: revision.Content,
+		})
+		if err != nil {
+			return err
+		}
+		_, err = assignSlug(ctx, tx, request.PostId, revision.Title)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &pb.PostVoid{}, nil
+}
+
+// changesText reports whether an update touches the title or content, the
+// fields revisions keep.
+func changesText(request *pb.PostUpdateRequest) bool {
+	return (request.Title != "" && request.Title != "string") ||
+		(request.Content != "" && request.Content != "string")
+}
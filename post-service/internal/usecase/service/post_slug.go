@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/slug"
+	"posts/internal/repository"
+)
+
+// fallbackSlug is used for titles without a single transliterable letter
+// or digit.
+const fallbackSlug = "post"
+
+// GetBySlug returns the post that has or had the given slug. Callers can
+// compare the returned post's slug with the requested one to redirect old
+// links. Visibility rules are those of GetDetail.
+func (s *PostService) GetBySlug(ctx context.Context, request *pb.PostSlugRequest) (*pb.PostGetResponse, error) {
+	postSlug := slug.ASCII(request.Slug)
+	if postSlug == "" {
+		return nil, status.Error(codes.InvalidArgument, "slug is required")
+	}
+
+	post, err := s.stg.Post().GetBySlug(ctx, postSlug)
+	if err != nil {
+		return nil, notFound(err, "post")
+	}
+	if !visible(post, request.ViewerId) {
+		return nil, status.Error(codes.NotFound, "post not found")
+	}
+	return post, nil
+}
+
+// assignSlug derives a slug from title and makes it the post's current
+// one. Slugs other posts have or had get a numeric suffix.
+func assignSlug(ctx context.Context, tx repository.StorageI, postID int64, title string) (string, error) {
+	base := slug.ASCII(title)
+	if base == "" {
+		base = fallbackSlug
+	}
+
+	taken, err := tx.Post().TakenSlugs(ctx, base, postID)
+	if err != nil {
+		return "", err
+	}
+	postSlug := slug.WithSuffix(base, taken)
+
+	if err := tx.Post().SetSlug(ctx, postID, postSlug); err != nil {
+		return "", err
+	}
+	return postSlug, nil
+}
@@ -2,18 +2,61 @@ package service
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"time"
 
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	pb "posts/internal/pkg/genproto"
 	"posts/internal/repository"
+	"posts/internal/usecase/kafka"
 )
 
+// DeletionPolicy decides what happens to a user's related rows when the user
+// is deleted.
+type DeletionPolicy string
+
+const (
+	// DeletionCascade soft-deletes the related rows together with the user.
+	DeletionCascade DeletionPolicy = "cascade"
+	// DeletionAnonymize keeps the related rows but detaches them from the user.
+	DeletionAnonymize DeletionPolicy = "anonymize"
+	// DeletionBlock refuses to delete a user that still has related rows.
+	DeletionBlock DeletionPolicy = "block"
+)
+
+func ParseDeletionPolicy(policy string) (DeletionPolicy, error) {
+	switch p := DeletionPolicy(strings.ToLower(strings.TrimSpace(policy))); p {
+	case DeletionCascade, DeletionAnonymize, DeletionBlock:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown deletion policy %q, expected cascade, anonymize or block", policy)
+	}
+}
+
+// DeletionPolicies configures user deletion per related relation.
+type DeletionPolicies struct {
+	Posts DeletionPolicy
+	Logs  DeletionPolicy
+}
+
+// userOwned is implemented by repositories whose rows belong to a user.
+type userOwned interface {
+	CountByUser(ctx context.Context, userID int64) (int64, error)
+	DeleteByUser(ctx context.Context, userID int64) (int64, error)
+	AnonymizeByUser(ctx context.Context, userID int64) (int64, error)
+}
+
 type UserService struct {
-	stg repository.StorageI
+	stg      repository.StorageI
+	deletion DeletionPolicies
+	producer kafka.KafkaProducer
 	pb.UnimplementedUserServiceServer
 }
 
-func NewUserService(stg repository.StorageI) *UserService {
-	return &UserService{stg: stg}
+func NewUserService(stg repository.StorageI, deletion DeletionPolicies, producer kafka.KafkaProducer) *UserService {
+	return &UserService{stg: stg, deletion: deletion, producer: producer}
 }
 func (s *UserService) Create(ctx context.Context, request *pb.UserCreateRequest) (*pb.UserCreateResponse, error) {
 	return s.stg.User().Create(ctx, request)
@@ -27,12 +70,116 @@ func (s *UserService) GetList(ctx context.Context, request *pb.FilterUser) (*pb.
 func (s *UserService) Update(ctx context.Context, request *pb.UserUpdateRequest) (*pb.Void, error) {
 	return s.stg.User().Update(ctx, request)
 }
-func (s *UserService) Delete(ctx context.Context, request *pb.ById) (*pb.Void, error) {
-	return s.stg.User().Delete(ctx, request)
+
+// Delete soft-deletes the user and applies the configured deletion policy to
+// each related relation in one transaction, reporting the affected rows.
+func (s *UserService) Delete(ctx context.Context, request *pb.ById) (*pb.UserDeleteResponse, error) {
+	var response *pb.UserDeleteResponse
+
+	err := s.stg.WithTx(ctx, func(tx repository.StorageI) error {
+		response = &pb.UserDeleteResponse{Id: request.Id}
+
+		relations := []struct {
+			name   string
+			policy DeletionPolicy
+			repo   userOwned
+		}{
+			{"posts", s.deletion.Posts, tx.Post()},
+			{"logs", s.deletion.Logs, tx.Log()},
+		}
+
+		for _, relation := range relations {
+			if relation.policy != DeletionBlock {
+				continue
+			}
+			count, err := relation.repo.CountByUser(ctx, request.Id)
+			if err != nil {
+				return err
+			}
+			if count > 0 {
+				return status.Errorf(codes.FailedPrecondition, "user still has %d %s", count, relation.name)
+			}
+		}
+
+		// tags only the user's posts used are pruned after a cascade
+		var tagIDs []int64
+		if s.deletion.Posts == DeletionCascade {
+			var err error
+			if tagIDs, err = tx.Tag().IDsForUser(ctx, request.Id); err != nil {
+				return err
+			}
+		}
+
+		if _, err := tx.User().Delete(ctx, request); err != nil {
+			return err
+		}
+
+		for _, relation := range relations {
+			var affected int64
+			var err error
+			switch relation.policy {
+			case DeletionCascade:
+				affected, err = relation.repo.DeleteByUser(ctx, request.Id)
+			case DeletionAnonymize:
+				affected, err = relation.repo.AnonymizeByUser(ctx, request.Id)
+			}
+			if err != nil {
+				return err
+			}
+
+			response.Relations = append(response.Relations, &pb.RelationDeletion{
+				Relation: relation.name,
+				Policy:   string(relation.policy),
+				Affected: affected,
+			})
+		}
+
+		if _, err := tx.Tag().DeleteUnused(ctx, tagIDs); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
 }
 func (s *UserService) Login(ctx context.Context, request *pb.LoginRequest) (*pb.LoginResponse, error) {
 	return s.stg.User().Login(ctx, request)
 }
 func (s *UserService) ChangeUserPassword(ctx context.Context, request *pb.UserRecoverPasswordRequest) (*pb.Void, error) {
-	return s.stg.User().ChangeUserPassword(ctx, request)
+	res, err := s.stg.User().ChangeUserPassword(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	emit(ctx, s.producer, TopicPasswordChanged, &pb.PasswordChanged{
+		Email:     request.Email,
+		ChangedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+	return res, nil
+}
+
+// Erase irreversibly removes a user's personal data: the profile is
+// blanked, and the user's follows, notifications, notification preferences
+// and notification webhook are deleted. Posts, comments, reactions and logs
+// stay, as part of threads and counts other users see, attributed to the
+// blanked profile; Delete's policies decide what happens to those.
+func (s *UserService) Erase(ctx context.Context, request *pb.ById) (*pb.UserEraseResponse, error) {
+	var response *pb.UserEraseResponse
+
+	err := s.stg.WithTx(ctx, func(tx repository.StorageI) error {
+		var err error
+		if response, err = tx.User().Erase(ctx, request); err != nil {
+			return notFound(err, "user")
+		}
+		if _, err := tx.Follow().DeleteByUser(ctx, request.Id); err != nil {
+			return err
+		}
+		_, err = tx.Notification().DeleteByUser(ctx, request.Id)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response, nil
 }
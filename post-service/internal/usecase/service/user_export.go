@@ -0,0 +1,167 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+)
+
+// streamChunkSize is the maximum payload of a single streamed chunk.
+const streamChunkSize = 64 << 10
+
+type userExport struct {
+	ExportedAt    string                  `json:"exported_at"`
+	Profile       *pb.UserGetResponse     `json:"profile"`
+	Posts         []*pb.PostGetResponse   `json:"posts"`
+	Comments      []*pb.CommentGet        `json:"comments"`
+	Reactions     []entity.Reaction       `json:"reactions"`
+	Follows       []entity.Follow         `json:"follows"`
+	Notifications userExportNotifications `json:"notifications"`
+	Logs          []*pb.LogGetResponse    `json:"logs"`
+}
+
+type userExportNotifications struct {
+	Received    []*pb.Notification           `json:"received"`
+	Preferences []*pb.NotificationPreference `json:"preferences"`
+	WebhookURL  string                       `json:"webhook_url,omitempty"`
+}
+
+// ExportData streams everything stored about a user as a single JSON
+// document or as a ZIP archive with one JSON file per section. Follows
+// carry only the other user's id: their profile is not the user's data.
+// Deleted users can be exported until they are erased.
+func (s *UserService) ExportData(request *pb.UserExportRequest, stream pb.UserService_ExportDataServer) error {
+	ctx := stream.Context()
+
+	profile, err := s.stg.User().Profile(ctx, request.Id)
+	if err != nil {
+		return notFound(err, "user")
+	}
+	export := userExport{
+		ExportedAt: time.Now().UTC().Format(time.RFC3339),
+		Profile:    profile,
+	}
+	if export.Posts, err = s.stg.Post().ListByUser(ctx, request.Id); err != nil {
+		return err
+	}
+	if export.Comments, err = s.stg.Comment().ListByUser(ctx, request.Id); err != nil {
+		return err
+	}
+	if export.Reactions, err = s.stg.Post().ReactionsByUser(ctx, request.Id); err != nil {
+		return err
+	}
+	if export.Follows, err = s.stg.Follow().ListByUser(ctx, request.Id); err != nil {
+		return err
+	}
+	if export.Notifications.Received, err = s.stg.Notification().ListByUser(ctx, request.Id); err != nil {
+		return err
+	}
+	if export.Notifications.Preferences, err = s.stg.Notification().Preferences(ctx, request.Id); err != nil {
+		return err
+	}
+	webhook, err := s.stg.Notification().Webhook(ctx, request.Id)
+	if err != nil {
+		return err
+	}
+	export.Notifications.WebhookURL = webhook.URL
+	if export.Logs, err = s.stg.Log().ListByUser(ctx, request.Id); err != nil {
+		return err
+	}
+	name := fmt.Sprintf("user-%d-export", request.Id)
+
+	switch request.Format {
+	case "", "json":
+		w := &exportWriter{stream: stream, contentType: "application/json", fileName: name + ".json"}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(export); err != nil {
+			return err
+		}
+		return w.Flush()
+	case "zip":
+		w := &exportWriter{stream: stream, contentType: "application/zip", fileName: name + ".zip"}
+		if err := writeExportZip(w, export); err != nil {
+			return err
+		}
+		return w.Flush()
+	default:
+		return status.Errorf(codes.InvalidArgument, "unknown export format %q, expected json or zip", request.Format)
+	}
+}
+
+func writeExportZip(w io.Writer, export userExport) error {
+	archive := zip.NewWriter(w)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Profile},
+		{"posts.json", export.Posts},
+		{"comments.json", export.Comments},
+		{"reactions.json", export.Reactions},
+		{"follows.json", export.Follows},
+		{"notifications.json", export.Notifications},
+		{"logs.json", export.Logs},
+	}
+	for _, file := range files {
+		f, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(file.data); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// exportWriter buffers written bytes and sends them as UserExportChunk
+// messages. Only the first chunk carries the content type and file name.
+type exportWriter struct {
+	stream      pb.UserService_ExportDataServer
+	contentType string
+	fileName    string
+	buf         []byte
+	sent        bool
+}
+
+func (w *exportWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for len(w.buf) >= streamChunkSize {
+		if err := w.send(w.buf[:streamChunkSize]); err != nil {
+			return 0, err
+		}
+		w.buf = w.buf[streamChunkSize:]
+	}
+	return len(p), nil
+}
+
+func (w *exportWriter) Flush() error {
+	if len(w.buf) == 0 && w.sent {
+		return nil
+	}
+	err := w.send(w.buf)
+	w.buf = nil
+	return err
+}
+
+func (w *exportWriter) send(data []byte) error {
+	chunk := &pb.UserExportChunk{Data: append([]byte(nil), data...)}
+	if !w.sent {
+		chunk.ContentType = w.contentType
+		chunk.FileName = w.fileName
+		w.sent = true
+	}
+	return w.stream.Send(chunk)
+}
@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	pb "posts/internal/pkg/genproto"
+)
+
+// Follow makes the follower follow another user. Following someone twice
+// is a no-op.
+func (s *UserService) Follow(ctx context.Context, request *pb.FollowRequest) (*pb.Void, error) {
+	if err := s.checkFollow(ctx, request); err != nil {
+		return nil, err
+	}
+	followed, err := s.stg.Follow().Follow(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if followed {
+		emit(ctx, s.producer, TopicUserFollowed, &pb.UserFollowed{
+			FollowerId: request.FollowerId,
+			FolloweeId: request.FolloweeId,
+		})
+	}
+	return &pb.Void{}, nil
+}
+
+// Unfollow stops the follower from following another user.
+func (s *UserService) Unfollow(ctx context.Context, request *pb.FollowRequest) (*pb.Void, error) {
+	if err := s.checkFollow(ctx, request); err != nil {
+		return nil, err
+	}
+	return s.stg.Follow().Unfollow(ctx, request)
+}
+
+func (s *UserService) ListFollowers(ctx context.Context, request *pb.FollowListRequest) (*pb.UserGetAll, error) {
+	return s.stg.Follow().Followers(ctx, request)
+}
+
+func (s *UserService) ListFollowing(ctx context.Context, request *pb.FollowListRequest) (*pb.UserGetAll, error) {
+	return s.stg.Follow().Following(ctx, request)
+}
+
+func (s *UserService) checkFollow(ctx context.Context, request *pb.FollowRequest) error {
+	if request.FollowerId == 0 {
+		return status.Error(codes.Unauthenticated, "follower is required")
+	}
+	if request.FollowerId == request.FolloweeId {
+		return status.Error(codes.InvalidArgument, "users cannot follow themselves")
+	}
+	if _, err := s.stg.User().GetDetail(ctx, &pb.ById{Id: request.FolloweeId}); err != nil {
+		return notFound(err, "user")
+	}
+	return nil
+}
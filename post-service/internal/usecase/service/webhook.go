@@ -0,0 +1,311 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"posts/internal/entity"
+	pb "posts/internal/pkg/genproto"
+	"posts/internal/pkg/logger"
+	"posts/internal/pkg/webhook"
+	"posts/internal/repository"
+)
+
+const (
+	// WebhookBatchSize is the most deliveries DeliverDue sends per call.
+	WebhookBatchSize = 20
+
+	webhookTimeout = 10 * time.Second
+	// webhookLease keeps claimed deliveries from other workers while a
+	// batch is sent one by one.
+	webhookLease = WebhookBatchSize*webhookTimeout + time.Minute
+	// minSecretLength applies to secrets chosen by the caller.
+	minSecretLength = 16
+)
+
+// webhookEvents are the event types a webhook can subscribe to.
+var webhookEvents = []string{entity.EventPostPublished, entity.EventUserFollowed, entity.EventCommentCreated}
+
+var deliveryStatuses = []string{entity.DeliveryPending, entity.DeliveryDelivered, entity.DeliveryFailed}
+
+type WebhookOptions struct {
+	// MaxAttempts is how often a delivery is tried before it is marked
+	// failed.
+	MaxAttempts int
+	// DisableAfter is how many failed attempts in a row disable a
+	// webhook.
+	DisableAfter int
+}
+
+type WebhookService struct {
+	stg    repository.StorageI
+	client *http.Client
+	opts   WebhookOptions
+	pb.UnimplementedWebhookServiceServer
+}
+
+func NewWebhookService(stg repository.StorageI, opts WebhookOptions) *WebhookService {
+	return &WebhookService{stg: stg, client: webhook.NewClient(webhookTimeout), opts: opts}
+}
+
+// Create registers a webhook. Its secret, generated when none is given, is
+// only returned here.
+func (s *WebhookService) Create(ctx context.Context, request *pb.WebhookCreateRequest) (*pb.Webhook, error) {
+	request.Url = strings.TrimSpace(request.Url)
+	if err := checkURL(ctx, request.Url); err != nil {
+		return nil, err
+	}
+	events, err := subscribedEvents(request.Events)
+	if err != nil {
+		return nil, err
+	}
+	if len(events) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "at least one event is required")
+	}
+	request.Events = events
+
+	if request.Secret == "" {
+		if request.Secret, err = newSecret(); err != nil {
+			return nil, err
+		}
+	} else if len(request.Secret) < minSecretLength {
+		return nil, status.Errorf(codes.InvalidArgument, "secret must be at least %d characters", minSecretLength)
+	}
+
+	return s.stg.Webhook().Create(ctx, request)
+}
+
+func (s *WebhookService) GetList(ctx context.Context, request *pb.WebhookListRequest) (*pb.WebhookList, error) {
+	return s.stg.Webhook().GetList(ctx, request)
+}
+
+// Update changes a webhook's URL, events or secret, and enables or
+// disables it. Enabling resumes its pending deliveries.
+func (s *WebhookService) Update(ctx context.Context, request *pb.WebhookUpdateRequest) (*pb.Webhook, error) {
+	request.Url = strings.TrimSpace(request.Url)
+	if request.Url != "" {
+		if err := checkURL(ctx, request.Url); err != nil {
+			return nil, err
+		}
+	}
+	events, err := subscribedEvents(request.Events)
+	if err != nil {
+		return nil, err
+	}
+	request.Events = events
+	if request.Secret != "" && len(request.Secret) < minSecretLength {
+		return nil, status.Errorf(codes.InvalidArgument, "secret must be at least %d characters", minSecretLength)
+	}
+
+	response, err := s.stg.Webhook().Update(ctx, request)
+	if err != nil {
+		return nil, notFound(err, "webhook")
+	}
+	return response, nil
+}
+
+// Delete removes a webhook together with its delivery log.
+func (s *WebhookService) Delete(ctx context.Context, request *pb.WebhookRequest) (*pb.WebhookVoid, error) {
+	if _, err := s.stg.Webhook().GetDetail(ctx, request.Id); err != nil {
+		return nil, notFound(err, "webhook")
+	}
+	if err := s.stg.Webhook().Delete(ctx, request.Id); err != nil {
+		return nil, err
+	}
+	return &pb.WebhookVoid{}, nil
+}
+
+func (s *WebhookService) GetDeliveries(ctx context.Context, request *pb.WebhookDeliveryListRequest) (*pb.WebhookDeliveryList, error) {
+	if request.Status != "" && !slices.Contains(deliveryStatuses, request.Status) {
+		return nil, status.Errorf(codes.InvalidArgument, "unknown delivery status %q", request.Status)
+	}
+	if _, err := s.stg.Webhook().GetDetail(ctx, request.WebhookId); err != nil {
+		return nil, notFound(err, "webhook")
+	}
+	return s.stg.Webhook().Deliveries(ctx, request)
+}
+
+// Redeliver queues the payload of an earlier delivery again, whatever
+// became of it.
+func (s *WebhookService) Redeliver(ctx context.Context, request *pb.WebhookDeliveryRequest) (*pb.WebhookDelivery, error) {
+	response, err := s.stg.Webhook().Redeliver(ctx, request)
+	if err != nil {
+		return nil, notFound(err, "delivery")
+	}
+	return response, nil
+}
+
+// envelope is the JSON body posted to webhooks.
+type envelope struct {
+	Event     string          `json:"event"`
+	CreatedAt string          `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Dispatch queues a delivery of an event to every active webhook
+// subscribed to it; data is the event as JSON.
+func (s *WebhookService) Dispatch(ctx context.Context, event string, data []byte) error {
+	if !json.Valid(data) {
+		return fmt.Errorf("%s event is not valid JSON", event)
+	}
+	payload, err := json.Marshal(envelope{
+		Event:     event,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Data:      data,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.stg.Webhook().Enqueue(ctx, event, string(payload))
+	return err
+}
+
+// DeliverDue sends due deliveries and returns how many were attempted.
+// Failed attempts are retried with backoff until MaxAttempts; a webhook
+// is disabled after DisableAfter failed attempts in a row.
+func (s *WebhookService) DeliverDue(ctx context.Context) (int, error) {
+	deliveries, err := s.stg.Webhook().Claim(ctx, WebhookBatchSize, webhookLease)
+	if err != nil {
+		return 0, err
+	}
+
+	hooks := make(map[int64]*entity.Webhook)
+	for i := range deliveries {
+		delivery := &deliveries[i]
+		hook, ok := hooks[delivery.WebhookID]
+		if !ok {
+			if hook, err = s.stg.Webhook().GetDetail(ctx, delivery.WebhookID); err != nil {
+				logger.FromContext(ctx).Error("failed to load webhook", "webhook_id", delivery.WebhookID, "error", err)
+				continue
+			}
+			hooks[delivery.WebhookID] = hook
+		}
+		// disabled earlier in this batch; the delivery waits for it to
+		// be enabled again
+		if !hook.Active {
+			continue
+		}
+		if err := s.attempt(ctx, hook, delivery); err != nil {
+			logger.FromContext(ctx).Error("failed to record webhook delivery", "delivery_id", delivery.ID, "error", err)
+		}
+	}
+	return len(deliveries), nil
+}
+
+// attempt sends a delivery once and records the outcome.
+func (s *WebhookService) attempt(ctx context.Context, hook *entity.Webhook, delivery *entity.WebhookDelivery) error {
+	code, sendErr := s.send(ctx, hook, delivery)
+
+	now := time.Now()
+	delivery.Attempts++
+	delivery.ResponseCode = nil
+	if code != 0 {
+		delivery.ResponseCode = &code
+	}
+
+	if sendErr == nil {
+		delivery.Status = entity.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.Error = nil
+		if err := s.stg.Webhook().SaveAttempt(ctx, delivery); err != nil {
+			return err
+		}
+		return s.stg.Webhook().Succeeded(ctx, hook.ID)
+	}
+
+	message := sendErr.Error()
+	delivery.Error = &message
+	if int(delivery.Attempts) >= s.opts.MaxAttempts {
+		delivery.Status = entity.DeliveryFailed
+	} else {
+		next := now.Add(webhook.Backoff(int(delivery.Attempts)))
+		delivery.NextAttemptAt = &next
+	}
+	if err := s.stg.Webhook().SaveAttempt(ctx, delivery); err != nil {
+		return err
+	}
+
+	disabled, err := s.stg.Webhook().Failed(ctx, hook.ID, s.opts.DisableAfter)
+	if err != nil {
+		return err
+	}
+	if disabled {
+		hook.Active = false
+		logger.FromContext(ctx).Warn("disabled webhook after failed attempts in a row", "webhook_id", hook.ID, "failures", s.opts.DisableAfter)
+	}
+	return nil
+}
+
+// send posts a delivery's payload, signed with the webhook's secret, and
+// returns the response status code, or 0 when there was no response.
+func (s *WebhookService) send(ctx context.Context, hook *entity.Webhook, delivery *entity.WebhookDelivery) (int32, error) {
+	body := []byte(delivery.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "posts-webhooks")
+	req.Header.Set(webhook.EventHeader, delivery.Event)
+	req.Header.Set(webhook.DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(webhook.SignatureHeader, webhook.Sign(hook.Secret, time.Now(), body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain a little so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, streamChunkSize))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return int32(resp.StatusCode), fmt.Errorf("endpoint answered %s", resp.Status)
+	}
+	return int32(resp.StatusCode), nil
+}
+
+// subscribedEvents normalizes and checks the events of a webhook,
+// dropping duplicates.
+func subscribedEvents(events []string) ([]string, error) {
+	var result []string
+	for _, event := range events {
+		event = strings.ToLower(strings.TrimSpace(event))
+		if !slices.Contains(webhookEvents, event) {
+			return nil, status.Errorf(codes.InvalidArgument, "unknown webhook event %q", event)
+		}
+		if !slices.Contains(result, event) {
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func newSecret() (string, error) {
+	var random [32]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return "", fmt.Errorf("generating webhook secret: %w", err)
+	}
+	return hex.EncodeToString(random[:]), nil
+}
+
+// checkURL rejects webhook URLs that are malformed, do not resolve or
+// point to internal addresses.
+func checkURL(ctx context.Context, raw string) error {
+	if err := webhook.CheckURL(ctx, raw); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	return nil
+}
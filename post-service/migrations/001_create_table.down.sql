@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS logs;
+
+DROP TABLE IF EXISTS posts;
+
+DROP TABLE IF EXISTS users;
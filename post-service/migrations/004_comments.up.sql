@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS comments (
+     id bigserial PRIMARY KEY,
+     post_id bigint NOT NULL REFERENCES posts(id),
+     parent_id bigint REFERENCES comments(id),
+     user_id bigint REFERENCES users(id),
+     content text NOT NULL,
+     created_at timestamp default now(),
+     created_by bigint references users(id),
+     updated_at timestamp,
+     updated_by bigint references users(id),
+     deleted_at timestamp,
+     deleted_by bigint references users(id)
+);
+
+CREATE INDEX IF NOT EXISTS comments_post_id_parent_id_idx ON comments (post_id, parent_id, created_at)
+    WHERE deleted_at IS NULL;
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comments_count bigint NOT NULL DEFAULT 0;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS posts_reactions_count_idx;
+ALTER TABLE posts DROP COLUMN IF EXISTS reaction_counts;
+ALTER TABLE posts DROP COLUMN IF EXISTS reactions_count;
+
+DROP TABLE IF EXISTS reactions;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS reactions (
+     post_id bigint NOT NULL REFERENCES posts(id),
+     user_id bigint NOT NULL REFERENCES users(id),
+     kind text NOT NULL CHECK (kind IN ('like', 'love', 'laugh', 'wow', 'sad', 'angry')),
+     created_at timestamp default now(),
+     PRIMARY KEY (post_id, user_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS reactions_user_id_idx ON reactions (user_id);
+
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS reactions_count bigint NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS reaction_counts jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS posts_reactions_count_idx ON posts (reactions_count DESC, created_at DESC)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS tags (
+     id bigserial PRIMARY KEY,
+     slug text NOT NULL UNIQUE,
+     name text NOT NULL,
+     created_at timestamp default now()
+);
+
+-- tags without live posts are pruned, taking their links to deleted posts along
+CREATE TABLE IF NOT EXISTS post_tags (
+     post_id bigint NOT NULL REFERENCES posts(id),
+     tag_id bigint NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+     PRIMARY KEY (post_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS post_tags_tag_id_idx ON post_tags (tag_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS posts_scheduled_publish_at_idx;
+ALTER TABLE posts DROP COLUMN IF EXISTS published_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS publish_at;
+ALTER TABLE posts DROP COLUMN IF EXISTS status;
@@ -0,0 +1,10 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'published'
+    CHECK (status IN ('draft', 'scheduled', 'published', 'archived'));
+-- both hold UTC wall-clock time
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS publish_at timestamp;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS published_at timestamp;
+
+UPDATE posts SET published_at = created_at WHERE status = 'published' AND published_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS posts_scheduled_publish_at_idx ON posts (publish_at)
+    WHERE status = 'scheduled' AND deleted_at IS NULL;
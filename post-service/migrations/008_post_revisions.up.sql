@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS post_revisions (
+     id bigserial PRIMARY KEY,
+     post_id bigint NOT NULL REFERENCES posts(id),
+     revision bigint NOT NULL,
+     title text NOT NULL,
+     content text NOT NULL,
+     created_at timestamp default now(),
+     created_by bigint references users(id),
+     UNIQUE (post_id, revision)
+);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS attachments (
+     id bigserial PRIMARY KEY,
+     post_id bigint NOT NULL REFERENCES posts(id),
+     user_id bigint REFERENCES users(id),
+     storage_key text NOT NULL UNIQUE,
+     file_name text NOT NULL,
+     content_type text NOT NULL,
+     size bigint NOT NULL,
+     checksum text NOT NULL,
+     created_at timestamp default now(),
+     created_by bigint references users(id),
+     updated_at timestamp,
+     updated_by bigint references users(id),
+     deleted_at timestamp,
+     deleted_by bigint references users(id)
+);
+
+CREATE INDEX IF NOT EXISTS attachments_post_id_idx ON attachments (post_id) WHERE deleted_at IS NULL;
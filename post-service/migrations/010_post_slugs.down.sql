@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS posts_slug_key;
+DROP TABLE IF EXISTS post_slugs;
+ALTER TABLE posts DROP COLUMN IF EXISTS slug;
@@ -0,0 +1,21 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS slug text;
+
+-- every slug a post has had; old ones keep resolving to the post
+CREATE TABLE IF NOT EXISTS post_slugs (
+     slug text PRIMARY KEY,
+     post_id bigint NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+     created_at timestamp default now()
+);
+
+CREATE INDEX IF NOT EXISTS post_slugs_post_id_idx ON post_slugs (post_id);
+
+-- existing posts get a plain slug with their id appended, which is unique
+UPDATE posts
+SET slug = concat_ws('-', NULLIF(trim(BOTH '-' FROM lower(regexp_replace(title, '[^[:alnum:]]+', '-', 'g'))), ''), id::text)
+WHERE slug IS NULL;
+
+INSERT INTO post_slugs (slug, post_id)
+SELECT slug, id FROM posts
+ON CONFLICT (slug) DO NOTHING;
+
+CREATE UNIQUE INDEX IF NOT EXISTS posts_slug_key ON posts (slug);
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS posts_feed_idx;
+DROP TABLE IF EXISTS follows;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS follows (
+     follower_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+     followee_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+     created_at timestamp default now(),
+     PRIMARY KEY (follower_id, followee_id),
+     CHECK (follower_id <> followee_id)
+);
+
+CREATE INDEX IF NOT EXISTS follows_followee_id_idx ON follows (followee_id, created_at DESC);
+
+-- the feed reads each followed author's newest published posts
+CREATE INDEX IF NOT EXISTS posts_feed_idx ON posts (user_id, published_at DESC, id DESC)
+    WHERE status = 'published' AND deleted_at IS NULL;
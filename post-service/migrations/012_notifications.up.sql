@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS notifications (
+     id bigserial PRIMARY KEY,
+     user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+     kind text NOT NULL,
+     actor_id bigint REFERENCES users(id) ON DELETE SET NULL,
+     post_id bigint REFERENCES posts(id) ON DELETE CASCADE,
+     comment_id bigint REFERENCES comments(id) ON DELETE CASCADE,
+     title text NOT NULL,
+     body text NOT NULL DEFAULT '',
+     read_at timestamp,
+     created_at timestamp default now()
+);
+
+CREATE INDEX IF NOT EXISTS notifications_user_id_idx ON notifications (user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS notifications_unread_idx ON notifications (user_id) WHERE read_at IS NULL;
+
+-- only deviations from the defaults are stored
+CREATE TABLE IF NOT EXISTS notification_preferences (
+     user_id bigint NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+     kind text NOT NULL,
+     channel text NOT NULL CHECK (channel IN ('in_app', 'email', 'webhook')),
+     enabled boolean NOT NULL,
+     PRIMARY KEY (user_id, kind, channel)
+);
+
+CREATE TABLE IF NOT EXISTS notification_webhooks (
+     user_id bigint PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+     url text NOT NULL,
+     updated_at timestamp default now()
+);
@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+     id bigserial PRIMARY KEY,
+     url text NOT NULL,
+     events text[] NOT NULL,
+     secret text NOT NULL,
+     active boolean NOT NULL DEFAULT true,
+     -- failed attempts since the last successful delivery
+     failure_count integer NOT NULL DEFAULT 0,
+     disabled_at timestamp,
+     created_by bigint REFERENCES users(id) ON DELETE SET NULL,
+     created_at timestamp default now(),
+     updated_at timestamp default now()
+);
+
+CREATE INDEX IF NOT EXISTS webhooks_events_idx ON webhooks USING gin (events) WHERE active;
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+     id bigserial PRIMARY KEY,
+     webhook_id bigint NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+     event text NOT NULL,
+     payload jsonb NOT NULL,
+     status text NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'delivered', 'failed')),
+     attempts integer NOT NULL DEFAULT 0,
+     next_attempt_at timestamp NOT NULL DEFAULT now(),
+     response_code integer,
+     error text,
+     redelivery_of bigint REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+     created_at timestamp default now(),
+     delivered_at timestamp
+);
+
+CREATE INDEX IF NOT EXISTS webhook_deliveries_due_idx ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id, created_at DESC);
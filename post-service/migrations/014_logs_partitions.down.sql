@@ -0,0 +1,22 @@
+ALTER TABLE logs RENAME TO logs_partitioned;
+ALTER INDEX logs_pkey RENAME TO logs_partitioned_pkey;
+ALTER SEQUENCE logs_id_seq OWNED BY NONE;
+
+CREATE TABLE logs (
+     id bigint PRIMARY KEY DEFAULT nextval('logs_id_seq'),
+     level VARCHAR(10) CHECK (level IN ('INFO', 'WARNING', 'ERROR')),
+     message TEXT NOT NULL,
+     service_name text,
+     created_at timestamp default now(),
+     created_by bigint references users(id),
+     updated_at timestamp,
+     updated_by bigint references users(id),
+     deleted_at timestamp,
+     deleted_by bigint references users(id)
+);
+
+ALTER SEQUENCE logs_id_seq OWNED BY logs.id;
+
+INSERT INTO logs SELECT * FROM logs_partitioned;
+
+DROP TABLE logs_partitioned;
@@ -0,0 +1,51 @@
+-- logs is partitioned by month of created_at so that expired months can be
+-- archived and dropped as a whole. Partitions are named logs_yYYYYmMM; the
+-- service creates upcoming ones ahead of time.
+ALTER TABLE logs RENAME TO logs_unpartitioned;
+ALTER INDEX logs_pkey RENAME TO logs_unpartitioned_pkey;
+-- the id sequence outlives the old table
+ALTER SEQUENCE logs_id_seq OWNED BY NONE;
+
+CREATE TABLE logs (
+     id bigint NOT NULL DEFAULT nextval('logs_id_seq'),
+     level VARCHAR(10) CHECK (level IN ('INFO', 'WARNING', 'ERROR')),
+     message TEXT NOT NULL,
+     service_name text,
+     created_at timestamp NOT NULL DEFAULT now(),
+     created_by bigint references users(id),
+     updated_at timestamp,
+     updated_by bigint references users(id),
+     deleted_at timestamp,
+     deleted_by bigint references users(id),
+     PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+ALTER SEQUENCE logs_id_seq OWNED BY logs.id;
+
+-- rows outside every monthly partition; kept out of retention
+CREATE TABLE logs_default PARTITION OF logs DEFAULT;
+
+-- keyset pagination of GetList
+CREATE INDEX logs_created_at_idx ON logs (created_at DESC, id DESC);
+
+DO $$
+DECLARE
+     first_month timestamp;
+BEGIN
+     FOR first_month IN
+          SELECT generate_series(
+               date_trunc('month', LEAST((SELECT min(created_at) FROM logs_unpartitioned), localtimestamp)),
+               date_trunc('month', localtimestamp) + interval '1 month',
+               interval '1 month')
+     LOOP
+          EXECUTE format('CREATE TABLE %I PARTITION OF logs FOR VALUES FROM (%L) TO (%L)',
+               'logs_y' || to_char(first_month, 'YYYY') || 'm' || to_char(first_month, 'MM'),
+               first_month, first_month + interval '1 month');
+     END LOOP;
+END $$;
+
+INSERT INTO logs (id, level, message, service_name, created_at, created_by, updated_at, updated_by, deleted_at, deleted_by)
+SELECT id, level, message, service_name, COALESCE(created_at, localtimestamp), created_by, updated_at, updated_by, deleted_at, deleted_by
+FROM logs_unpartitioned;
+
+DROP TABLE logs_unpartitioned;
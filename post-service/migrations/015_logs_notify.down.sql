@@ -0,0 +1,2 @@
+DROP TRIGGER IF EXISTS logs_inserted ON logs;
+DROP FUNCTION IF EXISTS notify_logs_inserted();
@@ -0,0 +1,12 @@
+-- wakes log tails once per inserting statement; listeners read the new
+-- rows themselves
+CREATE OR REPLACE FUNCTION notify_logs_inserted() RETURNS trigger AS $$
+BEGIN
+     PERFORM pg_notify('logs_inserted', '');
+     RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER logs_inserted
+AFTER INSERT ON logs
+FOR EACH STATEMENT EXECUTE FUNCTION notify_logs_inserted();
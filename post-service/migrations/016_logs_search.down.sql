@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS logs_service_created_at_idx;
+DROP INDEX IF EXISTS logs_search_vector_idx;
+ALTER TABLE logs DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,7 @@
+-- messages are mostly identifiers and English, so they are indexed without
+-- stemming
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', message)) STORED;
+
+CREATE INDEX IF NOT EXISTS logs_search_vector_idx ON logs USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS logs_service_created_at_idx ON logs (service_name, created_at);
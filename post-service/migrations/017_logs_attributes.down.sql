@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS logs_attributes_idx;
+ALTER TABLE logs DROP COLUMN IF EXISTS attributes;
+
+ALTER TABLE logs DROP CONSTRAINT IF EXISTS logs_level_check;
+UPDATE logs SET level = 'INFO' WHERE level IN ('TRACE', 'DEBUG');
+UPDATE logs SET level = 'ERROR' WHERE level = 'FATAL';
+ALTER TABLE logs ADD CONSTRAINT logs_level_check
+    CHECK (level IN ('INFO', 'WARNING', 'ERROR'));
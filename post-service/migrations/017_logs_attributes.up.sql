@@ -0,0 +1,9 @@
+-- the levels of the services' loggers
+ALTER TABLE logs DROP CONSTRAINT IF EXISTS logs_level_check;
+ALTER TABLE logs ADD CONSTRAINT logs_level_check
+    CHECK (level IN ('TRACE', 'DEBUG', 'INFO', 'WARNING', 'ERROR', 'FATAL'));
+
+-- structured fields such as trace_id, user_id or path
+ALTER TABLE logs ADD COLUMN IF NOT EXISTS attributes jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX IF NOT EXISTS logs_attributes_idx ON logs USING GIN (attributes);
@@ -0,0 +1 @@
+ALTER TABLE notification_webhooks DROP COLUMN IF EXISTS secret;
@@ -0,0 +1,4 @@
+-- signs webhook notifications; existing webhooks get a random secret
+ALTER TABLE notification_webhooks ADD COLUMN IF NOT EXISTS secret text NOT NULL
+    DEFAULT encode(sha256(gen_random_uuid()::text::bytea), 'hex');
+ALTER TABLE notification_webhooks ALTER COLUMN secret DROP DEFAULT;